@@ -58,11 +58,22 @@ func (a *App) GetEffectiveConfig(repositoryID string) (gitcfg.ConfigMatrix, erro
 	return a.service.GetEffectiveConfig(a.ctx, repositoryID)
 }
 
+// RevealConfigValue returns the unredacted value of a key. Pass "global" as repositoryID for
+// the global configuration.
+func (a *App) RevealConfigValue(repositoryID, key string) (gitcfg.ConfigValue, error) {
+	return a.service.RevealValue(a.ctx, repositoryID, key)
+}
+
 // GetGlobalConfig resolves the global git configuration.
 func (a *App) GetGlobalConfig() (gitcfg.ConfigMatrix, error) {
 	return a.service.GetGlobalConfig(a.ctx)
 }
 
+// SearchConfig finds configuration entries across all scanned repositories.
+func (a *App) SearchConfig(query gitcfg.SearchQuery) (gitcfg.SearchResponse, error) {
+	return a.service.Search(a.ctx, query)
+}
+
 // PickRoot opens a directory picker and registers the selected repository.
 func (a *App) PickRoot() (gitcfg.Repository, error) {
 	path, err := runtime.OpenDirectoryDialog(a.ctx, runtime.OpenDialogOptions{
@@ -102,6 +113,275 @@ func (a *App) Rollback(changeSetID string) (gitcfg.ChangeSet, error) {
 	return a.service.Rollback(a.ctx, changeSetID)
 }
 
+// ListURLRewrites returns the insteadOf and pushInsteadOf rules visible to a repository.
+func (a *App) ListURLRewrites(repositoryID string) ([]gitcfg.URLRewrite, error) {
+	return a.service.ListURLRewrites(a.ctx, repositoryID)
+}
+
+// AddURLRewrite adds an insteadOf or pushInsteadOf rule.
+func (a *App) AddURLRewrite(req gitcfg.URLRewriteRequest) (gitcfg.ChangeSet, error) {
+	return a.service.AddURLRewrite(a.ctx, req)
+}
+
+// RemoveURLRewrite removes an insteadOf or pushInsteadOf rule.
+func (a *App) RemoveURLRewrite(req gitcfg.URLRewriteRequest) (gitcfg.ChangeSet, error) {
+	return a.service.RemoveURLRewrite(a.ctx, req)
+}
+
+// SimulateURLRewrite shows the fetch and push URLs git uses for a URL or remote name.
+func (a *App) SimulateURLRewrite(repositoryID, input string) (gitcfg.RewriteSimulation, error) {
+	return a.service.SimulateURLRewrite(a.ctx, repositoryID, input)
+}
+
+// GetRemoteHTTPSettings returns the effective http.* settings for each remote of a repository.
+func (a *App) GetRemoteHTTPSettings(repositoryID string) ([]gitcfg.RemoteHTTPSettings, error) {
+	return a.service.GetRemoteHTTPSettings(a.ctx, repositoryID)
+}
+
+// ListAliases returns the aliases visible to a repository with detected problems.
+func (a *App) ListAliases(repositoryID string) ([]gitcfg.Alias, error) {
+	return a.service.ListAliases(a.ctx, repositoryID)
+}
+
+// ExpandAlias shows the command an alias invocation runs without executing it.
+func (a *App) ExpandAlias(repositoryID, name string, args []string) (gitcfg.AliasExpansion, error) {
+	return a.service.ExpandAlias(a.ctx, repositoryID, name, args)
+}
+
+// ListSafeDirectories returns the global safe.directory entries, flagging stale ones.
+func (a *App) ListSafeDirectories() ([]gitcfg.SafeDirectory, error) {
+	return a.service.ListSafeDirectories(a.ctx)
+}
+
+// TrustRepository adds a safe.directory entry for a repository blocked by the ownership check.
+func (a *App) TrustRepository(repositoryID string, dryRun bool) (gitcfg.ChangeSet, error) {
+	return a.service.TrustRepository(a.ctx, repositoryID, dryRun)
+}
+
+// PruneSafeDirectories removes safe.directory entries pointing at missing directories.
+func (a *App) PruneSafeDirectories(dryRun bool) (gitcfg.ChangeSet, error) {
+	return a.service.PruneSafeDirectories(a.ctx, dryRun)
+}
+
+// ListCredentialConfig returns the credential.* entries visible to a repository.
+func (a *App) ListCredentialConfig(repositoryID string) ([]gitcfg.CredentialEntry, error) {
+	return a.service.ListCredentialConfig(a.ctx, repositoryID)
+}
+
+// ResolveCredentialHelpers returns the helper chain git uses for a URL or remote.
+func (a *App) ResolveCredentialHelpers(repositoryID, input string) (gitcfg.CredentialResolution, error) {
+	return a.service.ResolveCredentialHelpers(a.ctx, repositoryID, input)
+}
+
+// ListStoredCredentials returns the entries of the store helper files, without passwords.
+func (a *App) ListStoredCredentials(repositoryID string) ([]gitcfg.StoredCredential, error) {
+	return a.service.ListStoredCredentials(a.ctx, repositoryID)
+}
+
+// DeleteStoredCredential removes a stored credential and records the change.
+func (a *App) DeleteStoredCredential(repositoryID, id string, dryRun bool) (gitcfg.ChangeSet, error) {
+	return a.service.DeleteStoredCredential(a.ctx, repositoryID, id, dryRun)
+}
+
+// ListRemotes returns the remotes of a repository.
+func (a *App) ListRemotes(repositoryID string) ([]gitcfg.Remote, error) {
+	return a.service.ListRemotes(a.ctx, repositoryID)
+}
+
+// AddRemote adds a remote to a repository.
+func (a *App) AddRemote(req gitcfg.RemoteRequest) (gitcfg.ChangeSet, error) {
+	return a.service.AddRemote(a.ctx, req)
+}
+
+// SetRemoteURL re-points the fetch or push URL of a remote.
+func (a *App) SetRemoteURL(req gitcfg.RemoteRequest) (gitcfg.ChangeSet, error) {
+	return a.service.SetRemoteURL(a.ctx, req)
+}
+
+// RenameRemote renames a remote and the settings referring to it.
+func (a *App) RenameRemote(req gitcfg.RemoteRequest) (gitcfg.ChangeSet, error) {
+	return a.service.RenameRemote(a.ctx, req)
+}
+
+// RemoveRemote removes a remote and the settings referring to it.
+func (a *App) RemoveRemote(req gitcfg.RemoteRequest) (gitcfg.ChangeSet, error) {
+	return a.service.RemoveRemote(a.ctx, req)
+}
+
+// MigrateRemoteHost rewrites remote URLs from one host or organization to another.
+func (a *App) MigrateRemoteHost(req gitcfg.HostMigrationRequest) (gitcfg.HostMigrationResult, error) {
+	return a.service.MigrateRemoteHost(a.ctx, req)
+}
+
+// ListBranchConfigs returns the branch sections of a repository.
+func (a *App) ListBranchConfigs(repositoryID string) ([]gitcfg.BranchConfig, error) {
+	return a.service.ListBranchConfigs(a.ctx, repositoryID)
+}
+
+// SetBranchUpstream sets or removes the upstream of a branch.
+func (a *App) SetBranchUpstream(req gitcfg.BranchRequest) (gitcfg.ChangeSet, error) {
+	return a.service.SetBranchUpstream(a.ctx, req)
+}
+
+// SetBranchRebase sets or removes branch.<name>.rebase.
+func (a *App) SetBranchRebase(req gitcfg.BranchRequest) (gitcfg.ChangeSet, error) {
+	return a.service.SetBranchRebase(a.ctx, req)
+}
+
+// ListStaleSections lists config sections of deleted branches and unused remotes.
+func (a *App) ListStaleSections(repositoryID string) (gitcfg.StaleSections, error) {
+	return a.service.ListStaleSections(a.ctx, repositoryID)
+}
+
+// CleanStaleSections removes config sections of deleted branches and of the confirmed remotes.
+func (a *App) CleanStaleSections(repositoryID string, remotes []string, dryRun bool) (gitcfg.ChangeSet, error) {
+	return a.service.CleanStaleSections(a.ctx, repositoryID, remotes, dryRun)
+}
+
+// GetIncludeGraph returns the config files of a repository and the includes between them.
+func (a *App) GetIncludeGraph(repositoryID string) (gitcfg.IncludeGraph, error) {
+	return a.service.GetIncludeGraph(a.ctx, repositoryID)
+}
+
+// AnalyzeIncludes reports include cycles, missing targets and other problems across all
+// config files.
+func (a *App) AnalyzeIncludes() (gitcfg.IncludeAnalysis, error) {
+	return a.service.AnalyzeIncludes(a.ctx)
+}
+
+// SimulateEnvironment shows how a repository's configuration changes under other environment
+// variables and -c options.
+func (a *App) SimulateEnvironment(req gitcfg.SimulationRequest) (gitcfg.SimulationResult, error) {
+	return a.service.SimulateEnvironment(a.ctx, req)
+}
+
+// EvaluatePath returns the configuration a repository at any path would receive from the
+// system and global config files.
+func (a *App) EvaluatePath(req gitcfg.WhatIfRequest) (gitcfg.WhatIfResult, error) {
+	return a.service.EvaluatePath(a.ctx, req)
+}
+
+// PreviewWriteImpact lists the repositories a global or system write would affect.
+func (a *App) PreviewWriteImpact(req gitcfg.WriteRequest) (gitcfg.ImpactReport, error) {
+	return a.service.PreviewWriteImpact(a.ctx, req)
+}
+
+// PreviewRuleImpact lists the repositories an include rule would affect.
+func (a *App) PreviewRuleImpact(rule gitcfg.IncludeRule) (gitcfg.ImpactReport, error) {
+	return a.service.PreviewRuleImpact(a.ctx, rule)
+}
+
+// StageWrite queues a write for the next batch.
+func (a *App) StageWrite(req gitcfg.WriteRequest) (gitcfg.StagedChange, error) {
+	return a.service.StageWrite(a.ctx, req)
+}
+
+// StageRule queues storing or, with remove, deleting an include rule.
+func (a *App) StageRule(rule gitcfg.IncludeRule, remove bool) (gitcfg.StagedChange, error) {
+	return a.service.StageRule(a.ctx, rule, remove)
+}
+
+// ListStaged returns the pending changes in application order.
+func (a *App) ListStaged() []gitcfg.StagedChange {
+	return a.service.ListStaged()
+}
+
+// ReorderStaged rearranges the pending changes.
+func (a *App) ReorderStaged(ids []string) ([]gitcfg.StagedChange, error) {
+	return a.service.ReorderStaged(ids)
+}
+
+// DropStaged removes a pending change.
+func (a *App) DropStaged(id string) error {
+	return a.service.DropStaged(id)
+}
+
+// ClearStaged discards every pending change.
+func (a *App) ClearStaged() {
+	a.service.ClearStaged()
+}
+
+// PreviewStaged returns the combined diff of the pending changes.
+func (a *App) PreviewStaged() (gitcfg.StagedPreview, error) {
+	return a.service.PreviewStaged(a.ctx)
+}
+
+// ApplyStaged applies every pending change as one transaction. baseHashes maps each file of
+// the preview to its BaseHash; files changed since the preview are refused.
+func (a *App) ApplyStaged(baseHashes map[string]string) (gitcfg.BatchResult, error) {
+	return a.service.ApplyStaged(a.ctx, baseHashes)
+}
+
+// ExportArchive writes every relevant config file and the app state into an archive.
+func (a *App) ExportArchive(req gitcfg.ExportRequest) (gitcfg.ArchiveManifest, error) {
+	return a.service.ExportArchive(a.ctx, req)
+}
+
+// PreviewImport reports what importing an archive would write where.
+func (a *App) PreviewImport(req gitcfg.ImportRequest) (gitcfg.ImportPlan, error) {
+	return a.service.PreviewImport(a.ctx, req)
+}
+
+// ImportArchive restores an archive.
+func (a *App) ImportArchive(req gitcfg.ImportRequest) (gitcfg.ImportResult, error) {
+	return a.service.ImportArchive(a.ctx, req)
+}
+
+// ListBackups returns the stored backups grouped by original file.
+func (a *App) ListBackups(filePath string) ([]gitcfg.BackupFile, error) {
+	return a.service.ListBackups(a.ctx, filePath)
+}
+
+// GetBackupRetention returns the backup retention policy.
+func (a *App) GetBackupRetention() (gitcfg.BackupRetention, error) {
+	return a.service.GetBackupRetention()
+}
+
+// SetBackupRetention saves the backup retention policy and prunes accordingly.
+func (a *App) SetBackupRetention(policy gitcfg.BackupRetention) (int, error) {
+	return a.service.SetBackupRetention(a.ctx, policy)
+}
+
+// PreviewRestore returns the diff restoring a backup would apply.
+func (a *App) PreviewRestore(backupID string) (gitcfg.ChangeSet, error) {
+	return a.service.PreviewRestore(a.ctx, backupID)
+}
+
+// RestoreBackup writes a backup over its original file.
+func (a *App) RestoreBackup(backupID, expectedHash string) (gitcfg.ChangeSet, error) {
+	return a.service.RestoreBackup(a.ctx, backupID, expectedHash)
+}
+
+// Undo reverts the most recent edit of the session.
+func (a *App) Undo() (gitcfg.EditResult, error) {
+	return a.service.Undo(a.ctx)
+}
+
+// Redo reapplies the most recently undone edit.
+func (a *App) Redo() (gitcfg.EditResult, error) {
+	return a.service.Redo(a.ctx)
+}
+
+// GetEditHistory returns the undo and redo stacks.
+func (a *App) GetEditHistory() gitcfg.EditHistory {
+	return a.service.GetEditHistory()
+}
+
+// QueryAuditLog returns the audit log entries matching q.
+func (a *App) QueryAuditLog(q gitcfg.AuditQuery) ([]gitcfg.AuditEntry, error) {
+	return a.service.QueryAuditLog(a.ctx, q)
+}
+
+// VerifyAuditLog checks the hash chain of the audit log.
+func (a *App) VerifyAuditLog() (gitcfg.AuditVerification, error) {
+	return a.service.VerifyAuditLog(a.ctx)
+}
+
+// GetKeyHistory returns the recorded changes to a key for a repository.
+func (a *App) GetKeyHistory(repositoryID, key string) (gitcfg.KeyHistory, error) {
+	return a.service.GetKeyHistory(a.ctx, repositoryID, key)
+}
+
 // ListIncludeRules returns the includeIf rules.
 func (a *App) ListIncludeRules() ([]gitcfg.IncludeRule, error) {
 	return a.service.ListRules(a.ctx)
@@ -122,6 +402,31 @@ func (a *App) ToggleIncludeRule(id string, enabled bool) (gitcfg.IncludeRule, er
 	return a.service.ToggleRule(a.ctx, id, enabled)
 }
 
+// DescribeConfigKey returns the documentation for a configuration key.
+func (a *App) DescribeConfigKey(key string) (gitcfg.KeyInfo, error) {
+	return a.service.DescribeKey(key)
+}
+
+// CompleteConfigKeys returns autocomplete suggestions for a partially typed key.
+func (a *App) CompleteConfigKeys(prefix string, limit int) []gitcfg.KeyInfo {
+	return a.service.CompleteKeys(prefix, limit)
+}
+
+// AuditCommandKeys lists configuration values that make git run programs for a repository.
+func (a *App) AuditCommandKeys(repositoryID string) ([]gitcfg.CommandKeyFinding, error) {
+	return a.service.AuditCommandKeys(a.ctx, repositoryID)
+}
+
+// TrustCommandKey adds a command-executing value to the trust list of a repository.
+func (a *App) TrustCommandKey(repositoryID, key, fingerprint string) error {
+	return a.service.TrustCommandKey(a.ctx, repositoryID, key, fingerprint)
+}
+
+// UntrustCommandKey removes a value from the trust list of a repository.
+func (a *App) UntrustCommandKey(repositoryID, key, fingerprint string) error {
+	return a.service.UntrustCommandKey(repositoryID, key, fingerprint)
+}
+
 // RunDiagnostics triggers the diagnostics subsystem for a repository.
 func (a *App) RunDiagnostics(repositoryID string) (gitcfg.DiagnosticsReport, error) {
 	return a.service.RunDiagnostics(a.ctx, repositoryID)
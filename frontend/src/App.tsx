@@ -125,7 +125,7 @@ function ConfigSections({ sections }: { sections: ConfigSectionGroup[] }) {
                     className="transition hover:bg-primary/5 hover:text-foreground"
                   >
                     <td className="px-4 py-3 align-top font-medium">
-                      {entry.key}
+                      {entry.displayKey || entry.key}
                     </td>
                     <td className="px-4 py-3 align-top text-xs text-muted-foreground">
                       <pre className="whitespace-pre-wrap break-words">
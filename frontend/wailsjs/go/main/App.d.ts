@@ -2,34 +2,154 @@
 // This file is automatically generated. DO NOT EDIT
 import {gitcfg} from '../models';
 
+export function AddRemote(arg1:gitcfg.RemoteRequest):Promise<gitcfg.ChangeSet>;
+
 export function AddRoot(arg1:string):Promise<void>;
 
+export function AddURLRewrite(arg1:gitcfg.URLRewriteRequest):Promise<gitcfg.ChangeSet>;
+
+export function AnalyzeIncludes():Promise<gitcfg.IncludeAnalysis>;
+
+export function ApplyStaged(arg1:Record<string, string>):Promise<gitcfg.BatchResult>;
+
+export function AuditCommandKeys(arg1:string):Promise<Array<gitcfg.CommandKeyFinding>>;
+
+export function CleanStaleSections(arg1:string,arg2:Array<string>,arg3:boolean):Promise<gitcfg.ChangeSet>;
+
+export function ClearStaged():Promise<void>;
+
+export function CompleteConfigKeys(arg1:string,arg2:number):Promise<Array<gitcfg.KeyInfo>>;
+
 export function DeleteIncludeRule(arg1:string):Promise<void>;
 
+export function DeleteStoredCredential(arg1:string,arg2:string,arg3:boolean):Promise<gitcfg.ChangeSet>;
+
+export function DescribeConfigKey(arg1:string):Promise<gitcfg.KeyInfo>;
+
+export function DropStaged(arg1:string):Promise<void>;
+
+export function EvaluatePath(arg1:gitcfg.WhatIfRequest):Promise<gitcfg.WhatIfResult>;
+
+export function ExpandAlias(arg1:string,arg2:string,arg3:Array<string>):Promise<gitcfg.AliasExpansion>;
+
+export function ExportArchive(arg1:gitcfg.ExportRequest):Promise<gitcfg.ArchiveManifest>;
+
+export function GetBackupRetention():Promise<gitcfg.BackupRetention>;
+
+export function GetEditHistory():Promise<gitcfg.EditHistory>;
+
 export function GetEffectiveConfig(arg1:string):Promise<gitcfg.ConfigMatrix>;
 
 export function GetGlobalConfig():Promise<gitcfg.ConfigMatrix>;
 
+export function GetIncludeGraph(arg1:string):Promise<gitcfg.IncludeGraph>;
+
+export function GetKeyHistory(arg1:string,arg2:string):Promise<gitcfg.KeyHistory>;
+
+export function GetRemoteHTTPSettings(arg1:string):Promise<Array<gitcfg.RemoteHTTPSettings>>;
+
 export function Greet(arg1:string):Promise<string>;
 
+export function ImportArchive(arg1:gitcfg.ImportRequest):Promise<gitcfg.ImportResult>;
+
+export function ListAliases(arg1:string):Promise<Array<gitcfg.Alias>>;
+
+export function ListBackups(arg1:string):Promise<Array<gitcfg.BackupFile>>;
+
+export function ListBranchConfigs(arg1:string):Promise<Array<gitcfg.BranchConfig>>;
+
 export function ListChangeSets(arg1:string):Promise<Array<gitcfg.ChangeSet>>;
 
+export function ListCredentialConfig(arg1:string):Promise<Array<gitcfg.CredentialEntry>>;
+
 export function ListIncludeRules():Promise<Array<gitcfg.IncludeRule>>;
 
+export function ListRemotes(arg1:string):Promise<Array<gitcfg.Remote>>;
+
 export function ListRoots():Promise<Array<string>>;
 
+export function ListSafeDirectories():Promise<Array<gitcfg.SafeDirectory>>;
+
+export function ListStaged():Promise<Array<gitcfg.StagedChange>>;
+
+export function ListStaleSections(arg1:string):Promise<gitcfg.StaleSections>;
+
+export function ListStoredCredentials(arg1:string):Promise<Array<gitcfg.StoredCredential>>;
+
+export function ListURLRewrites(arg1:string):Promise<Array<gitcfg.URLRewrite>>;
+
+export function MigrateRemoteHost(arg1:gitcfg.HostMigrationRequest):Promise<gitcfg.HostMigrationResult>;
+
 export function PickRoot():Promise<gitcfg.Repository>;
 
+export function PreviewImport(arg1:gitcfg.ImportRequest):Promise<gitcfg.ImportPlan>;
+
+export function PreviewRestore(arg1:string):Promise<gitcfg.ChangeSet>;
+
+export function PreviewRuleImpact(arg1:gitcfg.IncludeRule):Promise<gitcfg.ImpactReport>;
+
+export function PreviewStaged():Promise<gitcfg.StagedPreview>;
+
+export function PreviewWriteImpact(arg1:gitcfg.WriteRequest):Promise<gitcfg.ImpactReport>;
+
+export function PruneSafeDirectories(arg1:boolean):Promise<gitcfg.ChangeSet>;
+
+export function QueryAuditLog(arg1:gitcfg.AuditQuery):Promise<Array<gitcfg.AuditEntry>>;
+
+export function Redo():Promise<gitcfg.EditResult>;
+
+export function RemoveRemote(arg1:gitcfg.RemoteRequest):Promise<gitcfg.ChangeSet>;
+
 export function RemoveRoot(arg1:string):Promise<void>;
 
+export function RemoveURLRewrite(arg1:gitcfg.URLRewriteRequest):Promise<gitcfg.ChangeSet>;
+
+export function RenameRemote(arg1:gitcfg.RemoteRequest):Promise<gitcfg.ChangeSet>;
+
+export function ReorderStaged(arg1:Array<string>):Promise<Array<gitcfg.StagedChange>>;
+
+export function ResolveCredentialHelpers(arg1:string,arg2:string):Promise<gitcfg.CredentialResolution>;
+
+export function RestoreBackup(arg1:string,arg2:string):Promise<gitcfg.ChangeSet>;
+
+export function RevealConfigValue(arg1:string,arg2:string):Promise<gitcfg.ConfigValue>;
+
 export function Rollback(arg1:string):Promise<gitcfg.ChangeSet>;
 
 export function RunDiagnostics(arg1:string):Promise<gitcfg.DiagnosticsReport>;
 
 export function ScanRepositories(arg1:gitcfg.ScanOptions):Promise<Array<gitcfg.Repository>>;
 
+export function SearchConfig(arg1:gitcfg.SearchQuery):Promise<gitcfg.SearchResponse>;
+
+export function SetBackupRetention(arg1:gitcfg.BackupRetention):Promise<number>;
+
+export function SetBranchRebase(arg1:gitcfg.BranchRequest):Promise<gitcfg.ChangeSet>;
+
+export function SetBranchUpstream(arg1:gitcfg.BranchRequest):Promise<gitcfg.ChangeSet>;
+
+export function SetRemoteURL(arg1:gitcfg.RemoteRequest):Promise<gitcfg.ChangeSet>;
+
+export function SimulateEnvironment(arg1:gitcfg.SimulationRequest):Promise<gitcfg.SimulationResult>;
+
+export function SimulateURLRewrite(arg1:string,arg2:string):Promise<gitcfg.RewriteSimulation>;
+
+export function StageRule(arg1:gitcfg.IncludeRule,arg2:boolean):Promise<gitcfg.StagedChange>;
+
+export function StageWrite(arg1:gitcfg.WriteRequest):Promise<gitcfg.StagedChange>;
+
 export function ToggleIncludeRule(arg1:string,arg2:boolean):Promise<gitcfg.IncludeRule>;
 
+export function TrustCommandKey(arg1:string,arg2:string,arg3:string):Promise<void>;
+
+export function TrustRepository(arg1:string,arg2:boolean):Promise<gitcfg.ChangeSet>;
+
+export function Undo():Promise<gitcfg.EditResult>;
+
+export function UntrustCommandKey(arg1:string,arg2:string,arg3:string):Promise<void>;
+
 export function UpsertIncludeRule(arg1:gitcfg.IncludeRule):Promise<gitcfg.IncludeRule>;
 
+export function VerifyAuditLog():Promise<gitcfg.AuditVerification>;
+
 export function WriteConfig(arg1:gitcfg.WriteRequest):Promise<gitcfg.ChangeSet>;
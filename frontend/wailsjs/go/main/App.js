@@ -2,14 +2,78 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function AddRemote(arg1) {
+  return window['go']['main']['App']['AddRemote'](arg1);
+}
+
 export function AddRoot(arg1) {
   return window['go']['main']['App']['AddRoot'](arg1);
 }
 
+export function AddURLRewrite(arg1) {
+  return window['go']['main']['App']['AddURLRewrite'](arg1);
+}
+
+export function AnalyzeIncludes() {
+  return window['go']['main']['App']['AnalyzeIncludes']();
+}
+
+export function ApplyStaged(arg1) {
+  return window['go']['main']['App']['ApplyStaged'](arg1);
+}
+
+export function AuditCommandKeys(arg1) {
+  return window['go']['main']['App']['AuditCommandKeys'](arg1);
+}
+
+export function CleanStaleSections(arg1, arg2, arg3) {
+  return window['go']['main']['App']['CleanStaleSections'](arg1, arg2, arg3);
+}
+
+export function ClearStaged() {
+  return window['go']['main']['App']['ClearStaged']();
+}
+
+export function CompleteConfigKeys(arg1, arg2) {
+  return window['go']['main']['App']['CompleteConfigKeys'](arg1, arg2);
+}
+
 export function DeleteIncludeRule(arg1) {
   return window['go']['main']['App']['DeleteIncludeRule'](arg1);
 }
 
+export function DeleteStoredCredential(arg1, arg2, arg3) {
+  return window['go']['main']['App']['DeleteStoredCredential'](arg1, arg2, arg3);
+}
+
+export function DescribeConfigKey(arg1) {
+  return window['go']['main']['App']['DescribeConfigKey'](arg1);
+}
+
+export function DropStaged(arg1) {
+  return window['go']['main']['App']['DropStaged'](arg1);
+}
+
+export function EvaluatePath(arg1) {
+  return window['go']['main']['App']['EvaluatePath'](arg1);
+}
+
+export function ExpandAlias(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ExpandAlias'](arg1, arg2, arg3);
+}
+
+export function ExportArchive(arg1) {
+  return window['go']['main']['App']['ExportArchive'](arg1);
+}
+
+export function GetBackupRetention() {
+  return window['go']['main']['App']['GetBackupRetention']();
+}
+
+export function GetEditHistory() {
+  return window['go']['main']['App']['GetEditHistory']();
+}
+
 export function GetEffectiveConfig(arg1) {
   return window['go']['main']['App']['GetEffectiveConfig'](arg1);
 }
@@ -18,30 +82,150 @@ export function GetGlobalConfig() {
   return window['go']['main']['App']['GetGlobalConfig']();
 }
 
+export function GetIncludeGraph(arg1) {
+  return window['go']['main']['App']['GetIncludeGraph'](arg1);
+}
+
+export function GetKeyHistory(arg1, arg2) {
+  return window['go']['main']['App']['GetKeyHistory'](arg1, arg2);
+}
+
+export function GetRemoteHTTPSettings(arg1) {
+  return window['go']['main']['App']['GetRemoteHTTPSettings'](arg1);
+}
+
 export function Greet(arg1) {
   return window['go']['main']['App']['Greet'](arg1);
 }
 
+export function ImportArchive(arg1) {
+  return window['go']['main']['App']['ImportArchive'](arg1);
+}
+
+export function ListAliases(arg1) {
+  return window['go']['main']['App']['ListAliases'](arg1);
+}
+
+export function ListBackups(arg1) {
+  return window['go']['main']['App']['ListBackups'](arg1);
+}
+
+export function ListBranchConfigs(arg1) {
+  return window['go']['main']['App']['ListBranchConfigs'](arg1);
+}
+
 export function ListChangeSets(arg1) {
   return window['go']['main']['App']['ListChangeSets'](arg1);
 }
 
+export function ListCredentialConfig(arg1) {
+  return window['go']['main']['App']['ListCredentialConfig'](arg1);
+}
+
 export function ListIncludeRules() {
   return window['go']['main']['App']['ListIncludeRules']();
 }
 
+export function ListRemotes(arg1) {
+  return window['go']['main']['App']['ListRemotes'](arg1);
+}
+
 export function ListRoots() {
   return window['go']['main']['App']['ListRoots']();
 }
 
+export function ListSafeDirectories() {
+  return window['go']['main']['App']['ListSafeDirectories']();
+}
+
+export function ListStaged() {
+  return window['go']['main']['App']['ListStaged']();
+}
+
+export function ListStaleSections(arg1) {
+  return window['go']['main']['App']['ListStaleSections'](arg1);
+}
+
+export function ListStoredCredentials(arg1) {
+  return window['go']['main']['App']['ListStoredCredentials'](arg1);
+}
+
+export function ListURLRewrites(arg1) {
+  return window['go']['main']['App']['ListURLRewrites'](arg1);
+}
+
+export function MigrateRemoteHost(arg1) {
+  return window['go']['main']['App']['MigrateRemoteHost'](arg1);
+}
+
 export function PickRoot() {
   return window['go']['main']['App']['PickRoot']();
 }
 
+export function PreviewImport(arg1) {
+  return window['go']['main']['App']['PreviewImport'](arg1);
+}
+
+export function PreviewRestore(arg1) {
+  return window['go']['main']['App']['PreviewRestore'](arg1);
+}
+
+export function PreviewRuleImpact(arg1) {
+  return window['go']['main']['App']['PreviewRuleImpact'](arg1);
+}
+
+export function PreviewStaged() {
+  return window['go']['main']['App']['PreviewStaged']();
+}
+
+export function PreviewWriteImpact(arg1) {
+  return window['go']['main']['App']['PreviewWriteImpact'](arg1);
+}
+
+export function PruneSafeDirectories(arg1) {
+  return window['go']['main']['App']['PruneSafeDirectories'](arg1);
+}
+
+export function QueryAuditLog(arg1) {
+  return window['go']['main']['App']['QueryAuditLog'](arg1);
+}
+
+export function Redo() {
+  return window['go']['main']['App']['Redo']();
+}
+
+export function RemoveRemote(arg1) {
+  return window['go']['main']['App']['RemoveRemote'](arg1);
+}
+
 export function RemoveRoot(arg1) {
   return window['go']['main']['App']['RemoveRoot'](arg1);
 }
 
+export function RemoveURLRewrite(arg1) {
+  return window['go']['main']['App']['RemoveURLRewrite'](arg1);
+}
+
+export function RenameRemote(arg1) {
+  return window['go']['main']['App']['RenameRemote'](arg1);
+}
+
+export function ReorderStaged(arg1) {
+  return window['go']['main']['App']['ReorderStaged'](arg1);
+}
+
+export function ResolveCredentialHelpers(arg1, arg2) {
+  return window['go']['main']['App']['ResolveCredentialHelpers'](arg1, arg2);
+}
+
+export function RestoreBackup(arg1, arg2) {
+  return window['go']['main']['App']['RestoreBackup'](arg1, arg2);
+}
+
+export function RevealConfigValue(arg1, arg2) {
+  return window['go']['main']['App']['RevealConfigValue'](arg1, arg2);
+}
+
 export function Rollback(arg1) {
   return window['go']['main']['App']['Rollback'](arg1);
 }
@@ -54,14 +238,70 @@ export function ScanRepositories(arg1) {
   return window['go']['main']['App']['ScanRepositories'](arg1);
 }
 
+export function SearchConfig(arg1) {
+  return window['go']['main']['App']['SearchConfig'](arg1);
+}
+
+export function SetBackupRetention(arg1) {
+  return window['go']['main']['App']['SetBackupRetention'](arg1);
+}
+
+export function SetBranchRebase(arg1) {
+  return window['go']['main']['App']['SetBranchRebase'](arg1);
+}
+
+export function SetBranchUpstream(arg1) {
+  return window['go']['main']['App']['SetBranchUpstream'](arg1);
+}
+
+export function SetRemoteURL(arg1) {
+  return window['go']['main']['App']['SetRemoteURL'](arg1);
+}
+
+export function SimulateEnvironment(arg1) {
+  return window['go']['main']['App']['SimulateEnvironment'](arg1);
+}
+
+export function SimulateURLRewrite(arg1, arg2) {
+  return window['go']['main']['App']['SimulateURLRewrite'](arg1, arg2);
+}
+
+export function StageRule(arg1, arg2) {
+  return window['go']['main']['App']['StageRule'](arg1, arg2);
+}
+
+export function StageWrite(arg1) {
+  return window['go']['main']['App']['StageWrite'](arg1);
+}
+
 export function ToggleIncludeRule(arg1, arg2) {
   return window['go']['main']['App']['ToggleIncludeRule'](arg1, arg2);
 }
 
+export function TrustCommandKey(arg1, arg2, arg3) {
+  return window['go']['main']['App']['TrustCommandKey'](arg1, arg2, arg3);
+}
+
+export function TrustRepository(arg1, arg2) {
+  return window['go']['main']['App']['TrustRepository'](arg1, arg2);
+}
+
+export function Undo() {
+  return window['go']['main']['App']['Undo']();
+}
+
+export function UntrustCommandKey(arg1, arg2, arg3) {
+  return window['go']['main']['App']['UntrustCommandKey'](arg1, arg2, arg3);
+}
+
 export function UpsertIncludeRule(arg1) {
   return window['go']['main']['App']['UpsertIncludeRule'](arg1);
 }
 
+export function VerifyAuditLog() {
+  return window['go']['main']['App']['VerifyAuditLog']();
+}
+
 export function WriteConfig(arg1) {
   return window['go']['main']['App']['WriteConfig'](arg1);
 }
@@ -1,43 +1,1841 @@
 export namespace gitcfg {
 	
-	export class ChangeSet {
-	    id: string;
-	    repositoryId: string;
+	export class IncludeLink {
+	    file: string;
+	    line: number;
+	    directive: string;
+	    condition?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new IncludeLink(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.file = source["file"];
+	        this.line = source["line"];
+	        this.directive = source["directive"];
+	        this.condition = source["condition"];
+	    }
+	}
+	export class ConfigSource {
 	    scope: string;
-	    filePath: string;
-	    diff: string;
-	    backupPath: string;
-	    createdAt: string;
+	    file?: string;
+	    line?: number;
+	    order?: number;
+	    includeChain?: IncludeLink[];
 	
 	    static createFrom(source: any = {}) {
-	        return new ChangeSet(source);
+	        return new ConfigSource(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.scope = source["scope"];
+	        this.file = source["file"];
+	        this.line = source["line"];
+	        this.order = source["order"];
+	        this.includeChain = this.convertValues(source["includeChain"], IncludeLink);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Alias {
+	    name: string;
+	    command: string;
+	    kind: string;
+	    source: ConfigSource;
+	    overridden: boolean;
+	    shadowsBuiltin: boolean;
+	    references?: string;
+	    cycle?: string[];
+	    missingBinary?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Alias(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.command = source["command"];
+	        this.kind = source["kind"];
+	        this.source = this.convertValues(source["source"], ConfigSource);
+	        this.overridden = source["overridden"];
+	        this.shadowsBuiltin = source["shadowsBuiltin"];
+	        this.references = source["references"];
+	        this.cycle = source["cycle"];
+	        this.missingBinary = source["missingBinary"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class AliasExpansion {
+	    alias: string;
+	    args: string[];
+	    chain: string[];
+	    shell: boolean;
+	    argv: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new AliasExpansion(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.alias = source["alias"];
+	        this.args = source["args"];
+	        this.chain = source["chain"];
+	        this.shell = source["shell"];
+	        this.argv = source["argv"];
+	    }
+	}
+	export class ArchiveFile {
+	    name: string;
+	    originalPath: string;
+	    kind: string;
+	    scope: string;
+	    repositoryPath?: string;
+	    size: number;
+	    hash: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ArchiveFile(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.originalPath = source["originalPath"];
+	        this.kind = source["kind"];
+	        this.scope = source["scope"];
+	        this.repositoryPath = source["repositoryPath"];
+	        this.size = source["size"];
+	        this.hash = source["hash"];
+	    }
+	}
+	export class RuleConflict {
+	    ruleId: string;
+	    reason: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new RuleConflict(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.ruleId = source["ruleId"];
+	        this.reason = source["reason"];
+	    }
+	}
+	export class IncludeRule {
+	    id: string;
+	    pattern: string;
+	    targetPath: string;
+	    enabled: boolean;
+	    conflicts?: RuleConflict[];
+	    lastUpdated: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new IncludeRule(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
 	        this.id = source["id"];
+	        this.pattern = source["pattern"];
+	        this.targetPath = source["targetPath"];
+	        this.enabled = source["enabled"];
+	        this.conflicts = this.convertValues(source["conflicts"], RuleConflict);
+	        this.lastUpdated = source["lastUpdated"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ArchiveManifest {
+	    version: number;
+	    createdAt: string;
+	    user: string;
+	    home: string;
+	    files: ArchiveFile[];
+	    roots: string[];
+	    rules: IncludeRule[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ArchiveManifest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.version = source["version"];
+	        this.createdAt = source["createdAt"];
+	        this.user = source["user"];
+	        this.home = source["home"];
+	        this.files = this.convertValues(source["files"], ArchiveFile);
+	        this.roots = source["roots"];
+	        this.rules = this.convertValues(source["rules"], IncludeRule);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class AuditKeyChange {
+	    key: string;
+	    before?: string[];
+	    after?: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new AuditKeyChange(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.key = source["key"];
+	        this.before = source["before"];
+	        this.after = source["after"];
+	    }
+	}
+	export class AuditEntry {
+	    seq: number;
+	    timestamp: string;
+	    user: string;
+	    operation: string;
+	    repositoryId?: string;
+	    scope?: string;
+	    key?: string;
+	    file?: string;
+	    beforeHash?: string;
+	    afterHash?: string;
+	    diff?: string;
+	    keys?: AuditKeyChange[];
+	    changeSetId?: string;
+	    revertsId?: string;
+	    batchId?: string;
+	    detail?: string;
+	    prevHash: string;
+	    hash: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new AuditEntry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.seq = source["seq"];
+	        this.timestamp = source["timestamp"];
+	        this.user = source["user"];
+	        this.operation = source["operation"];
 	        this.repositoryId = source["repositoryId"];
 	        this.scope = source["scope"];
-	        this.filePath = source["filePath"];
+	        this.key = source["key"];
+	        this.file = source["file"];
+	        this.beforeHash = source["beforeHash"];
+	        this.afterHash = source["afterHash"];
 	        this.diff = source["diff"];
-	        this.backupPath = source["backupPath"];
+	        this.keys = this.convertValues(source["keys"], AuditKeyChange);
+	        this.changeSetId = source["changeSetId"];
+	        this.revertsId = source["revertsId"];
+	        this.batchId = source["batchId"];
+	        this.detail = source["detail"];
+	        this.prevHash = source["prevHash"];
+	        this.hash = source["hash"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class AuditQuery {
+	    repositoryId?: string;
+	    file?: string;
+	    key?: string;
+	    since?: string;
+	    until?: string;
+	    limit?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new AuditQuery(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.repositoryId = source["repositoryId"];
+	        this.file = source["file"];
+	        this.key = source["key"];
+	        this.since = source["since"];
+	        this.until = source["until"];
+	        this.limit = source["limit"];
+	    }
+	}
+	export class AuditVerification {
+	    valid: boolean;
+	    entries: number;
+	    brokenAt?: number;
+	    problem?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new AuditVerification(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.valid = source["valid"];
+	        this.entries = source["entries"];
+	        this.brokenAt = source["brokenAt"];
+	        this.problem = source["problem"];
+	    }
+	}
+	export class BackupRecord {
+	    id: string;
+	    filePath: string;
+	    hash: string;
+	    size: number;
+	    changeSetId?: string;
+	    createdAt: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new BackupRecord(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.filePath = source["filePath"];
+	        this.hash = source["hash"];
+	        this.size = source["size"];
+	        this.changeSetId = source["changeSetId"];
 	        this.createdAt = source["createdAt"];
 	    }
 	}
-	export class ConfigOverride {
-	    value: string;
+	export class BackupFile {
+	    filePath: string;
+	    backups: BackupRecord[];
+	
+	    static createFrom(source: any = {}) {
+	        return new BackupFile(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.filePath = source["filePath"];
+	        this.backups = this.convertValues(source["backups"], BackupRecord);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class BackupRetention {
+	    maxPerFile: number;
+	    maxAgeDays: number;
+	    maxTotalBytes: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new BackupRetention(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.maxPerFile = source["maxPerFile"];
+	        this.maxAgeDays = source["maxAgeDays"];
+	        this.maxTotalBytes = source["maxTotalBytes"];
+	    }
+	}
+	export class ChangeSet {
+	    id: string;
+	    repositoryId: string;
+	    scope: string;
+	    key?: string;
+	    operation?: string;
+	    filePath: string;
+	    diff: string;
+	    backupPath: string;
+	    baseHash?: string;
+	    afterHash?: string;
+	    created?: boolean;
+	    revertsId?: string;
+	    batchId?: string;
+	    createdAt: string;
+	    auditWarning?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ChangeSet(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.repositoryId = source["repositoryId"];
+	        this.scope = source["scope"];
+	        this.key = source["key"];
+	        this.operation = source["operation"];
+	        this.filePath = source["filePath"];
+	        this.diff = source["diff"];
+	        this.backupPath = source["backupPath"];
+	        this.baseHash = source["baseHash"];
+	        this.afterHash = source["afterHash"];
+	        this.created = source["created"];
+	        this.revertsId = source["revertsId"];
+	        this.batchId = source["batchId"];
+	        this.createdAt = source["createdAt"];
+	        this.auditWarning = source["auditWarning"];
+	    }
+	}
+	export class BatchResult {
+	    batchId: string;
+	    changeSets: ChangeSet[];
+	    rules?: IncludeRule[];
+	    auditWarnings?: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new BatchResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.batchId = source["batchId"];
+	        this.changeSets = this.convertValues(source["changeSets"], ChangeSet);
+	        this.rules = this.convertValues(source["rules"], IncludeRule);
+	        this.auditWarnings = source["auditWarnings"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class BranchConfig {
+	    name: string;
+	    exists: boolean;
+	    remote?: string;
+	    merge?: string;
+	    rebase?: string;
+	    pushRemote?: string;
+	    other?: Record<string, string>;
+	    source: ConfigSource;
+	
+	    static createFrom(source: any = {}) {
+	        return new BranchConfig(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.exists = source["exists"];
+	        this.remote = source["remote"];
+	        this.merge = source["merge"];
+	        this.rebase = source["rebase"];
+	        this.pushRemote = source["pushRemote"];
+	        this.other = source["other"];
+	        this.source = this.convertValues(source["source"], ConfigSource);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class BranchRequest {
+	    repositoryId: string;
+	    branch: string;
+	    remote?: string;
+	    merge?: string;
+	    rebase?: string;
+	    dryRun: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new BranchRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.repositoryId = source["repositoryId"];
+	        this.branch = source["branch"];
+	        this.remote = source["remote"];
+	        this.merge = source["merge"];
+	        this.rebase = source["rebase"];
+	        this.dryRun = source["dryRun"];
+	    }
+	}
+	
+	export class CommandKeyFinding {
+	    key: string;
+	    value: string;
+	    source: ConfigSource;
+	    local: boolean;
+	    trusted: boolean;
+	    fingerprint: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new CommandKeyFinding(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.key = source["key"];
+	        this.value = source["value"];
+	        this.source = this.convertValues(source["source"], ConfigSource);
+	        this.local = source["local"];
+	        this.trusted = source["trusted"];
+	        this.fingerprint = source["fingerprint"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ConfigOverride {
+	    value: string;
+	    normalized?: string;
+	    source: ConfigSource;
+	    timestamp: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ConfigOverride(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.value = source["value"];
+	        this.normalized = source["normalized"];
+	        this.source = this.convertValues(source["source"], ConfigSource);
+	        this.timestamp = source["timestamp"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ConfigValue {
+	    key: string;
+	    displayKey?: string;
+	    value: string;
+	    type: string;
+	    normalized?: string;
+	    typeError?: string;
+	    redacted?: boolean;
+	    source: ConfigSource;
+	    overrides?: ConfigOverride[];
+	    lastModified: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ConfigValue(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.key = source["key"];
+	        this.displayKey = source["displayKey"];
+	        this.value = source["value"];
+	        this.type = source["type"];
+	        this.normalized = source["normalized"];
+	        this.typeError = source["typeError"];
+	        this.redacted = source["redacted"];
+	        this.source = this.convertValues(source["source"], ConfigSource);
+	        this.overrides = this.convertValues(source["overrides"], ConfigOverride);
+	        this.lastModified = source["lastModified"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ConfigMatrix {
+	    repositoryId: string;
+	    entries: Record<string, ConfigValue>;
+	    retrievedAt: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ConfigMatrix(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.repositoryId = source["repositoryId"];
+	        this.entries = this.convertValues(source["entries"], ConfigValue, true);
+	        this.retrievedAt = source["retrievedAt"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class ConfigPair {
+	    key: string;
+	    value: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ConfigPair(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.key = source["key"];
+	        this.value = source["value"];
+	    }
+	}
+	
+	
+	export class CredentialEntry {
+	    key: string;
+	    pattern?: string;
+	    value: string;
+	    source: ConfigSource;
+	
+	    static createFrom(source: any = {}) {
+	        return new CredentialEntry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.key = source["key"];
+	        this.pattern = source["pattern"];
+	        this.value = source["value"];
+	        this.source = this.convertValues(source["source"], ConfigSource);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class CredentialResolution {
+	    input: string;
+	    remote?: string;
+	    url: string;
+	    helpers: CredentialEntry[];
+	    settings: CredentialEntry[];
+	
+	    static createFrom(source: any = {}) {
+	        return new CredentialResolution(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.input = source["input"];
+	        this.remote = source["remote"];
+	        this.url = source["url"];
+	        this.helpers = this.convertValues(source["helpers"], CredentialEntry);
+	        this.settings = this.convertValues(source["settings"], CredentialEntry);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class DiagnosticIssue {
+	    severity: string;
+	    message: string;
+	    suggestion?: string;
+	    relatedFile?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new DiagnosticIssue(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.severity = source["severity"];
+	        this.message = source["message"];
+	        this.suggestion = source["suggestion"];
+	        this.relatedFile = source["relatedFile"];
+	    }
+	}
+	export class DiagnosticsReport {
+	    repositoryId: string;
+	    checkedAt: string;
+	    issues?: DiagnosticIssue[];
+	
+	    static createFrom(source: any = {}) {
+	        return new DiagnosticsReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.repositoryId = source["repositoryId"];
+	        this.checkedAt = source["checkedAt"];
+	        this.issues = this.convertValues(source["issues"], DiagnosticIssue);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class EditStep {
+	    label: string;
+	    files?: string[];
+	    ruleIds?: string[];
+	    roots?: string[];
+	    changeSetIds?: string[];
+	    batchId?: string;
+	    at: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new EditStep(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.label = source["label"];
+	        this.files = source["files"];
+	        this.ruleIds = source["ruleIds"];
+	        this.roots = source["roots"];
+	        this.changeSetIds = source["changeSetIds"];
+	        this.batchId = source["batchId"];
+	        this.at = source["at"];
+	    }
+	}
+	export class EditHistory {
+	    undo: EditStep[];
+	    redo: EditStep[];
+	
+	    static createFrom(source: any = {}) {
+	        return new EditHistory(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.undo = this.convertValues(source["undo"], EditStep);
+	        this.redo = this.convertValues(source["redo"], EditStep);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class EditResult {
+	    step: EditStep;
+	    changeSets: ChangeSet[];
+	    auditWarnings?: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new EditResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.step = this.convertValues(source["step"], EditStep);
+	        this.changeSets = this.convertValues(source["changeSets"], ChangeSet);
+	        this.auditWarnings = source["auditWarnings"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class EnvOverride {
+	    name: string;
+	    value: string;
+	    unset?: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new EnvOverride(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.value = source["value"];
+	        this.unset = source["unset"];
+	    }
+	}
+	export class ExportRequest {
+	    path: string;
+	    format: string;
+	    includeSystem?: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new ExportRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.format = source["format"];
+	        this.includeSystem = source["includeSystem"];
+	    }
+	}
+	export class HostMigrationRequest {
+	    from: string;
+	    to: string;
+	    dryRun: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new HostMigrationRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.from = source["from"];
+	        this.to = source["to"];
+	        this.dryRun = source["dryRun"];
+	    }
+	}
+	export class HostMigrationSkip {
+	    repositoryId: string;
+	    repositoryName: string;
+	    error: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new HostMigrationSkip(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.repositoryId = source["repositoryId"];
+	        this.repositoryName = source["repositoryName"];
+	        this.error = source["error"];
+	    }
+	}
+	export class HostMigrationResult {
+	    changeSets: ChangeSet[];
+	    skipped: HostMigrationSkip[];
+	
+	    static createFrom(source: any = {}) {
+	        return new HostMigrationResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.changeSets = this.convertValues(source["changeSets"], ChangeSet);
+	        this.skipped = this.convertValues(source["skipped"], HostMigrationSkip);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class RepositoryImpact {
+	    repositoryId: string;
+	    repositoryName: string;
+	    path: string;
+	    key: string;
+	    change: string;
+	    before?: string;
+	    after?: string;
+	    hidden?: boolean;
+	    effective?: string;
+	    hiddenBy: ConfigSource;
+	
+	    static createFrom(source: any = {}) {
+	        return new RepositoryImpact(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.repositoryId = source["repositoryId"];
+	        this.repositoryName = source["repositoryName"];
+	        this.path = source["path"];
+	        this.key = source["key"];
+	        this.change = source["change"];
+	        this.before = source["before"];
+	        this.after = source["after"];
+	        this.hidden = source["hidden"];
+	        this.effective = source["effective"];
+	        this.hiddenBy = this.convertValues(source["hiddenBy"], ConfigSource);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ImpactReport {
+	    filePath: string;
+	    diff: string;
+	    keys: string[];
+	    impacts: RepositoryImpact[];
+	    repositories: number;
+	    analyzedAt: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ImpactReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.filePath = source["filePath"];
+	        this.diff = source["diff"];
+	        this.keys = source["keys"];
+	        this.impacts = this.convertValues(source["impacts"], RepositoryImpact);
+	        this.repositories = source["repositories"];
+	        this.analyzedAt = source["analyzedAt"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ImportItem {
+	    file: ArchiveFile;
+	    targetPath: string;
+	    action: string;
+	    reason?: string;
+	    diff?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ImportItem(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.file = this.convertValues(source["file"], ArchiveFile);
+	        this.targetPath = source["targetPath"];
+	        this.action = source["action"];
+	        this.reason = source["reason"];
+	        this.diff = source["diff"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class PathMapping {
+	    from: string;
+	    to: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new PathMapping(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.from = source["from"];
+	        this.to = source["to"];
+	    }
+	}
+	export class ImportPlan {
+	    manifest: ArchiveManifest;
+	    remap: PathMapping[];
+	    items: ImportItem[];
+	    roots?: string[];
+	    rules?: IncludeRule[];
+	    replacedRules?: IncludeRule[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ImportPlan(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.manifest = this.convertValues(source["manifest"], ArchiveManifest);
+	        this.remap = this.convertValues(source["remap"], PathMapping);
+	        this.items = this.convertValues(source["items"], ImportItem);
+	        this.roots = source["roots"];
+	        this.rules = this.convertValues(source["rules"], IncludeRule);
+	        this.replacedRules = this.convertValues(source["replacedRules"], IncludeRule);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ImportRequest {
+	    path: string;
+	    remap?: PathMapping[];
+	    includeSystem?: boolean;
+	    restoreState?: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new ImportRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.remap = this.convertValues(source["remap"], PathMapping);
+	        this.includeSystem = source["includeSystem"];
+	        this.restoreState = source["restoreState"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ImportResult {
+	    plan: ImportPlan;
+	    batchId: string;
+	    changeSets: ChangeSet[];
+	    auditWarnings?: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ImportResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.plan = this.convertValues(source["plan"], ImportPlan);
+	        this.batchId = source["batchId"];
+	        this.changeSets = this.convertValues(source["changeSets"], ChangeSet);
+	        this.auditWarnings = source["auditWarnings"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class IncludeFinding {
+	    kind: string;
+	    severity: string;
+	    file: string;
+	    line?: number;
+	    files?: string[];
+	    message: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new IncludeFinding(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.kind = source["kind"];
+	        this.severity = source["severity"];
+	        this.file = source["file"];
+	        this.line = source["line"];
+	        this.files = source["files"];
+	        this.message = source["message"];
+	    }
+	}
+	export class IncludeEdge {
+	    from: string;
+	    line: number;
+	    to: string;
+	    directive: string;
+	    condition?: string;
+	    active: boolean;
+	    reason?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new IncludeEdge(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.from = source["from"];
+	        this.line = source["line"];
+	        this.to = source["to"];
+	        this.directive = source["directive"];
+	        this.condition = source["condition"];
+	        this.active = source["active"];
+	        this.reason = source["reason"];
+	    }
+	}
+	export class IncludeNode {
+	    file: string;
+	    scope: string;
+	    root: boolean;
+	    exists: boolean;
+	    mode?: string;
+	    size: number;
+	    active: boolean;
+	    error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new IncludeNode(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.file = source["file"];
+	        this.scope = source["scope"];
+	        this.root = source["root"];
+	        this.exists = source["exists"];
+	        this.mode = source["mode"];
+	        this.size = source["size"];
+	        this.active = source["active"];
+	        this.error = source["error"];
+	    }
+	}
+	export class IncludeGraph {
+	    repositoryId: string;
+	    nodes: IncludeNode[];
+	    edges: IncludeEdge[];
+	
+	    static createFrom(source: any = {}) {
+	        return new IncludeGraph(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.repositoryId = source["repositoryId"];
+	        this.nodes = this.convertValues(source["nodes"], IncludeNode);
+	        this.edges = this.convertValues(source["edges"], IncludeEdge);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class IncludeAnalysis {
+	    graph: IncludeGraph;
+	    repositories: number;
+	    findings: IncludeFinding[];
+	    analyzedAt: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new IncludeAnalysis(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.graph = this.convertValues(source["graph"], IncludeGraph);
+	        this.repositories = source["repositories"];
+	        this.findings = this.convertValues(source["findings"], IncludeFinding);
+	        this.analyzedAt = source["analyzedAt"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	
+	
+	
+	
+	
+	export class KeyHistoryEvent {
+	    timestamp: string;
+	    operation: string;
+	    user?: string;
+	    scope?: string;
+	    file: string;
+	    before?: string[];
+	    values?: string[];
+	    removed?: boolean;
+	    changeSetId?: string;
+	    batchId?: string;
+	    revertsId?: string;
+	    external?: boolean;
+	    reverted?: boolean;
+	    revertedBy?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new KeyHistoryEvent(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.timestamp = source["timestamp"];
+	        this.operation = source["operation"];
+	        this.user = source["user"];
+	        this.scope = source["scope"];
+	        this.file = source["file"];
+	        this.before = source["before"];
+	        this.values = source["values"];
+	        this.removed = source["removed"];
+	        this.changeSetId = source["changeSetId"];
+	        this.batchId = source["batchId"];
+	        this.revertsId = source["revertsId"];
+	        this.external = source["external"];
+	        this.reverted = source["reverted"];
+	        this.revertedBy = source["revertedBy"];
+	    }
+	}
+	export class KeyHistory {
+	    repositoryId: string;
+	    key: string;
+	    events: KeyHistoryEvent[];
+	
+	    static createFrom(source: any = {}) {
+	        return new KeyHistory(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.repositoryId = source["repositoryId"];
+	        this.key = source["key"];
+	        this.events = this.convertValues(source["events"], KeyHistoryEvent);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class KeyInfo {
+	    key: string;
+	    summary: string;
+	    type: string;
+	    default?: string;
+	    enum?: string[];
+	    since?: string;
+	    deprecated?: string;
+	    multiValued?: boolean;
+	    urlMatch?: boolean;
+	    executes?: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new KeyInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.key = source["key"];
+	        this.summary = source["summary"];
+	        this.type = source["type"];
+	        this.default = source["default"];
+	        this.enum = source["enum"];
+	        this.since = source["since"];
+	        this.deprecated = source["deprecated"];
+	        this.multiValued = source["multiValued"];
+	        this.urlMatch = source["urlMatch"];
+	        this.executes = source["executes"];
+	    }
+	}
+	
+	export class Remote {
+	    name: string;
+	    urls: string[];
+	    pushUrls?: string[];
+	    fetch?: string[];
+	    push?: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Remote(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.urls = source["urls"];
+	        this.pushUrls = source["pushUrls"];
+	        this.fetch = source["fetch"];
+	        this.push = source["push"];
+	    }
+	}
+	export class URLMatchSetting {
+	    key: string;
+	    value: string;
+	    pattern?: string;
+	    source: ConfigSource;
+	
+	    static createFrom(source: any = {}) {
+	        return new URLMatchSetting(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.key = source["key"];
+	        this.value = source["value"];
+	        this.pattern = source["pattern"];
+	        this.source = this.convertValues(source["source"], ConfigSource);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class RemoteHTTPSettings {
+	    remote: string;
+	    url: string;
+	    settings: URLMatchSetting[];
+	    note?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new RemoteHTTPSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.remote = source["remote"];
+	        this.url = source["url"];
+	        this.settings = this.convertValues(source["settings"], URLMatchSetting);
+	        this.note = source["note"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class RemoteRequest {
+	    repositoryId: string;
+	    name: string;
+	    newName?: string;
+	    url?: string;
+	    push?: boolean;
+	    dryRun: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new RemoteRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.repositoryId = source["repositoryId"];
+	        this.name = source["name"];
+	        this.newName = source["newName"];
+	        this.url = source["url"];
+	        this.push = source["push"];
+	        this.dryRun = source["dryRun"];
+	    }
+	}
+	export class Repository {
+	    id: string;
+	    name: string;
+	    path: string;
+	    root: string;
+	    type: string;
+	    parentId?: string;
+	    isBare: boolean;
+	    isWorktree: boolean;
+	    isSubmodule: boolean;
+	    gitDir: string;
+	    lastScanTime: string;
+	    status: string;
+	    remotes?: Remote[];
+	    remotesError?: string;
+	    safeDirectory?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Repository(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.path = source["path"];
+	        this.root = source["root"];
+	        this.type = source["type"];
+	        this.parentId = source["parentId"];
+	        this.isBare = source["isBare"];
+	        this.isWorktree = source["isWorktree"];
+	        this.isSubmodule = source["isSubmodule"];
+	        this.gitDir = source["gitDir"];
+	        this.lastScanTime = source["lastScanTime"];
+	        this.status = source["status"];
+	        this.remotes = this.convertValues(source["remotes"], Remote);
+	        this.remotesError = source["remotesError"];
+	        this.safeDirectory = source["safeDirectory"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class URLRewrite {
+	    base: string;
+	    prefix: string;
+	    push: boolean;
+	    source: ConfigSource;
+	
+	    static createFrom(source: any = {}) {
+	        return new URLRewrite(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.base = source["base"];
+	        this.prefix = source["prefix"];
+	        this.push = source["push"];
+	        this.source = this.convertValues(source["source"], ConfigSource);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class RewriteSimulation {
+	    input: string;
+	    remote?: string;
+	    fetchUrls: string[];
+	    pushUrls: string[];
+	    fetchRule?: URLRewrite;
+	    pushRule?: URLRewrite;
+	
+	    static createFrom(source: any = {}) {
+	        return new RewriteSimulation(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.input = source["input"];
+	        this.remote = source["remote"];
+	        this.fetchUrls = source["fetchUrls"];
+	        this.pushUrls = source["pushUrls"];
+	        this.fetchRule = this.convertValues(source["fetchRule"], URLRewrite);
+	        this.pushRule = this.convertValues(source["pushRule"], URLRewrite);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class SafeDirectory {
+	    path: string;
 	    source: ConfigSource;
-	    timestamp: string;
+	    stale: boolean;
+	    note?: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new ConfigOverride(source);
+	        return new SafeDirectory(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.value = source["value"];
+	        this.path = source["path"];
 	        this.source = this.convertValues(source["source"], ConfigSource);
-	        this.timestamp = source["timestamp"];
+	        this.stale = source["stale"];
+	        this.note = source["note"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -58,40 +1856,76 @@ export namespace gitcfg {
 		    return a;
 		}
 	}
-	export class ConfigSource {
-	    scope: string;
-	    file?: string;
-	    line?: number;
+	export class ScanOptions {
+	    forceRefresh: boolean;
 	
 	    static createFrom(source: any = {}) {
-	        return new ConfigSource(source);
+	        return new ScanOptions(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.scope = source["scope"];
-	        this.file = source["file"];
-	        this.line = source["line"];
+	        this.forceRefresh = source["forceRefresh"];
 	    }
 	}
-	export class ConfigValue {
+	export class SearchQuery {
+	    keyPattern?: string;
+	    keyRegex?: boolean;
+	    valuePattern?: string;
+	    scopes?: string[];
+	    includeOverrides?: boolean;
+	    forceRefresh?: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new SearchQuery(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.keyPattern = source["keyPattern"];
+	        this.keyRegex = source["keyRegex"];
+	        this.valuePattern = source["valuePattern"];
+	        this.scopes = source["scopes"];
+	        this.includeOverrides = source["includeOverrides"];
+	        this.forceRefresh = source["forceRefresh"];
+	    }
+	}
+	export class SearchSkip {
+	    repositoryId: string;
+	    repositoryName: string;
+	    error: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SearchSkip(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.repositoryId = source["repositoryId"];
+	        this.repositoryName = source["repositoryName"];
+	        this.error = source["error"];
+	    }
+	}
+	export class SearchResult {
+	    repositoryId: string;
+	    repositoryName: string;
 	    key: string;
 	    value: string;
 	    source: ConfigSource;
-	    overrides?: ConfigOverride[];
-	    lastModified: string;
+	    overridden: boolean;
 	
 	    static createFrom(source: any = {}) {
-	        return new ConfigValue(source);
+	        return new SearchResult(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.repositoryId = source["repositoryId"];
+	        this.repositoryName = source["repositoryName"];
 	        this.key = source["key"];
 	        this.value = source["value"];
 	        this.source = this.convertValues(source["source"], ConfigSource);
-	        this.overrides = this.convertValues(source["overrides"], ConfigOverride);
-	        this.lastModified = source["lastModified"];
+	        this.overridden = source["overridden"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -112,20 +1946,18 @@ export namespace gitcfg {
 		    return a;
 		}
 	}
-	export class ConfigMatrix {
-	    repositoryId: string;
-	    entries: Record<string, ConfigValue>;
-	    retrievedAt: string;
+	export class SearchResponse {
+	    results: SearchResult[];
+	    skipped: SearchSkip[];
 	
 	    static createFrom(source: any = {}) {
-	        return new ConfigMatrix(source);
+	        return new SearchResponse(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.repositoryId = source["repositoryId"];
-	        this.entries = this.convertValues(source["entries"], ConfigValue, true);
-	        this.retrievedAt = source["retrievedAt"];
+	        this.results = this.convertValues(source["results"], SearchResult);
+	        this.skipped = this.convertValues(source["skipped"], SearchSkip);
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -148,39 +1980,54 @@ export namespace gitcfg {
 	}
 	
 	
-	
-	export class DiagnosticIssue {
-	    severity: string;
-	    message: string;
-	    suggestion?: string;
-	    relatedFile?: string;
+	export class SimulationRequest {
+	    repositoryId: string;
+	    env?: EnvOverride[];
+	    envConfig?: ConfigPair[];
+	    commandLine?: ConfigPair[];
 	
 	    static createFrom(source: any = {}) {
-	        return new DiagnosticIssue(source);
+	        return new SimulationRequest(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.severity = source["severity"];
-	        this.message = source["message"];
-	        this.suggestion = source["suggestion"];
-	        this.relatedFile = source["relatedFile"];
+	        this.repositoryId = source["repositoryId"];
+	        this.env = this.convertValues(source["env"], EnvOverride);
+	        this.envConfig = this.convertValues(source["envConfig"], ConfigPair);
+	        this.commandLine = this.convertValues(source["commandLine"], ConfigPair);
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
-	export class DiagnosticsReport {
-	    repositoryId: string;
-	    checkedAt: string;
-	    issues?: DiagnosticIssue[];
+	export class SimulationResult {
+	    matrix: ConfigMatrix;
+	    baseline: ConfigMatrix;
 	
 	    static createFrom(source: any = {}) {
-	        return new DiagnosticsReport(source);
+	        return new SimulationResult(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.repositoryId = source["repositoryId"];
-	        this.checkedAt = source["checkedAt"];
-	        this.issues = this.convertValues(source["issues"], DiagnosticIssue);
+	        this.matrix = this.convertValues(source["matrix"], ConfigMatrix);
+	        this.baseline = this.convertValues(source["baseline"], ConfigMatrix);
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -201,40 +2048,56 @@ export namespace gitcfg {
 		    return a;
 		}
 	}
-	export class RuleConflict {
-	    ruleId: string;
-	    reason: string;
+	export class WriteRequest {
+	    repositoryId: string;
+	    scope: string;
+	    key: string;
+	    value: string;
+	    operation?: string;
+	    matchValue?: string;
+	    targetPath?: string;
+	    dryRun: boolean;
+	    expectedHash?: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new RuleConflict(source);
+	        return new WriteRequest(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.ruleId = source["ruleId"];
-	        this.reason = source["reason"];
+	        this.repositoryId = source["repositoryId"];
+	        this.scope = source["scope"];
+	        this.key = source["key"];
+	        this.value = source["value"];
+	        this.operation = source["operation"];
+	        this.matchValue = source["matchValue"];
+	        this.targetPath = source["targetPath"];
+	        this.dryRun = source["dryRun"];
+	        this.expectedHash = source["expectedHash"];
 	    }
 	}
-	export class IncludeRule {
+	export class StagedChange {
 	    id: string;
-	    pattern: string;
-	    targetPath: string;
-	    enabled: boolean;
-	    conflicts?: RuleConflict[];
-	    lastUpdated: string;
+	    kind: string;
+	    description: string;
+	    write?: WriteRequest;
+	    rule?: IncludeRule;
+	    filePath?: string;
+	    stagedAt: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new IncludeRule(source);
+	        return new StagedChange(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
 	        this.id = source["id"];
-	        this.pattern = source["pattern"];
-	        this.targetPath = source["targetPath"];
-	        this.enabled = source["enabled"];
-	        this.conflicts = this.convertValues(source["conflicts"], RuleConflict);
-	        this.lastUpdated = source["lastUpdated"];
+	        this.kind = source["kind"];
+	        this.description = source["description"];
+	        this.write = this.convertValues(source["write"], WriteRequest);
+	        this.rule = this.convertValues(source["rule"], IncludeRule);
+	        this.filePath = source["filePath"];
+	        this.stagedAt = source["stagedAt"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -255,75 +2118,160 @@ export namespace gitcfg {
 		    return a;
 		}
 	}
-	export class Repository {
-	    id: string;
-	    name: string;
-	    path: string;
-	    root: string;
-	    type: string;
-	    parentId?: string;
-	    isBare: boolean;
-	    isWorktree: boolean;
-	    isSubmodule: boolean;
-	    gitDir: string;
-	    lastScanTime: string;
-	    status: string;
+	export class StagedPreview {
+	    changes: StagedChange[];
+	    files: ChangeSet[];
+	    diff: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new Repository(source);
+	        return new StagedPreview(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.id = source["id"];
-	        this.name = source["name"];
-	        this.path = source["path"];
-	        this.root = source["root"];
-	        this.type = source["type"];
-	        this.parentId = source["parentId"];
-	        this.isBare = source["isBare"];
-	        this.isWorktree = source["isWorktree"];
-	        this.isSubmodule = source["isSubmodule"];
-	        this.gitDir = source["gitDir"];
-	        this.lastScanTime = source["lastScanTime"];
-	        this.status = source["status"];
+	        this.changes = this.convertValues(source["changes"], StagedChange);
+	        this.files = this.convertValues(source["files"], ChangeSet);
+	        this.diff = source["diff"];
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
+	export class StaleSections {
+	    branches: string[];
+	    remotes: string[];
 	
-	export class ScanOptions {
-	    forceRefresh: boolean;
+	    static createFrom(source: any = {}) {
+	        return new StaleSections(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.branches = source["branches"];
+	        this.remotes = source["remotes"];
+	    }
+	}
+	export class StoredCredential {
+	    id: string;
+	    file: string;
+	    line: number;
+	    url: string;
+	    protocol: string;
+	    host: string;
+	    path?: string;
+	    username?: string;
+	    hasPassword: boolean;
 	
 	    static createFrom(source: any = {}) {
-	        return new ScanOptions(source);
+	        return new StoredCredential(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.forceRefresh = source["forceRefresh"];
+	        this.id = source["id"];
+	        this.file = source["file"];
+	        this.line = source["line"];
+	        this.url = source["url"];
+	        this.protocol = source["protocol"];
+	        this.host = source["host"];
+	        this.path = source["path"];
+	        this.username = source["username"];
+	        this.hasPassword = source["hasPassword"];
 	    }
 	}
-	export class WriteRequest {
-	    repositoryId: string;
+	
+	
+	export class URLRewriteRequest {
+	    repositoryId?: string;
 	    scope: string;
-	    key: string;
-	    value: string;
 	    targetPath?: string;
+	    base: string;
+	    prefix: string;
+	    push: boolean;
 	    dryRun: boolean;
 	
 	    static createFrom(source: any = {}) {
-	        return new WriteRequest(source);
+	        return new URLRewriteRequest(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
 	        this.repositoryId = source["repositoryId"];
 	        this.scope = source["scope"];
-	        this.key = source["key"];
-	        this.value = source["value"];
 	        this.targetPath = source["targetPath"];
+	        this.base = source["base"];
+	        this.prefix = source["prefix"];
+	        this.push = source["push"];
 	        this.dryRun = source["dryRun"];
 	    }
 	}
+	export class WhatIfRequest {
+	    path: string;
+	    branch?: string;
+	    remoteUrl?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new WhatIfRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.branch = source["branch"];
+	        this.remoteUrl = source["remoteUrl"];
+	    }
+	}
+	export class WhatIfResult {
+	    path: string;
+	    gitDir: string;
+	    matrix: ConfigMatrix;
+	    graph: IncludeGraph;
+	
+	    static createFrom(source: any = {}) {
+	        return new WhatIfResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.gitDir = source["gitDir"];
+	        this.matrix = this.convertValues(source["matrix"], ConfigMatrix);
+	        this.graph = this.convertValues(source["graph"], IncludeGraph);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 
 }
 
@@ -0,0 +1,368 @@
+package gitcfg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// AliasKind distinguishes aliases executed by the shell from those expanded into git commands.
+type AliasKind string
+
+const (
+	AliasKindGit   AliasKind = "git"
+	AliasKindShell AliasKind = "shell"
+)
+
+// Alias describes one alias.<name> definition and the problems detected for it.
+type Alias struct {
+	Name    string       `json:"name"`
+	Command string       `json:"command"`
+	Kind    AliasKind    `json:"kind"`
+	Source  ConfigSource `json:"source"`
+	// Overridden is set when a later definition of the same alias takes precedence.
+	Overridden bool `json:"overridden"`
+	// ShadowsBuiltin is set when the name is a git command; git ignores such aliases.
+	ShadowsBuiltin bool `json:"shadowsBuiltin"`
+	// References lists the alias invoked by this one, if any.
+	References string `json:"references,omitempty"`
+	// Cycle holds the alias chain when following references loops back.
+	Cycle []string `json:"cycle,omitempty"`
+	// MissingBinary names the program a shell alias runs that could not be found in PATH.
+	MissingBinary string `json:"missingBinary,omitempty"`
+}
+
+// AliasExpansion shows what git runs for an alias invocation.
+type AliasExpansion struct {
+	Alias string   `json:"alias"`
+	Args  []string `json:"args"`
+	// Chain lists the aliases expanded in order, starting with Alias.
+	Chain []string `json:"chain"`
+	Shell bool     `json:"shell"`
+	// Argv is the final command line, starting with "git" or "sh".
+	Argv []string `json:"argv"`
+}
+
+var (
+	gitCommandsOnce sync.Once
+	gitCommands     map[string]bool
+)
+
+// fallbackGitCommands is used when the installed git cannot list its commands.
+var fallbackGitCommands = []string{
+	"add", "am", "archive", "bisect", "blame", "branch", "bundle", "checkout", "cherry-pick",
+	"clean", "clone", "commit", "config", "describe", "diff", "fetch", "format-patch", "gc",
+	"grep", "init", "log", "merge", "mv", "notes", "pull", "push", "rebase", "reflog", "remote",
+	"reset", "restore", "revert", "rm", "shortlog", "show", "stash", "status", "submodule",
+	"switch", "tag", "worktree",
+}
+
+// knownGitCommands lists the commands of the installed git that an alias cannot override.
+func knownGitCommands() map[string]bool {
+	gitCommandsOnce.Do(func() {
+		gitCommands = make(map[string]bool)
+		out, err := exec.Command("git", "--list-cmds=main,nohelpers").Output()
+		if err != nil || len(out) == 0 {
+			for _, name := range fallbackGitCommands {
+				gitCommands[name] = true
+			}
+			return
+		}
+		for _, name := range strings.Fields(string(out)) {
+			gitCommands[name] = true
+		}
+	})
+	return gitCommands
+}
+
+// splitCmdline splits an alias value into words following git's split_cmdline: single and
+// double quotes group words and a backslash escapes the next character outside single quotes.
+func splitCmdline(cmdline string) ([]string, error) {
+	var (
+		words   []string
+		current strings.Builder
+		inWord  bool
+		quote   byte
+	)
+
+	for i := 0; i < len(cmdline); i++ {
+		c := cmdline[i]
+		switch {
+		case quote == 0 && (c == ' ' || c == '\t' || c == '\n'):
+			if inWord {
+				words = append(words, current.String())
+				current.Reset()
+				inWord = false
+			}
+		case c == '\\' && quote != '\'':
+			i++
+			if i == len(cmdline) {
+				return nil, errors.New("cmdline ends with \\")
+			}
+			current.WriteByte(cmdline[i])
+			inWord = true
+		case quote == 0 && (c == '\'' || c == '"'):
+			quote = c
+			inWord = true
+		case c == quote:
+			quote = 0
+		default:
+			current.WriteByte(c)
+			inWord = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, errors.New("unclosed quote")
+	}
+	if inWord {
+		words = append(words, current.String())
+	}
+	return words, nil
+}
+
+// collectAliases extracts alias definitions from matrix, keyed by alias name. The last
+// definition of each name is the active one.
+func collectAliases(matrix ConfigMatrix) map[string][]Alias {
+	aliases := make(map[string][]Alias)
+	for key, value := range matrix.Entries {
+		parsed, err := ParseKey(key)
+		if err != nil || parsed.Section != "alias" {
+			continue
+		}
+		name := parsed.Name
+		if parsed.Subsection != "" {
+			name = parsed.Subsection + "." + parsed.Name
+		}
+
+		definitions := valuesInOrder(value)
+		for i, entry := range definitions {
+			kind := AliasKindGit
+			if strings.HasPrefix(entry.Value, "!") {
+				kind = AliasKindShell
+			}
+			aliases[name] = append(aliases[name], Alias{
+				Name:       name,
+				Command:    entry.Value,
+				Kind:       kind,
+				Source:     entry.Source,
+				Overridden: i < len(definitions)-1,
+			})
+		}
+	}
+	return aliases
+}
+
+// aliasTarget returns the command a git alias invokes, or "" for shell or empty aliases.
+func aliasTarget(alias Alias) string {
+	if alias.Kind != AliasKindGit {
+		return ""
+	}
+	words, err := splitCmdline(alias.Command)
+	if err != nil || len(words) == 0 {
+		return ""
+	}
+	return words[0]
+}
+
+// analyzeAliases annotates every alias with shadowing, reference, cycle and missing binary
+// information, using lookPath to resolve shell commands.
+func analyzeAliases(defs map[string][]Alias, builtins map[string]bool, lookPath func(string) error) []Alias {
+	active := func(name string) (Alias, bool) {
+		list := defs[name]
+		if len(list) == 0 {
+			return Alias{}, false
+		}
+		return list[len(list)-1], true
+	}
+
+	var result []Alias
+	for name, list := range defs {
+		for _, alias := range list {
+			alias.ShadowsBuiltin = builtins[name]
+
+			if target := aliasTarget(alias); target != "" && !builtins[target] {
+				if _, ok := active(target); ok {
+					alias.References = target
+					alias.Cycle = findAliasCycle(name, alias, active, builtins)
+				}
+			}
+
+			if alias.Kind == AliasKindShell {
+				if binary := shellAliasBinary(alias.Command); binary != "" && lookPath(binary) != nil {
+					alias.MissingBinary = binary
+				}
+			}
+
+			result = append(result, alias)
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Name != result[j].Name {
+			return result[i].Name < result[j].Name
+		}
+		return result[i].Overridden && !result[j].Overridden
+	})
+	return result
+}
+
+func findAliasCycle(start string, alias Alias, active func(string) (Alias, bool), builtins map[string]bool) []string {
+	chain := []string{start}
+	seen := map[string]bool{start: true}
+	for {
+		target := aliasTarget(alias)
+		if target == "" || builtins[target] {
+			return nil
+		}
+		next, ok := active(target)
+		if !ok {
+			return nil
+		}
+		chain = append(chain, target)
+		if seen[target] {
+			return chain
+		}
+		seen[target] = true
+		alias = next
+	}
+}
+
+// shellBuiltins are shell words that never resolve to a binary in PATH.
+var shellBuiltins = map[string]bool{
+	"cd": true, "echo": true, "exec": true, "export": true, "set": true, "test": true, "[": true,
+	"if": true, "for": true, "while": true, "case": true, "{": true, "(": true, "eval": true,
+	"printf": true, "read": true, "true": true, "false": true, ":": true, ".": true, "source": true,
+	"git": true,
+}
+
+// shellAliasBinary guesses the program a shell alias starts, skipping variable assignments.
+// Aliases defining shell functions or starting with builtins yield "".
+func shellAliasBinary(command string) string {
+	words, err := splitCmdline(strings.TrimPrefix(command, "!"))
+	if err != nil {
+		return ""
+	}
+	for _, word := range words {
+		if strings.Contains(word, "=") && !strings.HasPrefix(word, "=") {
+			continue
+		}
+		if shellBuiltins[word] || strings.ContainsAny(word, "(){};|&$`") {
+			return ""
+		}
+		return word
+	}
+	return ""
+}
+
+func lookPathError(binary string) error {
+	if strings.Contains(binary, "/") {
+		expanded, err := expandPath(binary)
+		if err != nil {
+			return err
+		}
+		_, err = os.Stat(expanded)
+		return err
+	}
+	_, err := exec.LookPath(binary)
+	return err
+}
+
+// expandAlias follows alias definitions the way git's handle_alias does until a git command or
+// a shell alias is reached.
+func expandAlias(defs map[string][]Alias, builtins map[string]bool, name string, args []string) (AliasExpansion, error) {
+	expansion := AliasExpansion{Alias: name, Args: args}
+	argv := append([]string{name}, args...)
+	seen := make(map[string]bool)
+
+	for {
+		command := argv[0]
+		list := defs[command]
+		if len(list) == 0 || builtins[command] {
+			if len(expansion.Chain) == 0 {
+				if len(list) > 0 {
+					return AliasExpansion{}, fmt.Errorf("alias %q shadows a git command and is ignored by git", name)
+				}
+				return AliasExpansion{}, fmt.Errorf("alias %q is not defined", name)
+			}
+			expansion.Argv = append([]string{"git"}, argv...)
+			return expansion, nil
+		}
+		if seen[command] {
+			return AliasExpansion{}, fmt.Errorf("alias loop detected: %s", strings.Join(append(expansion.Chain, command), " -> "))
+		}
+		seen[command] = true
+		expansion.Chain = append(expansion.Chain, command)
+
+		alias := list[len(list)-1]
+		rest := argv[1:]
+		if alias.Kind == AliasKindShell {
+			script := strings.TrimPrefix(alias.Command, "!")
+			expansion.Shell = true
+			if len(rest) == 0 {
+				expansion.Argv = []string{"sh", "-c", script}
+			} else {
+				expansion.Argv = append([]string{"sh", "-c", script + ` "$@"`, script}, rest...)
+			}
+			return expansion, nil
+		}
+
+		words, err := splitCmdline(alias.Command)
+		if err != nil {
+			return AliasExpansion{}, fmt.Errorf("bad alias.%s string: %w", command, err)
+		}
+		if len(words) == 0 {
+			return AliasExpansion{}, fmt.Errorf("empty alias for %s", command)
+		}
+		argv = append(words, rest...)
+	}
+}
+
+// ListAliases returns every alias definition visible to a repository, or the global ones when
+// repositoryID is GlobalRepositoryID, annotated with detected problems.
+func (s *Service) ListAliases(ctx context.Context, repositoryID string) ([]Alias, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	matrix, err := s.matrixFor(ctx, repositoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	aliases := analyzeAliases(collectAliases(matrix), knownGitCommands(), lookPathError)
+	for i := range aliases {
+		aliases[i].Command, _ = redactValue(aliases[i].Command)
+	}
+	return aliases, nil
+}
+
+// ExpandAlias shows the command git would run for `git <name> <args...>` without running it.
+func (s *Service) ExpandAlias(ctx context.Context, repositoryID, name string, args []string) (AliasExpansion, error) {
+	select {
+	case <-ctx.Done():
+		return AliasExpansion{}, ctx.Err()
+	default:
+	}
+
+	matrix, err := s.matrixFor(ctx, repositoryID)
+	if err != nil {
+		return AliasExpansion{}, err
+	}
+
+	expansion, err := expandAlias(collectAliases(matrix), knownGitCommands(), strings.ToLower(name), args)
+	if err != nil {
+		return AliasExpansion{}, err
+	}
+	for i := range expansion.Argv {
+		expansion.Argv[i], _ = redactValue(expansion.Argv[i])
+	}
+	return expansion, nil
+}
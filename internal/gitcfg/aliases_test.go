@@ -0,0 +1,130 @@
+package gitcfg
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSplitCmdline(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		input   string
+		want    []string
+		wantErr bool
+	}{
+		{input: "log --oneline  --graph", want: []string{"log", "--oneline", "--graph"}},
+		{input: `log --format='%h %s'`, want: []string{"log", "--format=%h %s"}},
+		{input: `commit -m "a \"quoted\" msg"`, want: []string{"commit", "-m", `a "quoted" msg`}},
+		{input: `grep a\ b`, want: []string{"grep", "a b"}},
+		{input: `log 'unterminated`, wantErr: true},
+		{input: `log \`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := splitCmdline(tt.input)
+		if tt.wantErr {
+			if err == nil {
+				t.Fatalf("splitCmdline(%q) expected error", tt.input)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("splitCmdline(%q) returned error: %v", tt.input, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Fatalf("splitCmdline(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func aliasTestMatrix() ConfigMatrix {
+	entries := []gitConfigEntry{
+		{key: "alias.co", value: "checkout", scope: ConfigScopeGlobal, order: 0},
+		{key: "alias.lg", value: "log --oneline", scope: ConfigScopeGlobal, order: 1},
+		{key: "alias.lg", value: "log --graph --oneline", scope: ConfigScopeLocal, order: 2},
+		{key: "alias.last", value: "lg -1", scope: ConfigScopeGlobal, order: 3},
+		{key: "alias.ping", value: "pong", scope: ConfigScopeGlobal, order: 4},
+		{key: "alias.pong", value: "ping", scope: ConfigScopeGlobal, order: 5},
+		{key: "alias.status", value: "status -sb", scope: ConfigScopeGlobal, order: 6},
+		{key: "alias.open", value: "!missing-browser-tool", scope: ConfigScopeGlobal, order: 7},
+		{key: "alias.root", value: "!cd \"$(git rev-parse --show-toplevel)\"", scope: ConfigScopeGlobal, order: 8},
+	}
+	return ConfigMatrix{Entries: buildConfigValues(entries)}
+}
+
+var aliasTestBuiltins = map[string]bool{"checkout": true, "log": true, "status": true}
+
+func TestAnalyzeAliases(t *testing.T) {
+	t.Parallel()
+
+	lookPath := func(binary string) error {
+		if binary == "missing-browser-tool" {
+			return errors.New("not found")
+		}
+		return nil
+	}
+	aliases := analyzeAliases(collectAliases(aliasTestMatrix()), aliasTestBuiltins, lookPath)
+
+	active := make(map[string]Alias)
+	overridden := 0
+	for _, alias := range aliases {
+		if alias.Overridden {
+			overridden++
+			continue
+		}
+		active[alias.Name] = alias
+	}
+
+	if overridden != 1 || active["lg"].Command != "log --graph --oneline" {
+		t.Fatalf("expected the local lg alias to override the global one, got %+v", aliases)
+	}
+	if !active["status"].ShadowsBuiltin || active["co"].ShadowsBuiltin {
+		t.Fatalf("expected only status to shadow a builtin, got %+v / %+v", active["status"], active["co"])
+	}
+	if active["last"].References != "lg" || active["last"].Cycle != nil {
+		t.Fatalf("expected last to reference lg without a cycle, got %+v", active["last"])
+	}
+	if want := []string{"ping", "pong", "ping"}; !reflect.DeepEqual(active["ping"].Cycle, want) {
+		t.Fatalf("expected cycle %v, got %+v", want, active["ping"])
+	}
+	if active["open"].Kind != AliasKindShell || active["open"].MissingBinary != "missing-browser-tool" {
+		t.Fatalf("expected missing binary for shell alias, got %+v", active["open"])
+	}
+	if active["root"].MissingBinary != "" {
+		t.Fatalf("expected shell builtins to be skipped, got %+v", active["root"])
+	}
+}
+
+func TestExpandAlias(t *testing.T) {
+	t.Parallel()
+
+	defs := collectAliases(aliasTestMatrix())
+
+	expansion, err := expandAlias(defs, aliasTestBuiltins, "last", []string{"--stat"})
+	if err != nil {
+		t.Fatalf("expandAlias returned error: %v", err)
+	}
+	if want := []string{"git", "log", "--graph", "--oneline", "-1", "--stat"}; !reflect.DeepEqual(expansion.Argv, want) {
+		t.Fatalf("expected argv %q, got %q", want, expansion.Argv)
+	}
+	if want := []string{"last", "lg"}; !reflect.DeepEqual(expansion.Chain, want) {
+		t.Fatalf("expected chain %q, got %q", want, expansion.Chain)
+	}
+
+	expansion, err = expandAlias(defs, aliasTestBuiltins, "open", []string{"docs"})
+	if err != nil {
+		t.Fatalf("expandAlias returned error: %v", err)
+	}
+	if want := []string{"sh", "-c", `missing-browser-tool "$@"`, "missing-browser-tool", "docs"}; !expansion.Shell || !reflect.DeepEqual(expansion.Argv, want) {
+		t.Fatalf("expected shell argv %q, got %+v", want, expansion)
+	}
+
+	if _, err := expandAlias(defs, aliasTestBuiltins, "ping", nil); err == nil {
+		t.Fatal("expected alias loop to be reported")
+	}
+	if _, err := expandAlias(defs, aliasTestBuiltins, "status", nil); err == nil {
+		t.Fatal("expected alias shadowing a builtin to be treated as undefined")
+	}
+}
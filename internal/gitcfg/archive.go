@@ -0,0 +1,951 @@
+package gitcfg
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ArchiveFormat selects the container of an exported archive.
+type ArchiveFormat string
+
+const (
+	ArchiveTarGz ArchiveFormat = "tar.gz"
+	ArchiveZip   ArchiveFormat = "zip"
+)
+
+// ArchiveFileKind tells where an archived config file came from.
+type ArchiveFileKind string
+
+const (
+	ArchiveFileSystem   ArchiveFileKind = "system"
+	ArchiveFileGlobal   ArchiveFileKind = "global"
+	ArchiveFileXDG      ArchiveFileKind = "xdg"
+	ArchiveFileInclude  ArchiveFileKind = "include"
+	ArchiveFileLocal    ArchiveFileKind = "local"
+	ArchiveFileWorktree ArchiveFileKind = "worktree"
+)
+
+// WriteOperationImport marks change sets produced by Service.ImportArchive.
+const WriteOperationImport WriteOperation = "import"
+
+const (
+	archiveManifestName = "manifest.json"
+	archiveVersion      = 1
+	// maxArchiveFileSize bounds each file read back from an archive.
+	maxArchiveFileSize = 16 << 20
+	// maxArchiveSize bounds the archive itself and everything read back from it together.
+	maxArchiveSize = 64 << 20
+	// maxArchiveFiles bounds the number of files a manifest may list.
+	maxArchiveFiles = 1024
+)
+
+// ArchiveFile describes one config file stored in an archive under Name.
+type ArchiveFile struct {
+	Name         string          `json:"name"`
+	OriginalPath string          `json:"originalPath"`
+	Kind         ArchiveFileKind `json:"kind"`
+	Scope        ConfigScope     `json:"scope"`
+	// RepositoryPath is the working directory of the repository local and worktree files
+	// belong to.
+	RepositoryPath string `json:"repositoryPath,omitempty"`
+	Size           int64  `json:"size"`
+	Hash           string `json:"hash"`
+}
+
+// ArchiveManifest is stored in every archive and lists its files and the application state.
+type ArchiveManifest struct {
+	Version   int           `json:"version"`
+	CreatedAt string        `json:"createdAt"`
+	User      string        `json:"user"`
+	Home      string        `json:"home"`
+	Files     []ArchiveFile `json:"files"`
+	Roots     []string      `json:"roots"`
+	Rules     []IncludeRule `json:"rules"`
+}
+
+// ExportRequest selects what to export and where to write the archive.
+type ExportRequest struct {
+	Path          string        `json:"path"`
+	Format        ArchiveFormat `json:"format"`
+	IncludeSystem bool          `json:"includeSystem,omitempty"`
+}
+
+// PathMapping replaces the prefix From with To. Mappings apply to file locations and to the
+// paths inside config files, roots and rules.
+type PathMapping struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// ImportRequest describes how to restore an archive. Unless a mapping for it is given, the
+// home directory recorded in the archive is mapped to the current one.
+type ImportRequest struct {
+	Path          string        `json:"path"`
+	Remap         []PathMapping `json:"remap,omitempty"`
+	IncludeSystem bool          `json:"includeSystem,omitempty"`
+	// RestoreState adds the archived roots and rules to the current ones.
+	RestoreState bool `json:"restoreState,omitempty"`
+}
+
+// ImportAction is what importing does with one archived file.
+type ImportAction string
+
+const (
+	ImportCreate    ImportAction = "create"
+	ImportUpdate    ImportAction = "update"
+	ImportUnchanged ImportAction = "unchanged"
+	ImportSkip      ImportAction = "skip"
+)
+
+// ImportItem is the planned destination of one archived file.
+type ImportItem struct {
+	File       ArchiveFile  `json:"file"`
+	TargetPath string       `json:"targetPath"`
+	Action     ImportAction `json:"action"`
+	Reason     string       `json:"reason,omitempty"`
+	Diff       string       `json:"diff,omitempty"`
+}
+
+// ImportPlan previews an import: what will be written where, and the remapped state.
+type ImportPlan struct {
+	Manifest ArchiveManifest `json:"manifest"`
+	Remap    []PathMapping   `json:"remap"`
+	Items    []ImportItem    `json:"items"`
+	Roots    []string        `json:"roots,omitempty"`
+	Rules    []IncludeRule   `json:"rules,omitempty"`
+	// ReplacedRules holds the stored rules that restored rules with the same ID overwrite.
+	ReplacedRules []IncludeRule `json:"replacedRules,omitempty"`
+}
+
+// ImportResult describes an applied import. Its change sets share BatchID, so it can be
+// undone as one step.
+type ImportResult struct {
+	Plan       ImportPlan  `json:"plan"`
+	BatchID    string      `json:"batchId"`
+	ChangeSets []ChangeSet `json:"changeSets"`
+	// AuditWarnings lists imported rules that were stored but could not be audited.
+	AuditWarnings []string `json:"auditWarnings,omitempty"`
+}
+
+// archiveFileKind classifies a node of an include graph.
+func archiveFileKind(node IncludeNode) ArchiveFileKind {
+	switch {
+	case !node.Root:
+		return ArchiveFileInclude
+	case node.Scope == ConfigScopeSystem:
+		return ArchiveFileSystem
+	case node.Scope == ConfigScopeLocal:
+		return ArchiveFileLocal
+	case node.Scope == ConfigScopeWorktree:
+		return ArchiveFileWorktree
+	case samePath(node.File, xdgConfigPath()):
+		return ArchiveFileXDG
+	default:
+		return ArchiveFileGlobal
+	}
+}
+
+// exportFiles collects the config files of the user, of every tracked repository and of
+// everything they include, active or not, listing each file once.
+func (s *Service) exportFiles(ctx context.Context, includeSystem bool) ([]ArchiveFile, error) {
+	s.mu.RLock()
+	ids := []string{GlobalRepositoryID}
+	repoPaths := map[string]string{}
+	for id, repo := range s.repositories {
+		ids = append(ids, id)
+		repoPaths[id] = repo.Path
+	}
+	s.mu.RUnlock()
+	sort.Strings(ids[1:])
+
+	var files []ArchiveFile
+	seen := make(map[string]bool)
+	for _, id := range ids {
+		graph, _, err := s.includeGraph(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		for _, node := range graph.Nodes {
+			file := filepath.Clean(node.File)
+			kind := archiveFileKind(node)
+			if !node.Exists || seen[file] || (kind == ArchiveFileSystem && !includeSystem) {
+				continue
+			}
+			seen[file] = true
+			entry := ArchiveFile{OriginalPath: file, Kind: kind, Scope: node.Scope}
+			if kind == ArchiveFileLocal || kind == ArchiveFileWorktree {
+				entry.RepositoryPath = repoPaths[id]
+			}
+			files = append(files, entry)
+		}
+	}
+	return files, nil
+}
+
+// ExportArchive writes every relevant config file and the roots and rules of the application
+// into one archive, together with a manifest.
+func (s *Service) ExportArchive(ctx context.Context, req ExportRequest) (ArchiveManifest, error) {
+	select {
+	case <-ctx.Done():
+		return ArchiveManifest{}, ctx.Err()
+	default:
+	}
+
+	if req.Format == "" {
+		req.Format = ArchiveTarGz
+	}
+	if req.Format != ArchiveTarGz && req.Format != ArchiveZip {
+		return ArchiveManifest{}, fmt.Errorf("unsupported archive format %q", req.Format)
+	}
+	if strings.TrimSpace(req.Path) == "" {
+		return ArchiveManifest{}, errors.New("path cannot be empty")
+	}
+	dest, err := expandPath(req.Path)
+	if err != nil {
+		return ArchiveManifest{}, err
+	}
+
+	files, err := s.exportFiles(ctx, req.IncludeSystem)
+	if err != nil {
+		return ArchiveManifest{}, err
+	}
+	rules, err := s.ListRules(ctx)
+	if err != nil {
+		return ArchiveManifest{}, err
+	}
+	home, _ := os.UserHomeDir()
+	manifest := ArchiveManifest{
+		Version:   archiveVersion,
+		CreatedAt: timestamp(time.Now()),
+		User:      currentUser(),
+		Home:      home,
+		Files:     []ArchiveFile{},
+		Roots:     s.ListRoots(),
+		Rules:     rules,
+	}
+
+	contents := make(map[string][]byte)
+	for i, file := range files {
+		data, err := os.ReadFile(file.OriginalPath)
+		if err != nil {
+			return ArchiveManifest{}, fmt.Errorf("read %s: %w", file.OriginalPath, err)
+		}
+		file.Name = fmt.Sprintf("files/%03d-%s", i+1, filepath.Base(file.OriginalPath))
+		file.Size = int64(len(data))
+		file.Hash = contentHash(data)
+		contents[file.Name] = data
+		manifest.Files = append(manifest.Files, file)
+	}
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return ArchiveManifest{}, fmt.Errorf("encode manifest: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if req.Format == ArchiveZip {
+		err = writeZipArchive(&buf, manifestData, manifest.Files, contents)
+	} else {
+		err = writeTarArchive(&buf, manifestData, manifest.Files, contents)
+	}
+	if err != nil {
+		return ArchiveManifest{}, err
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return ArchiveManifest{}, fmt.Errorf("create directory for %s: %w", dest, err)
+	}
+	// Config files may hold credentials, so the archive is private to the user.
+	if err := os.WriteFile(dest, buf.Bytes(), 0o600); err != nil {
+		return ArchiveManifest{}, fmt.Errorf("write archive: %w", err)
+	}
+	return manifest, nil
+}
+
+func writeTarArchive(w io.Writer, manifest []byte, files []ArchiveFile, contents map[string][]byte) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	add := func(name string, data []byte) error {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o600, Size: int64(len(data)), ModTime: time.Now()}); err != nil {
+			return fmt.Errorf("write archive: %w", err)
+		}
+		if _, err := tw.Write(data); err != nil {
+			return fmt.Errorf("write archive: %w", err)
+		}
+		return nil
+	}
+	if err := add(archiveManifestName, manifest); err != nil {
+		return err
+	}
+	for _, file := range files {
+		if err := add(file.Name, contents[file.Name]); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("write archive: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("write archive: %w", err)
+	}
+	return nil
+}
+
+func writeZipArchive(w io.Writer, manifest []byte, files []ArchiveFile, contents map[string][]byte) error {
+	zw := zip.NewWriter(w)
+	add := func(name string, data []byte) error {
+		fw, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: time.Now()})
+		if err != nil {
+			return fmt.Errorf("write archive: %w", err)
+		}
+		if _, err := fw.Write(data); err != nil {
+			return fmt.Errorf("write archive: %w", err)
+		}
+		return nil
+	}
+	if err := add(archiveManifestName, manifest); err != nil {
+		return err
+	}
+	for _, file := range files {
+		if err := add(file.Name, contents[file.Name]); err != nil {
+			return err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("write archive: %w", err)
+	}
+	return nil
+}
+
+// readArchive returns the manifest of an archive and the verified contents of its files,
+// keyed by name. The format is detected from the data.
+func readArchive(path string) (ArchiveManifest, map[string][]byte, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return ArchiveManifest{}, nil, fmt.Errorf("read archive: %w", err)
+	}
+	if info.Size() > maxArchiveSize {
+		return ArchiveManifest{}, nil, fmt.Errorf("archive %s is too large", path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ArchiveManifest{}, nil, fmt.Errorf("read archive: %w", err)
+	}
+
+	// walk calls visit with every regular file of the archive until visit returns false.
+	var walk func(visit func(name string, r io.Reader) (bool, error)) error
+	switch {
+	case bytes.HasPrefix(data, []byte("PK\x03\x04")):
+		zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return ArchiveManifest{}, nil, fmt.Errorf("read archive: %w", err)
+		}
+		walk = func(visit func(string, io.Reader) (bool, error)) error {
+			for _, f := range zr.File {
+				if !f.Mode().IsRegular() {
+					continue
+				}
+				rc, err := f.Open()
+				if err != nil {
+					return fmt.Errorf("read archive entry %s: %w", f.Name, err)
+				}
+				more, err := visit(f.Name, rc)
+				rc.Close()
+				if err != nil || !more {
+					return err
+				}
+			}
+			return nil
+		}
+	case bytes.HasPrefix(data, []byte{0x1f, 0x8b}):
+		walk = func(visit func(string, io.Reader) (bool, error)) error {
+			gz, err := gzip.NewReader(bytes.NewReader(data))
+			if err != nil {
+				return fmt.Errorf("read archive: %w", err)
+			}
+			tr := tar.NewReader(gz)
+			for {
+				header, err := tr.Next()
+				if errors.Is(err, io.EOF) {
+					return nil
+				}
+				if err != nil {
+					return fmt.Errorf("read archive: %w", err)
+				}
+				if header.Typeflag != tar.TypeReg {
+					continue
+				}
+				if more, err := visit(header.Name, tr); err != nil || !more {
+					return err
+				}
+			}
+		}
+	default:
+		return ArchiveManifest{}, nil, fmt.Errorf("%s is neither a tar.gz nor a zip archive", path)
+	}
+
+	var total int64
+	readEntry := func(name string, r io.Reader) ([]byte, error) {
+		content, err := io.ReadAll(io.LimitReader(r, maxArchiveFileSize+1))
+		if err != nil {
+			return nil, fmt.Errorf("read archive entry %s: %w", name, err)
+		}
+		if len(content) > maxArchiveFileSize {
+			return nil, fmt.Errorf("archive entry %s is too large", name)
+		}
+		if total += int64(len(content)); total > maxArchiveSize {
+			return nil, errors.New("archive contents are too large")
+		}
+		return content, nil
+	}
+
+	var raw []byte
+	err = walk(func(name string, r io.Reader) (bool, error) {
+		if name != archiveManifestName {
+			return true, nil
+		}
+		raw, err = readEntry(name, r)
+		return false, err
+	})
+	if err != nil {
+		return ArchiveManifest{}, nil, err
+	}
+	if raw == nil {
+		return ArchiveManifest{}, nil, errors.New("archive has no manifest")
+	}
+	var manifest ArchiveManifest
+	if err := json.Unmarshal(raw, &manifest); err != nil {
+		return ArchiveManifest{}, nil, fmt.Errorf("decode manifest: %w", err)
+	}
+	if manifest.Version != archiveVersion {
+		return ArchiveManifest{}, nil, fmt.Errorf("unsupported archive version %d", manifest.Version)
+	}
+	if len(manifest.Files) > maxArchiveFiles {
+		return ArchiveManifest{}, nil, fmt.Errorf("archive lists %d files, more than the %d supported", len(manifest.Files), maxArchiveFiles)
+	}
+
+	// Only the files the manifest names are read; anything else in the archive is ignored.
+	entries := make(map[string][]byte, len(manifest.Files))
+	wanted := make(map[string]bool, len(manifest.Files))
+	for _, file := range manifest.Files {
+		wanted[file.Name] = true
+	}
+	err = walk(func(name string, r io.Reader) (bool, error) {
+		if !wanted[name] {
+			return true, nil
+		}
+		if _, ok := entries[name]; ok {
+			return false, fmt.Errorf("archive holds %s more than once", name)
+		}
+		content, err := readEntry(name, r)
+		if err != nil {
+			return false, err
+		}
+		entries[name] = content
+		return true, nil
+	})
+	if err != nil {
+		return ArchiveManifest{}, nil, err
+	}
+	for _, file := range manifest.Files {
+		content, ok := entries[file.Name]
+		if !ok {
+			return ArchiveManifest{}, nil, fmt.Errorf("archive is missing %s", file.Name)
+		}
+		if contentHash(content) != file.Hash {
+			return ArchiveManifest{}, nil, fmt.Errorf("archive entry %s does not match its checksum", file.Name)
+		}
+	}
+	return manifest, entries, nil
+}
+
+// remapPath applies the first mapping whose From is a path prefix of path.
+func remapPath(path string, mappings []PathMapping) string {
+	for _, m := range mappings {
+		if path == m.From {
+			return m.To
+		}
+		sep := string(filepath.Separator)
+		if rest, ok := strings.CutPrefix(path, strings.TrimSuffix(m.From, sep)+sep); ok {
+			return filepath.Join(m.To, rest)
+		}
+	}
+	return path
+}
+
+// pathBoundary lists the characters that may follow a mapped prefix in config text: a
+// separator, or whatever ends the value.
+const pathBoundary = "/\\\" \t\r\n#;"
+
+// remapPrefix applies the first mapping whose From starts text and is followed by the end of
+// text or by a path boundary, so that /home/al does not rewrite /home/alice.
+func remapPrefix(text string, mappings []PathMapping) (string, bool) {
+	for _, m := range mappings {
+		rest, ok := strings.CutPrefix(text, m.From)
+		if ok && (rest == "" || strings.ContainsRune(pathBoundary, rune(rest[0]))) {
+			return m.To + rest, true
+		}
+	}
+	return text, false
+}
+
+// remapCondition remaps the pattern of a gitdir: or gitdir/i: includeIf condition.
+func remapCondition(condition string, mappings []PathMapping) (string, bool) {
+	for _, prefix := range []string{"gitdir:", "gitdir/i:"} {
+		if pattern, ok := strings.CutPrefix(condition, prefix); ok {
+			remapped, ok := remapPrefix(pattern, mappings)
+			return prefix + remapped, ok
+		}
+	}
+	return condition, false
+}
+
+var configQuoter = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// remapContent remaps the paths in the text of a config file: the values of path-typed
+// variables such as include.path or core.excludesFile, and the gitdir: conditions of includeIf
+// sections. Other values, URLs and aliases among them, are left alone, as is the layout.
+func remapContent(text string, mappings []PathMapping) string {
+	// Values are compared and written in their escaped spelling.
+	quoted := make([]PathMapping, 0, len(mappings))
+	for _, m := range mappings {
+		quoted = append(quoted, PathMapping{From: configQuoter.Replace(m.From), To: configQuoter.Replace(m.To)})
+	}
+
+	lines := strings.SplitAfter(text, "\n")
+	var section, subsection string
+	continued := false
+	for i, line := range lines {
+		wasContinued := continued
+		continued = strings.HasSuffix(strings.TrimRight(line, "\r\n"), `\`)
+		if wasContinued {
+			continue
+		}
+
+		body := strings.TrimLeft(line, " \t")
+		indent := line[:len(line)-len(body)]
+		if strings.HasPrefix(body, "[") {
+			end := strings.IndexByte(body, ']')
+			if end < 0 {
+				continue
+			}
+			header := body[1:end]
+			var err error
+			if section, subsection, err = parseSectionHeader(header); err != nil {
+				section = ""
+				continue
+			}
+			if section != "includeif" {
+				continue
+			}
+			if condition, ok := remapCondition(subsection, mappings); ok {
+				name, _, _ := strings.Cut(header, `"`)
+				lines[i] = indent + "[" + name + `"` + configQuoter.Replace(condition) + `"]` + body[end+1:]
+			}
+			continue
+		}
+
+		nameEnd := strings.IndexFunc(body, func(r rune) bool {
+			return !(r == '-' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z')
+		})
+		if section == "" || nameEnd <= 0 {
+			continue
+		}
+		key := ConfigKey{Section: section, Subsection: subsection, Name: strings.ToLower(body[:nameEnd])}
+		if ValueTypeForKey(key.String()) != ValueTypePath {
+			continue
+		}
+		value, ok := strings.CutPrefix(strings.TrimLeft(body[nameEnd:], " \t"), "=")
+		if !ok {
+			continue
+		}
+		value = strings.TrimLeft(value, " \t")
+		quote := ""
+		if strings.HasPrefix(value, `"`) {
+			quote, value = `"`, value[1:]
+		}
+		if remapped, ok := remapPrefix(value, quoted); ok {
+			lines[i] = line[:len(line)-len(value)-len(quote)] + quote + remapped
+		}
+	}
+	return strings.Join(lines, "")
+}
+
+// importMappings completes the mappings of req, longest prefix first.
+func importMappings(req ImportRequest, manifest ArchiveManifest) []PathMapping {
+	var mappings []PathMapping
+	for _, m := range req.Remap {
+		if m.From != "" && m.To != "" {
+			mappings = append(mappings, PathMapping{From: filepath.Clean(m.From), To: filepath.Clean(m.To)})
+		}
+	}
+	if home, err := os.UserHomeDir(); err == nil && manifest.Home != "" && manifest.Home != home {
+		covered := false
+		for _, m := range mappings {
+			if m.From == filepath.Clean(manifest.Home) {
+				covered = true
+			}
+		}
+		if !covered {
+			mappings = append(mappings, PathMapping{From: filepath.Clean(manifest.Home), To: home})
+		}
+	}
+	sort.SliceStable(mappings, func(i, j int) bool {
+		return len(mappings[i].From) > len(mappings[j].From)
+	})
+	return mappings
+}
+
+// importTargetProblem explains why an archived file of a top-level kind may not be restored
+// to target, or returns "" if it may: each kind only goes where git reads such a file.
+// Include files are checked by planImport against the files that reference them.
+func importTargetProblem(ctx context.Context, file ArchiveFile, target string, mappings []PathMapping) string {
+	var allowed []string
+	switch file.Kind {
+	case ArchiveFileSystem:
+		allowed = []string{systemConfigPath()}
+	case ArchiveFileGlobal:
+		if home, err := os.UserHomeDir(); err == nil {
+			allowed = append(allowed, filepath.Join(home, ".gitconfig"))
+		}
+		allowed = append(allowed, os.Getenv("GIT_CONFIG_GLOBAL"), xdgConfigPath())
+	case ArchiveFileXDG:
+		allowed = []string{xdgConfigPath()}
+	case ArchiveFileLocal, ArchiveFileWorktree:
+		repoPath := remapPath(file.RepositoryPath, mappings)
+		if !filepath.IsAbs(repoPath) {
+			return "the archive names no absolute repository path for this file"
+		}
+		if !dirExists(repoPath) {
+			return "the repository does not exist at " + repoPath + "; clone it first"
+		}
+		name := "config"
+		if file.Kind == ArchiveFileWorktree {
+			name = "config.worktree"
+		}
+		expected, err := repositoryConfigPath(ctx, Repository{Name: repoPath, Path: repoPath}, name)
+		if err != nil {
+			return "the repository does not exist at " + repoPath + "; clone it first"
+		}
+		allowed = []string{expected}
+	default:
+		return fmt.Sprintf("unknown file kind %q", file.Kind)
+	}
+	for _, path := range allowed {
+		if samePath(target, path) {
+			return ""
+		}
+	}
+	return fmt.Sprintf("%s is not where git reads the %s config file", target, file.Kind)
+}
+
+// includeTargets returns the files the include directives of a config file point at.
+func includeTargets(content []byte, from string) []string {
+	entries, err := parseConfigFile(content)
+	if err != nil {
+		return nil
+	}
+	var targets []string
+	for _, entry := range entries {
+		if _, _, ok := includeDirective(entry.key); !ok || entry.noValue {
+			continue
+		}
+		if target, err := resolveIncludePath(entry.value, from); err == nil {
+			targets = append(targets, target)
+		}
+	}
+	return targets
+}
+
+// planImport decides where each archived file goes and what writing it changes. Targets come
+// from the manifest, so a file is only restored where git reads a file of its kind; an include
+// file only when a restored file includes it.
+func planImport(ctx context.Context, req ImportRequest, manifest ArchiveManifest, entries map[string][]byte, rules map[string]IncludeRule) (ImportPlan, map[string][]byte, error) {
+	mappings := importMappings(req, manifest)
+	plan := ImportPlan{Manifest: manifest, Remap: append([]PathMapping{}, mappings...), Items: []ImportItem{}}
+
+	items := make([]ImportItem, len(manifest.Files))
+	afters := make([][]byte, len(manifest.Files))
+	accepted := make([]bool, len(manifest.Files))
+	referenced := make(map[string]bool)
+	accept := func(i int) {
+		accepted[i] = true
+		for _, target := range includeTargets(afters[i], items[i].TargetPath) {
+			referenced[target] = true
+		}
+	}
+	for i, file := range manifest.Files {
+		items[i] = ImportItem{File: file, TargetPath: filepath.Clean(remapPath(file.OriginalPath, mappings))}
+		afters[i] = []byte(remapContent(string(entries[file.Name]), mappings))
+		switch {
+		case !filepath.IsAbs(file.OriginalPath) || !filepath.IsAbs(items[i].TargetPath):
+			items[i].Action, items[i].Reason = ImportSkip, "the archive names no absolute path for this file"
+		case file.Kind == ArchiveFileSystem && !req.IncludeSystem:
+			items[i].Action, items[i].Reason = ImportSkip, "system files are only restored on request"
+		case file.Kind == ArchiveFileInclude:
+			items[i].Action, items[i].Reason = ImportSkip, "no restored file includes it"
+		default:
+			if reason := importTargetProblem(ctx, file, items[i].TargetPath, mappings); reason != "" {
+				items[i].Action, items[i].Reason = ImportSkip, reason
+			} else {
+				accept(i)
+			}
+		}
+	}
+	// Includes may be nested, so include files are accepted until none is left that an
+	// accepted file references.
+	for changed := true; changed; {
+		changed = false
+		for i, file := range manifest.Files {
+			if file.Kind == ArchiveFileInclude && !accepted[i] && filepath.IsAbs(file.OriginalPath) && referenced[items[i].TargetPath] {
+				accept(i)
+				changed = true
+			}
+		}
+	}
+
+	contents := make(map[string][]byte)
+	seen := make(map[string]bool)
+	for i := range manifest.Files {
+		item, after := items[i], afters[i]
+		if !accepted[i] {
+			plan.Items = append(plan.Items, item)
+			continue
+		}
+		item.Reason = ""
+
+		current, err := os.ReadFile(item.TargetPath)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return ImportPlan{}, nil, fmt.Errorf("read %s: %w", item.TargetPath, err)
+		}
+		exists := err == nil
+
+		switch {
+		case seen[item.TargetPath]:
+			item.Action, item.Reason = ImportSkip, "another archived file is restored to the same path"
+		case exists && bytes.Equal(current, after):
+			item.Action = ImportUnchanged
+		case exists:
+			item.Action = ImportUpdate
+		default:
+			item.Action = ImportCreate
+		}
+		if item.Action == ImportCreate || item.Action == ImportUpdate {
+			seen[item.TargetPath] = true
+			item.Diff = redactDiff(unifiedDiff(item.TargetPath, string(current), string(after)))
+			contents[item.TargetPath] = after
+		}
+		plan.Items = append(plan.Items, item)
+	}
+	if req.RestoreState {
+		// Roots are checked here, before any file is written, as ImportArchive adds them after.
+		for _, root := range manifest.Roots {
+			if root = remapPath(root, mappings); filepath.IsAbs(root) {
+				plan.Roots = append(plan.Roots, root)
+			}
+		}
+		for _, rule := range manifest.Rules {
+			rule.Pattern, _ = remapCondition(rule.Pattern, mappings)
+			rule.TargetPath = remapPath(rule.TargetPath, mappings)
+			plan.Rules = append(plan.Rules, rule)
+			if previous, ok := rules[rule.ID]; ok {
+				plan.ReplacedRules = append(plan.ReplacedRules, previous)
+			}
+		}
+		if err := planRuleDirectives(ctx, &plan, contents, rules); err != nil {
+			return ImportPlan{}, nil, err
+		}
+	}
+	return plan, contents, nil
+}
+
+// planRuleDirectives adds the includeIf directives of the restored rules to the global config
+// file, on top of its archived contents when that file is restored as well. current holds the
+// rules the restored ones replace.
+func planRuleDirectives(ctx context.Context, plan *ImportPlan, contents map[string][]byte, current map[string]IncludeRule) error {
+	path, err := globalConfigPath()
+	if err != nil {
+		return err
+	}
+	path = filepath.Clean(path)
+	existing, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("read %s: %w", path, err)
+	}
+	exists := err == nil
+	base, restored := contents[path]
+	if !restored {
+		base = existing
+	}
+
+	present := ruleDirectives(base)
+	var ops [][]string
+	for _, rule := range plan.Rules {
+		previous, ok := current[rule.ID]
+		ops = append(ops, ruleOps(ruleState(previous, ok), &rule, present)...)
+	}
+	if len(ops) == 0 {
+		return nil
+	}
+	after, err := editContent(ctx, base, ops)
+	if err != nil {
+		return err
+	}
+	contents[path] = after
+
+	item := ImportItem{
+		File:       ArchiveFile{Kind: ArchiveFileGlobal, Scope: ConfigScopeGlobal},
+		TargetPath: path,
+		Reason:     "adds the includeIf directives of the restored rules",
+	}
+	index := slices.IndexFunc(plan.Items, func(item ImportItem) bool {
+		return item.TargetPath == path && item.Action != ImportSkip
+	})
+	if index >= 0 {
+		item = plan.Items[index]
+	}
+	item.Action = ImportCreate
+	if exists {
+		item.Action = ImportUpdate
+	}
+	item.Diff = redactDiff(unifiedDiff(path, string(existing), string(after)))
+	if index < 0 {
+		plan.Items = append(plan.Items, item)
+	} else {
+		plan.Items[index] = item
+	}
+	return nil
+}
+
+func dirExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// PreviewImport reads an archive and reports what importing it would write where, without
+// touching any file.
+func (s *Service) PreviewImport(ctx context.Context, req ImportRequest) (ImportPlan, error) {
+	select {
+	case <-ctx.Done():
+		return ImportPlan{}, ctx.Err()
+	default:
+	}
+
+	path, err := expandPath(req.Path)
+	if err != nil {
+		return ImportPlan{}, err
+	}
+	manifest, entries, err := readArchive(path)
+	if err != nil {
+		return ImportPlan{}, err
+	}
+	plan, _, err := planImport(ctx, req, manifest, entries, s.rulesByID())
+	return plan, err
+}
+
+// ImportArchive restores the files of an archive as one batch: every target is locked first,
+// and if writing any of them or adding a root fails the files already written are restored.
+// With RestoreState the archived roots and rules are added as well.
+func (s *Service) ImportArchive(ctx context.Context, req ImportRequest) (ImportResult, error) {
+	select {
+	case <-ctx.Done():
+		return ImportResult{}, ctx.Err()
+	default:
+	}
+
+	path, err := expandPath(req.Path)
+	if err != nil {
+		return ImportResult{}, err
+	}
+	manifest, entries, err := readArchive(path)
+	if err != nil {
+		return ImportResult{}, err
+	}
+
+	// The files to write are locked and the plan is made again, so that they cannot change
+	// between planning and writing.
+	_, contents, err := planImport(ctx, req, manifest, entries, s.rulesByID())
+	if err != nil {
+		return ImportResult{}, err
+	}
+	targets := make([]string, 0, len(contents))
+	for target := range contents {
+		targets = append(targets, target)
+	}
+	sort.Strings(targets)
+	locks := make(map[string]*fileLock, len(targets))
+	for _, target := range targets {
+		lock, err := lockFile(target)
+		if err != nil {
+			return ImportResult{}, err
+		}
+		defer lock.release()
+		locks[target] = lock
+	}
+	plan, contents, err := planImport(ctx, req, manifest, entries, s.rulesByID())
+	if err != nil {
+		return ImportResult{}, err
+	}
+	for target := range contents {
+		if locks[target] == nil {
+			return ImportResult{}, fmt.Errorf("%s appeared while importing; preview the import again", target)
+		}
+	}
+
+	result := ImportResult{Plan: plan, BatchID: uuid.NewString()}
+	var changeSets []ChangeSet
+	var changes []fileChange
+	for _, item := range plan.Items {
+		if item.Action != ImportCreate && item.Action != ImportUpdate {
+			continue
+		}
+		current, _ := os.ReadFile(item.TargetPath)
+		changes = append(changes, fileChange{path: item.TargetPath, before: current, after: contents[item.TargetPath], existed: item.Action == ImportUpdate, lock: locks[item.TargetPath]})
+		changeSets = append(changeSets, ChangeSet{Scope: item.File.Scope, Operation: WriteOperationImport, BatchID: result.BatchID})
+	}
+	if result.ChangeSets, err = s.recordBatch(changeSets, changes); err != nil {
+		return ImportResult{}, err
+	}
+
+	// Roots and rules join the undo step of the files; planImport has checked the roots.
+	var added []string
+	var ruleEdits []editRule
+	s.mu.Lock()
+	for _, root := range plan.Roots {
+		root = filepath.Clean(root)
+		if _, had := s.roots[root]; !had {
+			s.roots[root] = struct{}{}
+			added = append(added, root)
+		}
+	}
+	for _, rule := range plan.Rules {
+		previous, existed := s.includeRules[rule.ID]
+		rule.LastUpdated = timestamp(time.Now())
+		s.includeRules[rule.ID] = rule
+		ruleEdits = append(ruleEdits, editRule{id: rule.ID, before: ruleState(previous, existed), after: ruleState(rule, true)})
+	}
+	if len(ruleEdits) > 0 || len(added) > 0 {
+		s.pushEditStepLocked(editStep{label: "import archive", batchID: result.BatchID, rules: ruleEdits, roots: added})
+	}
+	s.mu.Unlock()
+
+	var errs []error
+	for _, edit := range ruleEdits {
+		errs = append(errs, s.auditRule(AuditRuleUpsert, *edit.after))
+	}
+	result.AuditWarnings = auditWarnings(errs)
+	return result, nil
+}
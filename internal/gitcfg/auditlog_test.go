@@ -0,0 +1,189 @@
+package gitcfg
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAuditLogRecordsChanges(t *testing.T) {
+	s := newTestService(t)
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "profile.gitconfig")
+	t.Setenv("GIT_CONFIG_GLOBAL", filepath.Join(t.TempDir(), "gitconfig"))
+
+	first, err := s.WriteConfig(ctx, WriteRequest{TargetPath: path, Key: "user.name", Value: "Alice"})
+	if err != nil {
+		t.Fatalf("WriteConfig returned error: %v", err)
+	}
+	second, err := s.WriteConfig(ctx, WriteRequest{TargetPath: path, Key: "user.email", Value: "alice@example.com"})
+	if err != nil {
+		t.Fatalf("WriteConfig returned error: %v", err)
+	}
+	if _, err := s.Rollback(ctx, first.ID); err == nil || !strings.Contains(err.Error(), "has changed") {
+		t.Fatalf("expected rolling back a change that was edited since to be refused, got %v", err)
+	}
+	if _, err := s.Rollback(ctx, second.ID); err != nil {
+		t.Fatalf("Rollback returned error: %v", err)
+	}
+	rule, err := s.UpsertRule(ctx, IncludeRule{Pattern: "~/work/", TargetPath: "~/.gitconfig-work"})
+	if err != nil {
+		t.Fatalf("UpsertRule returned error: %v", err)
+	}
+	if err := s.DeleteRule(ctx, rule.ID); err != nil {
+		t.Fatalf("DeleteRule returned error: %v", err)
+	}
+
+	entries, err := s.QueryAuditLog(ctx, AuditQuery{})
+	if err != nil {
+		t.Fatalf("QueryAuditLog returned error: %v", err)
+	}
+	var operations []string
+	for _, entry := range entries {
+		operations = append(operations, entry.Operation)
+	}
+	want := "set,set,rollback,rule-upsert,rule-delete"
+	if got := strings.Join(operations, ","); got != want {
+		t.Fatalf("expected operations %s, got %s", want, got)
+	}
+
+	created := entries[0]
+	if created.BeforeHash != "" || created.AfterHash == "" || created.ChangeSetID != first.ID || created.User == "" {
+		t.Fatalf("unexpected entry for the first write: %+v", created)
+	}
+	if !strings.Contains(created.Diff, "+\tname = Alice") {
+		t.Fatalf("expected the diff to be recorded, got %q", created.Diff)
+	}
+	if entries[1].BeforeHash != created.AfterHash {
+		t.Fatal("expected the second write to start from the contents of the first")
+	}
+	if entries[1].PrevHash != created.Hash || created.PrevHash != "" {
+		t.Fatal("expected the entries to be chained")
+	}
+
+	byKey, err := s.QueryAuditLog(ctx, AuditQuery{File: path, Key: "User.Email"})
+	if err != nil {
+		t.Fatalf("QueryAuditLog returned error: %v", err)
+	}
+	if len(byKey) != 2 || byKey[0].Key != "user.email" || byKey[1].Operation != string(WriteOperationRollback) {
+		t.Fatalf("expected the write and the rollback of user.email, got %+v", byKey)
+	}
+	future := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	if later, err := s.QueryAuditLog(ctx, AuditQuery{Since: future}); err != nil || len(later) != 0 {
+		t.Fatalf("expected no entries after %s, got %d (%v)", future, len(later), err)
+	}
+	if last, err := s.QueryAuditLog(ctx, AuditQuery{Limit: 2}); err != nil || len(last) != 2 || last[1].Operation != AuditRuleDelete {
+		t.Fatalf("expected the two newest entries, got %+v (%v)", last, err)
+	}
+
+	verification, err := s.VerifyAuditLog(ctx)
+	if err != nil {
+		t.Fatalf("VerifyAuditLog returned error: %v", err)
+	}
+	if !verification.Valid || verification.Entries != 5 {
+		t.Fatalf("expected a valid chain of 5 entries, got %+v", verification)
+	}
+}
+
+func TestVerifyAuditLogDetectsTampering(t *testing.T) {
+	s := newTestService(t)
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "profile.gitconfig")
+
+	for _, name := range []string{"Alice", "Bob", "Carol"} {
+		if _, err := s.WriteConfig(ctx, WriteRequest{TargetPath: path, Key: "user.name", Value: name}); err != nil {
+			t.Fatalf("WriteConfig returned error: %v", err)
+		}
+	}
+
+	logPath := filepath.Join(s.dataDir, auditFileName)
+	data, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("read audit log: %v", err)
+	}
+	lines := strings.SplitAfter(strings.TrimSuffix(string(data), "\n"), "\n")
+
+	tests := []struct {
+		name     string
+		lines    []string
+		brokenAt int64
+	}{
+		{name: "edited", lines: []string{lines[0], strings.Replace(lines[1], "Bob", "Eve", 1), lines[2]}, brokenAt: 2},
+		{name: "removed", lines: []string{lines[0], lines[2]}, brokenAt: 2},
+		{name: "reordered", lines: []string{lines[1], lines[0], lines[2]}, brokenAt: 1},
+	}
+	for _, tt := range tests {
+		if err := os.WriteFile(logPath, []byte(strings.Join(tt.lines, "")), 0o600); err != nil {
+			t.Fatalf("%s: write audit log: %v", tt.name, err)
+		}
+		verification, err := s.VerifyAuditLog(ctx)
+		if err != nil {
+			t.Fatalf("%s: VerifyAuditLog returned error: %v", tt.name, err)
+		}
+		if verification.Valid || verification.BrokenAt != tt.brokenAt {
+			t.Fatalf("%s: expected the chain to break at entry %d, got %+v", tt.name, tt.brokenAt, verification)
+		}
+	}
+}
+
+func TestAuditFailureIsAWarning(t *testing.T) {
+	s := newTestService(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.gitconfig")
+	second := filepath.Join(dir, "second.gitconfig")
+
+	if _, err := s.WriteConfig(ctx, WriteRequest{TargetPath: first, Key: "user.name", Value: "Alice"}); err != nil {
+		t.Fatalf("WriteConfig returned error: %v", err)
+	}
+	if verification, err := s.VerifyAuditLog(ctx); err != nil || !verification.Valid || verification.Entries != 1 {
+		t.Fatalf("expected a valid log of one entry, got %+v (%v)", verification, err)
+	}
+
+	// The log cannot be appended to once a directory takes its place.
+	logPath := filepath.Join(s.dataDir, auditFileName)
+	if err := os.Rename(logPath, logPath+".saved"); err != nil {
+		t.Fatalf("move audit log: %v", err)
+	}
+	if err := os.Mkdir(logPath, 0o700); err != nil {
+		t.Fatalf("block audit log: %v", err)
+	}
+	for _, req := range []WriteRequest{
+		{TargetPath: first, Key: "user.name", Value: "Bob"},
+		{TargetPath: second, Key: "user.email", Value: "bob@example.com"},
+	} {
+		if _, err := s.StageWrite(ctx, req); err != nil {
+			t.Fatalf("StageWrite returned error: %v", err)
+		}
+	}
+	result, err := s.ApplyStaged(ctx, nil)
+	if err != nil {
+		t.Fatalf("expected an audit failure not to fail the batch, got %v", err)
+	}
+	if len(result.ChangeSets) != 2 || result.ChangeSets[0].AuditWarning == "" || result.ChangeSets[1].AuditWarning == "" {
+		t.Fatalf("expected both change sets to carry an audit warning, got %+v", result.ChangeSets)
+	}
+	if got := gitConfigGet(t, dir, "--file", first, "user.name"); got != "Bob" {
+		t.Fatalf("expected the batch to be kept, got %q", got)
+	}
+	if got := gitConfigGet(t, dir, "--file", second, "user.email"); got != "bob@example.com" {
+		t.Fatalf("expected the batch to be kept, got %q", got)
+	}
+
+	// Later entries keep chaining onto the cached tail of the log.
+	if err := os.Remove(logPath); err != nil {
+		t.Fatalf("unblock audit log: %v", err)
+	}
+	if err := os.Rename(logPath+".saved", logPath); err != nil {
+		t.Fatalf("restore audit log: %v", err)
+	}
+	if _, err := s.WriteConfig(ctx, WriteRequest{TargetPath: first, Key: "user.name", Value: "Carol"}); err != nil {
+		t.Fatalf("WriteConfig returned error: %v", err)
+	}
+	if verification, err := s.VerifyAuditLog(ctx); err != nil || !verification.Valid || verification.Entries != 2 {
+		t.Fatalf("expected a valid log of two entries, got %+v (%v)", verification, err)
+	}
+}
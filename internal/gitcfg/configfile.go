@@ -0,0 +1,197 @@
+package gitcfg
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// configFileEntry is a variable assignment read directly from a config file.
+type configFileEntry struct {
+	key     ConfigKey
+	value   string
+	noValue bool
+	// line is the line on which the value ends, matching git's own line accounting for values
+	// continued over several lines.
+	line int
+}
+
+// configFileParser reads the config file syntax accepted by git's config.c: comments starting
+// with '#' or ';', section headers, quoted values with escapes and backslash continuations.
+type configFileParser struct {
+	data []byte
+	pos  int
+	line int
+}
+
+// parseConfigFile returns the assignments of a config file in order.
+func parseConfigFile(data []byte) ([]configFileEntry, error) {
+	p := &configFileParser{data: bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")), line: 1}
+	var (
+		entries    []configFileEntry
+		section    string
+		subsection string
+	)
+
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		switch {
+		case c == '\n':
+			p.pos++
+			p.line++
+		case c == ' ' || c == '\t' || c == '\r':
+			p.pos++
+		case c == '#' || c == ';':
+			p.skipLine()
+		case c == '[':
+			header, err := p.readHeader()
+			if err != nil {
+				return nil, err
+			}
+			if section, subsection, err = parseSectionHeader(header); err != nil {
+				return nil, p.errorf("%v", err)
+			}
+		case isAlpha(c):
+			if section == "" {
+				return nil, p.errorf("variable outside of a section")
+			}
+			entry, err := p.readVariable()
+			if err != nil {
+				return nil, err
+			}
+			entry.key.Section = section
+			entry.key.Subsection = subsection
+			entries = append(entries, entry)
+		default:
+			return nil, p.errorf("unexpected character %q", c)
+		}
+	}
+	return entries, nil
+}
+
+func isAlpha(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func (p *configFileParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("bad config line %d: %s", p.line, fmt.Sprintf(format, args...))
+}
+
+func (p *configFileParser) skipLine() {
+	for p.pos < len(p.data) && p.data[p.pos] != '\n' {
+		p.pos++
+	}
+}
+
+// readHeader returns the text between '[' and the matching ']', honouring quotes.
+func (p *configFileParser) readHeader() (string, error) {
+	start := p.pos + 1
+	quoted := false
+	for i := start; i < len(p.data); i++ {
+		switch c := p.data[i]; {
+		case c == '\n':
+			return "", p.errorf("unterminated section header")
+		case c == '\\' && quoted:
+			i++
+		case c == '"':
+			quoted = !quoted
+		case c == ']' && !quoted:
+			p.pos = i + 1
+			return string(p.data[start:i]), nil
+		}
+	}
+	return "", p.errorf("unterminated section header")
+}
+
+func (p *configFileParser) readVariable() (configFileEntry, error) {
+	start := p.pos
+	for p.pos < len(p.data) && (isAlpha(p.data[p.pos]) || (p.data[p.pos] >= '0' && p.data[p.pos] <= '9') || p.data[p.pos] == '-') {
+		p.pos++
+	}
+	entry := configFileEntry{key: ConfigKey{Name: strings.ToLower(string(p.data[start:p.pos]))}}
+
+	for p.pos < len(p.data) && (p.data[p.pos] == ' ' || p.data[p.pos] == '\t' || p.data[p.pos] == '\r') {
+		p.pos++
+	}
+	if p.pos == len(p.data) || p.data[p.pos] == '\n' || p.data[p.pos] == '#' || p.data[p.pos] == ';' {
+		p.skipLine()
+		entry.noValue = true
+		entry.line = p.line
+		return entry, nil
+	}
+	if p.data[p.pos] != '=' {
+		return configFileEntry{}, p.errorf("expected '=' after %q", entry.key.Name)
+	}
+	p.pos++
+
+	value, err := p.readValue()
+	if err != nil {
+		return configFileEntry{}, err
+	}
+	entry.value = value
+	entry.line = p.line
+	return entry, nil
+}
+
+// readValue mirrors parse_value: unquoted whitespace becomes spaces, while leading and
+// trailing whitespace is dropped.
+func (p *configFileParser) readValue() (string, error) {
+	var (
+		b       strings.Builder
+		quoted  bool
+		pending int
+	)
+	for ; p.pos < len(p.data); p.pos++ {
+		c := p.data[p.pos]
+		if c == '\n' {
+			break
+		}
+		if !quoted && (c == ' ' || c == '\t' || c == '\r') {
+			if b.Len() > 0 {
+				pending++
+			}
+			continue
+		}
+		if !quoted && (c == '#' || c == ';') {
+			p.skipLine()
+			break
+		}
+		b.WriteString(strings.Repeat(" ", pending))
+		pending = 0
+
+		switch c {
+		case '"':
+			quoted = !quoted
+		case '\\':
+			p.pos++
+			if p.pos == len(p.data) {
+				return "", p.errorf("value ends with a backslash")
+			}
+			switch escaped := p.data[p.pos]; escaped {
+			case '\n':
+				p.line++
+			case '\r':
+				if p.pos+1 < len(p.data) && p.data[p.pos+1] == '\n' {
+					p.pos++
+					p.line++
+				}
+			case 't':
+				b.WriteByte('\t')
+			case 'b':
+				b.WriteByte('\b')
+			case 'n':
+				b.WriteByte('\n')
+			case '\\', '"':
+				b.WriteByte(escaped)
+			default:
+				return "", p.errorf("invalid escape \\%c", escaped)
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	if quoted {
+		return "", p.errorf("unterminated quoted value")
+	}
+	return b.String(), nil
+}
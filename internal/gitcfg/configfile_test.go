@@ -0,0 +1,96 @@
+package gitcfg
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseConfigFile(t *testing.T) {
+	t.Parallel()
+
+	data := "# comment\n[user]\n\tName = Alice  Smith ; trailing\n" +
+		"[includeIf \"gitdir:~/work/\"]\n\tpath = work.gitconfig\n" +
+		"[core]\n\tbare\n\tpager = \"less -R\" # pager\n" +
+		"[alias]\n\tlg = log \\\n\t\t--oneline\n\tq = \"a\\tb\\\\c\\\"\"\n"
+	entries, err := parseConfigFile([]byte(data))
+	if err != nil {
+		t.Fatalf("parseConfigFile returned error: %v", err)
+	}
+
+	want := []struct {
+		key     string
+		value   string
+		noValue bool
+		line    int
+	}{
+		{key: "user.name", value: "Alice  Smith", line: 3},
+		{key: "includeif.gitdir:~/work/.path", value: "work.gitconfig", line: 5},
+		{key: "core.bare", noValue: true, line: 7},
+		{key: "core.pager", value: "less -R", line: 8},
+		{key: "alias.lg", value: "log   --oneline", line: 11},
+		{key: "alias.q", value: "a\tb\\c\"", line: 12},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("expected %d entries, got %+v", len(want), entries)
+	}
+	for i, w := range want {
+		got := entries[i]
+		if got.key.String() != w.key || got.value != w.value || got.noValue != w.noValue || got.line != w.line {
+			t.Fatalf("entry %d: expected %+v, got key=%q value=%q noValue=%t line=%d", i, w, got.key.String(), got.value, got.noValue, got.line)
+		}
+	}
+}
+
+func TestParseConfigFileErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []string{
+		"name = outside\n",
+		"[user\n\tname = x\n",
+		"[user]\n\tname = \"open\n",
+		"[user]\n\tname x\n",
+		"[user]\n\tname = bad\\q\n",
+	}
+	for _, data := range tests {
+		if _, err := parseConfigFile([]byte(data)); err == nil {
+			t.Fatalf("expected %q to be rejected", data)
+		}
+	}
+}
+
+func TestParseConfigFileMatchesGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	path := filepath.Join(t.TempDir(), "config")
+	data := "[alias]\n\tlg = log \\\n\t\t--oneline \\\n\t\t--graph\n\tst = \"status  -sb\" ; short\n[user]\n\tname = A  B\t\n"
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	entries, err := parseConfigFile([]byte(data))
+	if err != nil {
+		t.Fatalf("parseConfigFile returned error: %v", err)
+	}
+
+	out, err := exec.Command("git", "config", "--file", path, "--list", "--show-scope", "--show-origin").Output()
+	if err != nil {
+		t.Skipf("git config --show-origin unavailable: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	if len(lines) != len(entries) {
+		t.Fatalf("expected %d entries from git, got %q", len(entries), lines)
+	}
+	for i, line := range lines {
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) != 3 {
+			t.Fatalf("unexpected git output %q", line)
+		}
+		assignment := fields[2]
+		if got := entries[i].key.String() + "=" + entries[i].value; got != assignment {
+			t.Fatalf("entry %d: git reports %q, parser reports %q", i, assignment, got)
+		}
+	}
+}
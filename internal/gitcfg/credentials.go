@@ -0,0 +1,404 @@
+package gitcfg
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// CredentialEntry is one credential.* or credential.<url>.* configuration value.
+type CredentialEntry struct {
+	// Key is the variable without its URL, e.g. credential.helper.
+	Key string `json:"key"`
+	// Pattern is the URL subsection, or empty for the unscoped variable.
+	Pattern string       `json:"pattern,omitempty"`
+	Value   string       `json:"value"`
+	Source  ConfigSource `json:"source"`
+}
+
+// CredentialResolution is the credential configuration git applies to a URL.
+type CredentialResolution struct {
+	Input string `json:"input"`
+	// Remote is set when Input named a configured remote rather than a URL.
+	Remote string `json:"remote,omitempty"`
+	URL    string `json:"url"`
+	// Helpers lists the helpers git asks in order; an empty helper value clears the list.
+	Helpers []CredentialEntry `json:"helpers"`
+	// Settings holds the other credential.* variables that apply, such as username.
+	Settings []CredentialEntry `json:"settings"`
+}
+
+// StoredCredential is a line of a file written by the store credential helper.
+type StoredCredential struct {
+	ID       string `json:"id"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	URL      string `json:"url"`
+	Protocol string `json:"protocol"`
+	Host     string `json:"host"`
+	Path     string `json:"path,omitempty"`
+	Username string `json:"username,omitempty"`
+	// HasPassword reports whether the line contains a password; the password itself is never returned.
+	HasPassword bool `json:"hasPassword"`
+}
+
+// WriteOperationDeleteCredential marks change sets that removed a stored credential.
+const WriteOperationDeleteCredential WriteOperation = "delete-credential"
+
+// ConfigScopeCredentialStore marks change sets of a store helper file, which holds
+// credentials rather than config keys.
+const ConfigScopeCredentialStore ConfigScope = "credential-store"
+
+// readCredentialEntries returns credential.* entries in the order git reads them, which the
+// matrix does not preserve across keys but helper chains depend on.
+func (s *Service) readCredentialEntries(ctx context.Context, repositoryID string) ([]gitConfigEntry, error) {
+	args := []string{"config", "--null", "--show-origin", "--show-scope"}
+	if repositoryID == GlobalRepositoryID {
+		args = append(args, "--global")
+	} else {
+		s.mu.RLock()
+		repo, ok := s.repositories[repositoryID]
+		s.mu.RUnlock()
+		if !ok {
+			return nil, fmt.Errorf("repository %q not found", repositoryID)
+		}
+		args = append([]string{"-C", repo.Path}, args...)
+	}
+	args = append(args, "--get-regexp", `^credential\.`)
+
+	cmd := exec.CommandContext(ctx, "git", args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 && stderr.Len() == 0 {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("git config failed: %w", gitError(err, stderr.String()))
+	}
+	return parseGitConfigOutput(output)
+}
+
+func credentialEntry(entry gitConfigEntry) (CredentialEntry, bool) {
+	key, err := ParseKey(entry.key)
+	if err != nil || key.Section != "credential" {
+		return CredentialEntry{}, false
+	}
+	return CredentialEntry{
+		Key:     ConfigKey{Section: key.Section, Name: key.Name}.String(),
+		Pattern: key.Subsection,
+		Value:   entry.value,
+		Source:  ConfigSource{Scope: entry.scope, File: entry.file, Line: entry.line},
+	}, true
+}
+
+// resolveCredentialConfig mirrors credential_apply_config: every entry whose pattern matches
+// target applies in config order, helpers accumulate until an empty value resets them, and
+// other variables take the last matching value.
+func resolveCredentialConfig(entries []gitConfigEntry, target string) ([]CredentialEntry, []CredentialEntry, error) {
+	targetURL, err := normalizeMatchURL(target)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	helpers := []CredentialEntry{}
+	settings := []CredentialEntry{}
+	position := make(map[string]int)
+	for _, raw := range entries {
+		entry, ok := credentialEntry(raw)
+		if !ok {
+			continue
+		}
+		if entry.Pattern != "" {
+			pattern, err := normalizeMatchURL(entry.Pattern)
+			if err != nil {
+				continue
+			}
+			if _, ok := matchURL(pattern, targetURL); !ok {
+				continue
+			}
+		}
+
+		if entry.Key == "credential.helper" {
+			if entry.Value == "" {
+				helpers = helpers[:0]
+				continue
+			}
+			helpers = append(helpers, entry)
+			continue
+		}
+		if i, ok := position[entry.Key]; ok {
+			settings[i] = entry
+			continue
+		}
+		position[entry.Key] = len(settings)
+		settings = append(settings, entry)
+	}
+	return helpers, settings, nil
+}
+
+func redactCredentialEntry(entry CredentialEntry) CredentialEntry {
+	entry.Value, _ = redactValue(entry.Value)
+	entry.Pattern, _ = redactValue(entry.Pattern)
+	return entry
+}
+
+// ListCredentialConfig returns every credential.* entry visible to a repository in the order
+// git reads them, with credentials redacted.
+func (s *Service) ListCredentialConfig(ctx context.Context, repositoryID string) ([]CredentialEntry, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	raw, err := s.readCredentialEntries(ctx, repositoryID)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]CredentialEntry, 0, len(raw))
+	for _, item := range raw {
+		if entry, ok := credentialEntry(item); ok {
+			entries = append(entries, redactCredentialEntry(entry))
+		}
+	}
+	return entries, nil
+}
+
+// ResolveCredentialHelpers shows the helper chain and settings git uses for input, which is
+// either a URL or the name of a remote. Remote URLs are rewritten with insteadOf first.
+func (s *Service) ResolveCredentialHelpers(ctx context.Context, repositoryID, input string) (CredentialResolution, error) {
+	select {
+	case <-ctx.Done():
+		return CredentialResolution{}, ctx.Err()
+	default:
+	}
+
+	if input == "" {
+		return CredentialResolution{}, errors.New("url cannot be empty")
+	}
+
+	matrix, err := s.matrixFor(ctx, repositoryID)
+	if err != nil {
+		return CredentialResolution{}, err
+	}
+	resolution := CredentialResolution{Input: input, URL: input}
+	if urls, ok := configuredRemotes(matrix)[input]; ok && len(urls) > 0 {
+		resolution.Remote = input
+		resolution.URL, _ = rewriteURL(urls[0], collectURLRewrites(matrix), false)
+	}
+
+	raw, err := s.readCredentialEntries(ctx, repositoryID)
+	if err != nil {
+		return CredentialResolution{}, err
+	}
+	resolution.Helpers, resolution.Settings, err = resolveCredentialConfig(raw, resolution.URL)
+	if err != nil {
+		return CredentialResolution{}, err
+	}
+
+	resolution.Input, _ = redactValue(resolution.Input)
+	resolution.URL, _ = redactValue(resolution.URL)
+	for i := range resolution.Helpers {
+		resolution.Helpers[i] = redactCredentialEntry(resolution.Helpers[i])
+	}
+	for i := range resolution.Settings {
+		resolution.Settings[i] = redactCredentialEntry(resolution.Settings[i])
+	}
+	return resolution, nil
+}
+
+// defaultCredentialFiles returns the files git-credential-store reads when no --file is given.
+func defaultCredentialFiles() []string {
+	var files []string
+	if home, err := os.UserHomeDir(); err == nil {
+		files = append(files, filepath.Join(home, ".git-credentials"))
+	}
+	if xdg := xdgConfigPath(); xdg != "" {
+		files = append(files, filepath.Join(filepath.Dir(xdg), "credentials"))
+	}
+	return files
+}
+
+// storeHelperFiles returns the files used by helper if it is the store helper, or nil.
+func storeHelperFiles(helper string) []string {
+	words, err := splitCmdline(helper)
+	if err != nil || len(words) == 0 || (words[0] != "store" && filepath.Base(words[0]) != "git-credential-store") {
+		return nil
+	}
+	for i, word := range words[1:] {
+		var file string
+		switch {
+		case strings.HasPrefix(word, "--file="):
+			file = strings.TrimPrefix(word, "--file=")
+		case word == "--file" && i+2 < len(words):
+			file = words[i+2]
+		default:
+			continue
+		}
+		if expanded, err := expandPath(file); err == nil {
+			return []string{expanded}
+		}
+		return nil
+	}
+	return defaultCredentialFiles()
+}
+
+// credentialLineID identifies a line of a store file. The index keeps identical lines apart.
+func credentialLineID(file string, index int, line string) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%d\x00%s", file, index, line)))
+	return hex.EncodeToString(sum[:8])
+}
+
+// parseCredentialStore parses the URL-per-line format of git-credential-store. Lines that are
+// not valid credential URLs are skipped, as git does.
+func parseCredentialStore(file string, data []byte) []StoredCredential {
+	var credentials []StoredCredential
+	for i, line := range splitLines(string(data)) {
+		line = strings.TrimSuffix(line, "\r")
+		if line == "" {
+			continue
+		}
+		u, err := url.Parse(line)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			continue
+		}
+
+		credential := StoredCredential{
+			ID:       credentialLineID(file, i, line),
+			File:     file,
+			Line:     i + 1,
+			Protocol: u.Scheme,
+			Host:     u.Host,
+			Path:     strings.TrimPrefix(u.Path, "/"),
+		}
+		if u.User != nil {
+			credential.Username = u.User.Username()
+			_, credential.HasPassword = u.User.Password()
+		}
+		credential.URL, _ = redactValue(line)
+		credentials = append(credentials, credential)
+	}
+	return credentials
+}
+
+// credentialStoreFiles returns the existing files of every store helper configured for a
+// repository. The default store files are only included when a store helper without --file
+// uses them.
+func (s *Service) credentialStoreFiles(ctx context.Context, repositoryID string) ([]string, error) {
+	raw, err := s.readCredentialEntries(ctx, repositoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []string
+	for _, item := range raw {
+		if entry, ok := credentialEntry(item); ok && entry.Key == "credential.helper" {
+			candidates = append(candidates, storeHelperFiles(entry.Value)...)
+		}
+	}
+
+	var files []string
+	seen := make(map[string]bool)
+	for _, file := range candidates {
+		file = filepath.Clean(file)
+		if seen[file] {
+			continue
+		}
+		seen[file] = true
+		if _, err := os.Stat(file); err == nil {
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
+
+// ListStoredCredentials returns the entries of the store helper files used by a repository.
+// Passwords are never returned.
+func (s *Service) ListStoredCredentials(ctx context.Context, repositoryID string) ([]StoredCredential, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	files, err := s.credentialStoreFiles(ctx, repositoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	credentials := []StoredCredential{}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", file, err)
+		}
+		credentials = append(credentials, parseCredentialStore(file, data)...)
+	}
+	return credentials, nil
+}
+
+// DeleteStoredCredential removes a stored credential by ID from its file. The removal is
+// recorded as a change set and can be undone in the session, but the file is not backed up:
+// a backup would keep plaintext copies of every other credential on disk.
+func (s *Service) DeleteStoredCredential(ctx context.Context, repositoryID, id string, dryRun bool) (ChangeSet, error) {
+	credentials, err := s.ListStoredCredentials(ctx, repositoryID)
+	if err != nil {
+		return ChangeSet{}, err
+	}
+
+	var target *StoredCredential
+	for i := range credentials {
+		if credentials[i].ID == id {
+			target = &credentials[i]
+			break
+		}
+	}
+	if target == nil {
+		return ChangeSet{}, fmt.Errorf("stored credential %q not found", id)
+	}
+
+	var lock *fileLock
+	if !dryRun {
+		if lock, err = lockFile(target.File); err != nil {
+			return ChangeSet{}, err
+		}
+		defer lock.release()
+	}
+	before, err := os.ReadFile(target.File)
+	if err != nil {
+		return ChangeSet{}, fmt.Errorf("read %s: %w", target.File, err)
+	}
+	lines := splitLines(string(before))
+	index := target.Line - 1
+	if index >= len(lines) || credentialLineID(target.File, index, strings.TrimSuffix(lines[index], "\r")) != id {
+		return ChangeSet{}, fmt.Errorf("%s changed while the credential was being removed; try again", target.File)
+	}
+	after := strings.Join(append(lines[:index:index], lines[index+1:]...), "\n")
+	if after != "" {
+		after += "\n"
+	}
+
+	cs := ChangeSet{
+		RepositoryID: repositoryID,
+		Scope:        ConfigScopeCredentialStore,
+		Operation:    WriteOperationDeleteCredential,
+	}
+	if dryRun {
+		cs.FilePath = target.File
+		cs.Diff = redactDiff(unifiedDiff(target.File, string(before), after))
+		cs.CreatedAt = timestamp(time.Now())
+		return cs, nil
+	}
+	return s.recordChange(cs, fileChange{path: target.File, before: before, after: []byte(after), existed: true, lock: lock})
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
 	"os/exec"
 	"path/filepath"
 	"sort"
@@ -20,6 +21,8 @@ type gitConfigEntry struct {
 	file  string
 	line  int
 	order int
+	// noValue marks a `[section] key` line without "=", which git treats as boolean true.
+	noValue bool
 }
 
 func readGitConfig(ctx context.Context, repoPath string) (map[string]ConfigValue, error) {
@@ -45,6 +48,14 @@ func readGitConfig(ctx context.Context, repoPath string) (map[string]ConfigValue
 func readGlobalConfig(ctx context.Context) (map[string]ConfigValue, error) {
 	cmd := exec.CommandContext(ctx, "git", "config", "--null", "--show-origin", "--show-scope", "--list", "--global")
 
+	// git refuses to list the global scope when no global file exists; on a fresh machine that
+	// simply means nothing is configured yet.
+	if path, err := globalConfigPath(); err == nil {
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			return map[string]ConfigValue{}, nil
+		}
+	}
+
 	output, err := cmd.Output()
 	if err != nil {
 		return nil, fmt.Errorf("git config --global failed: %w", err)
@@ -75,7 +86,7 @@ func parseGitConfigOutput(raw []byte) ([]gitConfigEntry, error) {
 			continue
 		}
 
-		key, value := splitKeyValue(keyValueRaw)
+		key, value, noValue := splitKeyValue(keyValueRaw)
 		if key == "" {
 			continue
 		}
@@ -88,6 +99,8 @@ func parseGitConfigOutput(raw []byte) ([]gitConfigEntry, error) {
 			file:  file,
 			line:  line,
 			order: order,
+
+			noValue: noValue,
 		}
 		order++
 		entries = append(entries, entry)
@@ -96,17 +109,17 @@ func parseGitConfigOutput(raw []byte) ([]gitConfigEntry, error) {
 	return entries, nil
 }
 
-func splitKeyValue(raw []byte) (string, string) {
+func splitKeyValue(raw []byte) (string, string, bool) {
 	parts := bytes.SplitN(raw, []byte{'\n'}, 2)
 	if len(parts) == 0 {
-		return "", ""
+		return "", "", false
 	}
 
 	key := string(parts[0])
 	if len(parts) == 1 {
-		return key, ""
+		return key, "", true
 	}
-	return key, string(parts[1])
+	return key, string(parts[1]), false
 }
 
 func normalizeOrigin(origin string) (string, int) {
@@ -140,37 +153,50 @@ func buildConfigValues(entries []gitConfigEntry) map[string]ConfigValue {
 
 	byKey := make(map[string][]gitConfigEntry)
 	for _, entry := range entries {
-		byKey[entry.key] = append(byKey[entry.key], entry)
+		key := CanonicalKey(entry.key)
+		byKey[key] = append(byKey[key], entry)
 	}
 
+	now := time.Now()
 	result := make(map[string]ConfigValue, len(byKey))
 	for key, items := range byKey {
 		sort.SliceStable(items, func(i, j int) bool {
 			return items[i].order < items[j].order
 		})
 
+		valueType := ValueTypeForKey(key)
 		active := items[len(items)-1]
 		value := ConfigValue{
 			Key:   key,
 			Value: active.value,
+			Type:  valueType,
 			Source: ConfigSource{
 				Scope: active.scope,
 				File:  active.file,
 				Line:  active.line,
+				Order: active.order,
 			},
 			LastModified: "",
 		}
+		if normalized, err := normalizeValue(valueType, active.value, active.noValue, now); err != nil {
+			value.TypeError = err.Error()
+		} else {
+			value.Normalized = normalized
+		}
 
 		if len(items) > 1 {
 			overrides := make([]ConfigOverride, 0, len(items)-1)
 			for i := len(items) - 2; i >= 0; i-- {
 				entry := items[i]
+				normalized, _ := normalizeValue(valueType, entry.value, entry.noValue, now)
 				overrides = append(overrides, ConfigOverride{
-					Value: entry.value,
+					Value:      entry.value,
+					Normalized: normalized,
 					Source: ConfigSource{
 						Scope: entry.scope,
 						File:  entry.file,
 						Line:  entry.line,
+						Order: entry.order,
 					},
 					Timestamp: "",
 				})
@@ -238,7 +264,7 @@ func buildRepository(ctx context.Context, path string) (Repository, error) {
 		repoType = RepositoryTypeBare
 	}
 
-	return Repository{
+	repo := Repository{
 		ID:           ensureID(topLevel),
 		Name:         name,
 		Path:         topLevel,
@@ -250,15 +276,26 @@ func buildRepository(ctx context.Context, path string) (Repository, error) {
 		IsSubmodule:  isSubmodule,
 		LastScanTime: timestamp(time.Now()),
 		Status:       RepoStatusIdle,
-	}, nil
+	}
+	if remotes, err := readRemotes(ctx, path); err != nil {
+		repo.RemotesError = fmt.Sprintf("read remotes: %v", err)
+	} else {
+		repo.Remotes = remotes
+	}
+	return repo, nil
 }
 
 func gitQuery(ctx context.Context, workingDir string, args ...string) (string, error) {
 	allArgs := append([]string{"-C", workingDir}, args...)
 	cmd := exec.CommandContext(ctx, "git", allArgs...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
 	out, err := cmd.Output()
 	if err != nil {
-		return "", err
+		if dubious := parseDubiousOwnership(stderr.String()); dubious != nil {
+			return "", dubious
+		}
+		return "", gitError(err, stderr.String())
 	}
 	return string(out), nil
 }
@@ -272,3 +309,39 @@ func samePath(a, b string) bool {
 	normalizedB := filepath.Clean(b)
 	return normalizedA == normalizedB
 }
+
+// sameFile reports whether a and b name the same file once symlinks are resolved, so that a
+// ~/.gitconfig linked into a dotfiles repository matches its target.
+func sameFile(a, b string) bool {
+	if samePath(a, b) {
+		return true
+	}
+	if a == "" || b == "" {
+		return false
+	}
+	return samePath(resolveExistingPrefix(filepath.Clean(a)), resolveExistingPrefix(filepath.Clean(b)))
+}
+
+// pathWithin reports whether path is dir or lies below it.
+func pathWithin(path, dir string) bool {
+	if path == "" || dir == "" {
+		return false
+	}
+	rel, err := filepath.Rel(filepath.Clean(dir), filepath.Clean(path))
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// valuesInOrder returns every value recorded for a key, including overridden ones, in the
+// order git read them. This is the full value list of multi-valued keys.
+func valuesInOrder(value ConfigValue) []ConfigOverride {
+	values := make([]ConfigOverride, 0, len(value.Overrides)+1)
+	for i := len(value.Overrides) - 1; i >= 0; i-- {
+		values = append(values, value.Overrides[i])
+	}
+	return append(values, ConfigOverride{
+		Value:      value.Value,
+		Normalized: value.Normalized,
+		Source:     value.Source,
+		Timestamp:  value.LastModified,
+	})
+}
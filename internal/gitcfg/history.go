@@ -0,0 +1,212 @@
+package gitcfg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// KeyHistoryExternal is the operation of changes made outside of the application.
+const KeyHistoryExternal = "external"
+
+// KeyHistoryEvent is one change to the values of a key in one file. Values are redacted and in
+// file order; the effective value is the last one. External events are found by comparing the
+// values the audit log recorded with the ones observed later, so their Timestamp is when the
+// change was detected rather than when it was made.
+type KeyHistoryEvent struct {
+	Timestamp   string      `json:"timestamp"`
+	Operation   string      `json:"operation"`
+	User        string      `json:"user,omitempty"`
+	Scope       ConfigScope `json:"scope,omitempty"`
+	File        string      `json:"file"`
+	Before      []string    `json:"before,omitempty"`
+	Values      []string    `json:"values,omitempty"`
+	Removed     bool        `json:"removed,omitempty"`
+	ChangeSetID string      `json:"changeSetId,omitempty"`
+	BatchID     string      `json:"batchId,omitempty"`
+	RevertsID   string      `json:"revertsId,omitempty"`
+	External    bool        `json:"external,omitempty"`
+	// Reverted is set when a later rollback restored the file to its state before the change;
+	// RevertedBy is the change set of that rollback, if it has one.
+	Reverted   bool   `json:"reverted,omitempty"`
+	RevertedBy string `json:"revertedBy,omitempty"`
+}
+
+// KeyHistory is the timeline of a key across the files a repository reads, oldest first.
+type KeyHistory struct {
+	RepositoryID string            `json:"repositoryId"`
+	Key          string            `json:"key"`
+	Events       []KeyHistoryEvent `json:"events"`
+}
+
+// keyTimeline tracks the last known values of a key in one file.
+type keyTimeline struct {
+	scope  ConfigScope
+	values []string
+}
+
+// buildKeyHistory replays entries for key, keeping changes to the files in scopes, and
+// compares the last recorded values with current, the values each file holds now. Files that
+// hold the key without a recorded change start with an external event. Files missing from
+// current could not be read and are not compared.
+func buildKeyHistory(entries []AuditEntry, key string, scopes map[string]ConfigScope, current map[string][]string, now string) []KeyHistoryEvent {
+	reverted := make(map[string]string)
+	for _, entry := range entries {
+		switch {
+		case entry.RevertsID != "":
+			reverted[entry.RevertsID] = entry.ChangeSetID
+		case entry.Operation == AuditBatchUndo:
+			reverted[entry.ChangeSetID] = ""
+		}
+	}
+
+	events := []KeyHistoryEvent{}
+	timelines := make(map[string]*keyTimeline)
+	var files []string
+	for _, entry := range entries {
+		file := filepath.Clean(entry.File)
+		scope, tracked := scopes[file]
+		if entry.File == "" || !tracked {
+			continue
+		}
+		i := slices.IndexFunc(entry.Keys, func(change AuditKeyChange) bool { return change.Key == key })
+		if i < 0 {
+			continue
+		}
+		change := entry.Keys[i]
+		if entry.Scope != "" {
+			scope = entry.Scope
+		}
+
+		timeline, seen := timelines[file]
+		if !seen {
+			timeline = &keyTimeline{}
+			timelines[file] = timeline
+			files = append(files, file)
+		} else if !slices.Equal(timeline.values, change.Before) {
+			events = append(events, externalKeyEvent(file, timeline, change.Before, entry.Timestamp))
+		}
+
+		event := KeyHistoryEvent{
+			Timestamp:   entry.Timestamp,
+			Operation:   entry.Operation,
+			User:        entry.User,
+			Scope:       scope,
+			File:        file,
+			Before:      change.Before,
+			Values:      change.After,
+			Removed:     len(change.After) == 0,
+			ChangeSetID: entry.ChangeSetID,
+			BatchID:     entry.BatchID,
+			RevertsID:   entry.RevertsID,
+		}
+		if by, ok := reverted[entry.ChangeSetID]; ok && entry.Operation != AuditBatchUndo {
+			event.Reverted = true
+			event.RevertedBy = by
+		}
+		events = append(events, event)
+		timeline.scope = scope
+		timeline.values = change.After
+	}
+
+	// Files that hold the key without any recorded change to it were only edited outside of
+	// the application; their timeline starts with the values they hold now.
+	var unrecorded []string
+	for file, values := range current {
+		if _, seen := timelines[file]; !seen && len(values) > 0 {
+			timelines[file] = &keyTimeline{scope: scopes[file]}
+			unrecorded = append(unrecorded, file)
+		}
+	}
+	slices.Sort(unrecorded)
+	files = append(files, unrecorded...)
+
+	for _, file := range files {
+		timeline := timelines[file]
+		if values, ok := current[file]; ok && !slices.Equal(timeline.values, values) {
+			events = append(events, externalKeyEvent(file, timeline, values, now))
+		}
+	}
+	return events
+}
+
+// externalKeyEvent describes values found in file that no recorded change explains.
+func externalKeyEvent(file string, timeline *keyTimeline, values []string, detected string) KeyHistoryEvent {
+	return KeyHistoryEvent{
+		Timestamp: detected,
+		Operation: KeyHistoryExternal,
+		Scope:     timeline.scope,
+		File:      file,
+		Before:    timeline.values,
+		Values:    values,
+		Removed:   len(values) == 0,
+		External:  true,
+	}
+}
+
+// GetKeyHistory returns every recorded change to key in the files the repository reads,
+// including changes made to those files outside of the application.
+func (s *Service) GetKeyHistory(ctx context.Context, repositoryID, key string) (KeyHistory, error) {
+	select {
+	case <-ctx.Done():
+		return KeyHistory{}, ctx.Err()
+	default:
+	}
+
+	parsed, err := ParseKey(key)
+	if err != nil {
+		return KeyHistory{}, err
+	}
+	key = parsed.String()
+
+	graph, _, err := s.includeGraph(ctx, repositoryID)
+	if err != nil {
+		return KeyHistory{}, err
+	}
+	scopes := make(map[string]ConfigScope)
+	for _, node := range graph.Nodes {
+		if node.Active {
+			scopes[filepath.Clean(node.File)] = node.Scope
+		}
+	}
+
+	s.mu.Lock()
+	entries, err := s.readAuditLocked()
+	s.mu.Unlock()
+	if err != nil {
+		return KeyHistory{}, err
+	}
+	// Files the repository no longer reads still belong to its history when the change was
+	// made on its behalf.
+	for _, entry := range entries {
+		file := filepath.Clean(entry.File)
+		if _, ok := scopes[file]; !ok && entry.File != "" && entry.RepositoryID == repositoryID {
+			scopes[file] = entry.Scope
+		}
+	}
+
+	current := make(map[string][]string)
+	for file := range scopes {
+		data, err := os.ReadFile(file)
+		if errors.Is(err, os.ErrNotExist) {
+			current[file] = nil
+			continue
+		}
+		if err != nil {
+			return KeyHistory{}, fmt.Errorf("read %s: %w", file, err)
+		}
+		if values := fileKeyValues(data); values != nil {
+			current[file] = redactValues(values[key])
+		}
+	}
+
+	return KeyHistory{
+		RepositoryID: repositoryID,
+		Key:          key,
+		Events:       buildKeyHistory(entries, key, scopes, current, timestamp(time.Now())),
+	}, nil
+}
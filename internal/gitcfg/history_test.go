@@ -0,0 +1,99 @@
+package gitcfg
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBuildKeyHistory(t *testing.T) {
+	t.Parallel()
+
+	file := filepath.Join(string(filepath.Separator), "home", "alice", ".gitconfig")
+	entries := []AuditEntry{
+		{Timestamp: "t1", Operation: "set", File: file, ChangeSetID: "a", Keys: []AuditKeyChange{{Key: "user.name", After: []string{"Alice"}}}},
+		{Timestamp: "t2", Operation: "set", File: file, ChangeSetID: "b", Keys: []AuditKeyChange{{Key: "user.email", After: []string{"alice@example.com"}}}},
+		// Something else renamed the user before the next recorded change.
+		{Timestamp: "t3", Operation: "set", File: file, ChangeSetID: "c", Keys: []AuditKeyChange{{Key: "user.name", Before: []string{"Bob"}, After: []string{"Carol"}}}},
+		{Timestamp: "t4", Operation: "rollback", File: file, ChangeSetID: "d", RevertsID: "c", Keys: []AuditKeyChange{{Key: "user.name", Before: []string{"Carol"}, After: []string{"Bob"}}}},
+		{Timestamp: "t5", Operation: "set", File: "/elsewhere", ChangeSetID: "e", Keys: []AuditKeyChange{{Key: "user.name", After: []string{"Dave"}}}},
+	}
+	scopes := map[string]ConfigScope{file: ConfigScopeGlobal}
+
+	events := buildKeyHistory(entries, "user.name", scopes, map[string][]string{file: {"Bob"}}, "now")
+	var got []string
+	for _, event := range events {
+		got = append(got, event.Timestamp+":"+event.Operation+"="+strings.Join(event.Values, ","))
+	}
+	want := "t1:set=Alice t3:external=Bob t3:set=Carol t4:rollback=Bob"
+	if strings.Join(got, " ") != want {
+		t.Fatalf("expected %s, got %s", want, strings.Join(got, " "))
+	}
+	if !events[2].Reverted || events[2].RevertedBy != "d" || events[0].Reverted {
+		t.Fatalf("expected only the third change to be reverted, got %+v", events)
+	}
+	if events[1].Scope != ConfigScopeGlobal || !events[1].External {
+		t.Fatalf("unexpected external event: %+v", events[1])
+	}
+
+	events = buildKeyHistory(entries, "user.name", scopes, map[string][]string{file: nil}, "now")
+	if last := events[len(events)-1]; !last.External || !last.Removed || last.Timestamp != "now" {
+		t.Fatalf("expected the removal to be reported as external, got %+v", last)
+	}
+	// A file only ever edited outside of the application still has a timeline.
+	other := filepath.Join(string(filepath.Separator), "home", "alice", "work.gitconfig")
+	scopes[other] = ConfigScopeInclude
+	events = buildKeyHistory(entries, "user.name", scopes, map[string][]string{file: {"Bob"}, other: {"Eve"}}, "now")
+	if last := events[len(events)-1]; last.File != other || !last.External || last.Scope != ConfigScopeInclude || len(last.Before) != 0 || strings.Join(last.Values, ",") != "Eve" {
+		t.Fatalf("expected the untracked edit to be reported as external, got %+v", last)
+	}
+}
+
+func TestGetKeyHistory(t *testing.T) {
+	s := newTestService(t)
+	ctx := context.Background()
+	global := filepath.Join(t.TempDir(), "gitconfig")
+	t.Setenv("GIT_CONFIG_GLOBAL", global)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	repo := newTestRepository(t, s)
+
+	if _, err := s.WriteConfig(ctx, WriteRequest{Scope: ConfigScopeGlobal, Key: "user.email", Value: "alice@example.com"}); err != nil {
+		t.Fatalf("WriteConfig returned error: %v", err)
+	}
+	local, err := s.WriteConfig(ctx, WriteRequest{RepositoryID: repo.ID, Scope: ConfigScopeLocal, Key: "user.email", Value: "alice@work.example"})
+	if err != nil {
+		t.Fatalf("WriteConfig returned error: %v", err)
+	}
+	rollback, err := s.Rollback(ctx, local.ID)
+	if err != nil {
+		t.Fatalf("Rollback returned error: %v", err)
+	}
+	if _, err := s.WriteConfig(ctx, WriteRequest{Scope: ConfigScopeGlobal, Key: "user.name", Value: "Alice"}); err != nil {
+		t.Fatalf("WriteConfig returned error: %v", err)
+	}
+	runGit(t, repo.Path, "config", "--file", global, "user.email", "alice@home.example")
+
+	history, err := s.GetKeyHistory(ctx, repo.ID, "User.Email")
+	if err != nil {
+		t.Fatalf("GetKeyHistory returned error: %v", err)
+	}
+	if history.Key != "user.email" || len(history.Events) != 4 {
+		t.Fatalf("expected 4 events for user.email, got %+v", history)
+	}
+	wantScopes := []ConfigScope{ConfigScopeGlobal, ConfigScopeLocal, ConfigScopeLocal, ConfigScopeGlobal}
+	for i, event := range history.Events {
+		if event.Scope != wantScopes[i] {
+			t.Fatalf("event %d: expected scope %s, got %s", i, wantScopes[i], event.Scope)
+		}
+	}
+	if e := history.Events[1]; e.ChangeSetID != local.ID || !e.Reverted || e.RevertedBy != rollback.ID {
+		t.Fatalf("expected the local write to be reverted by the rollback, got %+v", e)
+	}
+	if e := history.Events[2]; e.RevertsID != local.ID || !e.Removed {
+		t.Fatalf("expected the rollback to remove the local value, got %+v", e)
+	}
+	if e := history.Events[3]; !e.External || strings.Join(e.Values, ",") != "alice@home.example" || strings.Join(e.Before, ",") != "alice@example.com" {
+		t.Fatalf("expected the external edit to be detected, got %+v", e)
+	}
+}
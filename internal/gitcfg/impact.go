@@ -0,0 +1,297 @@
+package gitcfg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ImpactChange classifies how the effective value of a key changes.
+type ImpactChange string
+
+const (
+	ImpactAdded    ImpactChange = "added"
+	ImpactRemoved  ImpactChange = "removed"
+	ImpactModified ImpactChange = "modified"
+)
+
+// RepositoryImpact is the effect of a pending change on one key of one repository. Before
+// and After are the values the system and global scopes provide; when Hidden is set a
+// repository-level value, shown in Effective, keeps the repository from seeing the change.
+type RepositoryImpact struct {
+	RepositoryID   string       `json:"repositoryId"`
+	RepositoryName string       `json:"repositoryName"`
+	Path           string       `json:"path"`
+	Key            string       `json:"key"`
+	Change         ImpactChange `json:"change"`
+	Before         string       `json:"before,omitempty"`
+	After          string       `json:"after,omitempty"`
+	Hidden         bool         `json:"hidden,omitempty"`
+	Effective      string       `json:"effective,omitempty"`
+	HiddenBy       ConfigSource `json:"hiddenBy"`
+}
+
+// ImpactReport lists every tracked repository affected by a pending change to a file outside
+// of any repository.
+type ImpactReport struct {
+	FilePath string `json:"filePath"`
+	Diff     string `json:"diff"`
+	// Keys are the keys whose value changes for at least one repository.
+	Keys    []string           `json:"keys"`
+	Impacts []RepositoryImpact `json:"impacts"`
+	// Repositories is the number of repositories evaluated.
+	Repositories int    `json:"repositories"`
+	AnalyzedAt   string `json:"analyzedAt"`
+}
+
+// ruleCondition returns the includeIf condition of a rule. Patterns without a condition type
+// are gitdir patterns.
+func ruleCondition(rule IncludeRule) string {
+	for _, prefix := range []string{"gitdir:", "gitdir/i:", "onbranch:", "hasconfig:"} {
+		if strings.HasPrefix(rule.Pattern, prefix) {
+			return rule.Pattern
+		}
+	}
+	return "gitdir:" + rule.Pattern
+}
+
+// valueList renders every value of a key, oldest first, for comparison.
+func valueList(value ConfigValue, ok bool) []string {
+	if !ok {
+		return nil
+	}
+	var values []string
+	for _, v := range valuesInOrder(value) {
+		values = append(values, v.Value)
+	}
+	return values
+}
+
+// outranksGlobal reports whether a value comes from a scope git reads after the global one.
+func outranksGlobal(scope ConfigScope) bool {
+	switch scope {
+	case ConfigScopeLocal, ConfigScopeWorktree, ConfigScopeCommand, ConfigScopeEnv:
+		return true
+	default:
+		return false
+	}
+}
+
+// repositoryImpacts compares the system and global values a repository receives before and
+// after a change, using matrix to detect repository-level values that hide the change.
+func repositoryImpacts(repo Repository, matrix ConfigMatrix, before, after map[string]ConfigValue) []RepositoryImpact {
+	keys := make(map[string]bool)
+	for key := range before {
+		keys[key] = true
+	}
+	for key := range after {
+		keys[key] = true
+	}
+
+	var impacts []RepositoryImpact
+	for key := range keys {
+		// The include directives themselves are reported through the keys they bring in.
+		if parsed, err := ParseKey(key); err == nil {
+			if _, _, isInclude := includeDirective(parsed); isInclude {
+				continue
+			}
+		}
+		old, hadOld := before[key]
+		updated, hasNew := after[key]
+		if strings.Join(valueList(old, hadOld), "\x00") == strings.Join(valueList(updated, hasNew), "\x00") && hadOld == hasNew {
+			continue
+		}
+
+		impact := RepositoryImpact{
+			RepositoryID:   repo.ID,
+			RepositoryName: repo.Name,
+			Path:           repo.Path,
+			Key:            key,
+			Change:         ImpactModified,
+		}
+		switch {
+		case !hadOld:
+			impact.Change = ImpactAdded
+		case !hasNew:
+			impact.Change = ImpactRemoved
+		}
+		if hadOld {
+			impact.Before, _ = redactValue(old.Value)
+		}
+		if hasNew {
+			impact.After, _ = redactValue(updated.Value)
+		}
+		// Repository-level values of a multi-valued key add to the global ones instead of
+		// replacing them, so the change still reaches the repository.
+		if info, _ := LookupKeyInfo(key); info.MultiValued {
+			impacts = append(impacts, impact)
+			continue
+		}
+		if current, ok := matrix.Entries[key]; ok && outranksGlobal(current.Source.Scope) {
+			impact.Hidden = true
+			impact.Effective, _ = redactValue(current.Value)
+			impact.HiddenBy = current.Source
+		}
+		impacts = append(impacts, impact)
+	}
+	return impacts
+}
+
+// impactOf evaluates edit, which must target a file outside of any repository, for every
+// tracked repository.
+func (s *Service) impactOf(ctx context.Context, edit configEdit) (ImpactReport, error) {
+	before, after, err := previewEdit(ctx, edit)
+	if err != nil {
+		return ImpactReport{}, err
+	}
+	report := ImpactReport{
+		FilePath:   edit.path,
+		Diff:       redactDiff(unifiedDiff(edit.path, string(before), string(after))),
+		Keys:       []string{},
+		Impacts:    []RepositoryImpact{},
+		AnalyzedAt: timestamp(time.Now()),
+	}
+	contents := map[string][]byte{filepath.Clean(edit.path): after}
+
+	s.mu.RLock()
+	repos := make([]Repository, 0, len(s.repositories))
+	for _, repo := range s.repositories {
+		repos = append(repos, repo)
+	}
+	s.mu.RUnlock()
+
+	roots := globalConfigRoots()
+	keys := make(map[string]bool)
+	for _, repo := range repos {
+		if err := ctx.Err(); err != nil {
+			return ImpactReport{}, err
+		}
+		matrix, err := s.loadMatrix(ctx, repo.ID, false)
+		if err != nil {
+			continue
+		}
+		ictx := repositoryIncludeContext(repo, matrix.Entries)
+		old, err := evaluateConfig(roots, ictx, nil)
+		if err != nil {
+			return ImpactReport{}, err
+		}
+		updated, err := evaluateConfig(roots, ictx, contents)
+		if err != nil {
+			return ImpactReport{}, fmt.Errorf("evaluate change: %w", err)
+		}
+		for _, impact := range repositoryImpacts(repo, matrix, old, updated) {
+			keys[impact.Key] = true
+			report.Impacts = append(report.Impacts, impact)
+		}
+		report.Repositories++
+	}
+
+	for key := range keys {
+		report.Keys = append(report.Keys, key)
+	}
+	sort.Strings(report.Keys)
+	sort.Slice(report.Impacts, func(i, j int) bool {
+		a, b := report.Impacts[i], report.Impacts[j]
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return a.Key < b.Key
+	})
+	return report, nil
+}
+
+// PreviewWriteImpact lists the tracked repositories whose effective configuration would change
+// if req were applied. Only writes to system, global or included files can be analysed.
+func (s *Service) PreviewWriteImpact(ctx context.Context, req WriteRequest) (ImpactReport, error) {
+	select {
+	case <-ctx.Done():
+		return ImpactReport{}, ctx.Err()
+	default:
+	}
+
+	if req.TargetPath == "" && (req.Scope == ConfigScopeLocal || req.Scope == ConfigScopeWorktree) {
+		return ImpactReport{}, errors.New("impact analysis applies to system, global and included files; preview repository changes with a dry run")
+	}
+	_, edit, err := s.prepareWrite(ctx, req)
+	if err != nil {
+		return ImpactReport{}, err
+	}
+	if repo, ok := s.repositoryContaining(ctx, edit.path); ok {
+		return ImpactReport{}, fmt.Errorf("%s belongs to repository %q; preview repository changes with a dry run", edit.path, repo.Name)
+	}
+	return s.impactOf(ctx, edit)
+}
+
+// repositoryContaining returns the tracked repository whose git directory, or common
+// directory for worktrees, holds path.
+func (s *Service) repositoryContaining(ctx context.Context, path string) (Repository, bool) {
+	paths := []string{path}
+	if real, err := filepath.EvalSymlinks(filepath.Dir(path)); err == nil {
+		paths = append(paths, filepath.Join(real, filepath.Base(path)))
+	}
+
+	s.mu.RLock()
+	repos := make([]Repository, 0, len(s.repositories))
+	for _, repo := range s.repositories {
+		repos = append(repos, repo)
+	}
+	s.mu.RUnlock()
+
+	within := func(dir string) bool {
+		dirs := []string{dir}
+		if real, err := filepath.EvalSymlinks(dir); err == nil {
+			dirs = append(dirs, real)
+		}
+		for _, d := range dirs {
+			for _, p := range paths {
+				if pathWithin(p, d) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	for _, repo := range repos {
+		if within(repo.GitDir) {
+			return repo, true
+		}
+		if config, err := repositoryConfigPath(ctx, repo, "config"); err == nil && within(filepath.Dir(config)) {
+			return repo, true
+		}
+	}
+	return Repository{}, false
+}
+
+// PreviewRuleImpact lists the tracked repositories whose effective configuration would change
+// if rule were saved. Saving writes the rule's includeIf directive to the global config file,
+// replacing the directive of the stored rule with the same ID; a disabled rule only removes it.
+func (s *Service) PreviewRuleImpact(ctx context.Context, rule IncludeRule) (ImpactReport, error) {
+	select {
+	case <-ctx.Done():
+		return ImpactReport{}, ctx.Err()
+	default:
+	}
+
+	if rule.Pattern == "" {
+		return ImpactReport{}, errors.New("pattern cannot be empty")
+	}
+	if rule.TargetPath == "" {
+		return ImpactReport{}, errors.New("targetPath cannot be empty")
+	}
+	path, present, err := globalRuleDirectives()
+	if err != nil {
+		return ImpactReport{}, err
+	}
+
+	s.mu.RLock()
+	stored, existed := s.includeRules[rule.ID]
+	s.mu.RUnlock()
+	if rule.ID == "" {
+		existed = false
+	}
+	return s.impactOf(ctx, configEdit{path: path, ops: ruleOps(ruleState(stored, existed), &rule, present)})
+}
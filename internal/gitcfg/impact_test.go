@@ -0,0 +1,120 @@
+package gitcfg
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRuleCondition(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"~/work/":            "gitdir:~/work/",
+		"gitdir/i:~/Work/":   "gitdir/i:~/Work/",
+		"onbranch:release/*": "onbranch:release/*",
+		"hasconfig:remote.*.url:https://github.com/**": "hasconfig:remote.*.url:https://github.com/**",
+	}
+	for pattern, want := range tests {
+		if got := ruleCondition(IncludeRule{Pattern: pattern}); got != want {
+			t.Fatalf("ruleCondition(%q) = %q, want %q", pattern, got, want)
+		}
+	}
+}
+
+func TestPreviewImpact(t *testing.T) {
+	s := newTestService(t)
+	ctx := context.Background()
+	home := t.TempDir()
+	global := filepath.Join(home, "gitconfig")
+	t.Setenv("GIT_CONFIG_GLOBAL", global)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	writeTestFile(t, global, "[user]\n\temail = alice@example.com\n")
+
+	plain := newTestRepository(t, s)
+	pinned := newTestRepository(t, s)
+	runGit(t, pinned.Path, "config", "user.email", "alice@pinned.example")
+
+	report, err := s.PreviewWriteImpact(ctx, WriteRequest{Scope: ConfigScopeGlobal, Key: "user.email", Value: "alice@new.example"})
+	if err != nil {
+		t.Fatalf("PreviewWriteImpact returned error: %v", err)
+	}
+	if report.Repositories != 2 || len(report.Impacts) != 2 || len(report.Keys) != 1 || report.Keys[0] != "user.email" {
+		t.Fatalf("expected user.email to change for both repositories, got %+v", report)
+	}
+	for _, impact := range report.Impacts {
+		if impact.Before != "alice@example.com" || impact.After != "alice@new.example" || impact.Change != ImpactModified {
+			t.Fatalf("unexpected impact: %+v", impact)
+		}
+		hidden := impact.RepositoryID == pinned.ID
+		if impact.Hidden != hidden || (hidden && (impact.Effective != "alice@pinned.example" || impact.HiddenBy.Scope != ConfigScopeLocal)) {
+			t.Fatalf("unexpected hidden state for %s: %+v", impact.Path, impact)
+		}
+	}
+	if got := gitConfigGet(t, plain.Path, "user.email"); got != "alice@example.com" {
+		t.Fatalf("expected the preview to leave the global file alone, got %q", got)
+	}
+
+	writeTestFile(t, filepath.Join(home, "work"), "[commit]\n\tgpgsign = true\n")
+	report, err = s.PreviewRuleImpact(ctx, IncludeRule{Pattern: plain.GitDir, TargetPath: filepath.Join(home, "work"), Enabled: true})
+	if err != nil {
+		t.Fatalf("PreviewRuleImpact returned error: %v", err)
+	}
+	if len(report.Impacts) != 1 || report.Impacts[0].RepositoryID != plain.ID || report.Impacts[0].Key != "commit.gpgsign" || report.Impacts[0].Change != ImpactAdded {
+		t.Fatalf("expected the rule to add commit.gpgsign to one repository, got %+v", report.Impacts)
+	}
+
+	// Updating or disabling a stored rule replaces or removes the directive it wrote.
+	stored, err := s.UpsertRule(ctx, IncludeRule{Pattern: plain.GitDir, TargetPath: filepath.Join(home, "work"), Enabled: true})
+	if err != nil {
+		t.Fatalf("UpsertRule returned error: %v", err)
+	}
+	writeTestFile(t, filepath.Join(home, "other"), "[commit]\n\tgpgsign = false\n")
+	moved := stored
+	moved.TargetPath = filepath.Join(home, "other")
+	report, err = s.PreviewRuleImpact(ctx, moved)
+	if err != nil {
+		t.Fatalf("PreviewRuleImpact returned error: %v", err)
+	}
+	if len(report.Impacts) != 1 || report.Impacts[0].Change != ImpactModified || report.Impacts[0].Before != "true" || report.Impacts[0].After != "false" {
+		t.Fatalf("expected the update to replace the included value, got %+v", report.Impacts)
+	}
+	disabled := stored
+	disabled.Enabled = false
+	report, err = s.PreviewRuleImpact(ctx, disabled)
+	if err != nil {
+		t.Fatalf("PreviewRuleImpact returned error: %v", err)
+	}
+	if len(report.Impacts) != 1 || report.Impacts[0].Change != ImpactRemoved {
+		t.Fatalf("expected disabling the rule to remove commit.gpgsign, got %+v", report.Impacts)
+	}
+	if err := s.DeleteRule(ctx, stored.ID); err != nil {
+		t.Fatalf("DeleteRule returned error: %v", err)
+	}
+	if got := gitConfigGet(t, plain.Path, "commit.gpgsign"); got != "" {
+		t.Fatalf("expected deleting the rule to remove its include, got %q", got)
+	}
+
+	if _, err := s.PreviewWriteImpact(ctx, WriteRequest{RepositoryID: plain.ID, Scope: ConfigScopeLocal, Key: "user.name", Value: "x"}); err == nil {
+		t.Fatal("expected repository scopes to be rejected")
+	}
+	if _, err := s.PreviewWriteImpact(ctx, WriteRequest{Scope: ConfigScopeInclude, TargetPath: filepath.Join(plain.GitDir, "config"), Key: "user.name", Value: "x"}); err == nil || !strings.Contains(err.Error(), "belongs to repository") {
+		t.Fatalf("expected a target inside a repository to be rejected, got %v", err)
+	}
+
+	// A local refspec adds to the global ones rather than hiding them.
+	runGit(t, pinned.Path, "config", "remote.origin.fetch", "+refs/heads/main:refs/remotes/origin/main")
+	report, err = s.PreviewWriteImpact(ctx, WriteRequest{Scope: ConfigScopeGlobal, Key: "remote.origin.fetch", Value: "+refs/tags/*:refs/tags/*", Operation: WriteOperationAdd})
+	if err != nil {
+		t.Fatalf("PreviewWriteImpact returned error: %v", err)
+	}
+	for _, impact := range report.Impacts {
+		if impact.Hidden {
+			t.Fatalf("expected a multi-valued key not to be hidden, got %+v", impact)
+		}
+	}
+	if len(report.Impacts) != 2 {
+		t.Fatalf("expected the refspec to reach both repositories, got %+v", report.Impacts)
+	}
+}
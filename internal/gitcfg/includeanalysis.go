@@ -0,0 +1,321 @@
+package gitcfg
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// IncludeFindingKind classifies a problem found in the include graph.
+type IncludeFindingKind string
+
+const (
+	IncludeFindingCycle     IncludeFindingKind = "cycle"
+	IncludeFindingDepth     IncludeFindingKind = "depth-limit"
+	IncludeFindingMissing   IncludeFindingKind = "missing-target"
+	IncludeFindingOrphan    IncludeFindingKind = "orphan"
+	IncludeFindingUnmatched IncludeFindingKind = "unmatched-condition"
+)
+
+// IncludeFinding is a problem in the include graph. File and Line point at the directive
+// responsible, or at the file itself for orphans.
+type IncludeFinding struct {
+	Kind     IncludeFindingKind `json:"kind"`
+	Severity string             `json:"severity"`
+	File     string             `json:"file"`
+	Line     int                `json:"line,omitempty"`
+	// Files lists the files of a cycle in include order.
+	Files   []string `json:"files,omitempty"`
+	Message string   `json:"message"`
+}
+
+// IncludeAnalysis is the include graph of the system and per-user config files combined with
+// the graphs of every tracked repository. A node or edge is active when it is active for at
+// least one of them.
+type IncludeAnalysis struct {
+	Graph        IncludeGraph     `json:"graph"`
+	Repositories int              `json:"repositories"`
+	Findings     []IncludeFinding `json:"findings"`
+	AnalyzedAt   string           `json:"analyzedAt"`
+}
+
+// includeMerger unions the graphs of several walks.
+type includeMerger struct {
+	graph IncludeGraph
+	nodes map[string]int
+	edges map[string]int
+	// evaluated records conditional edges whose including file was loaded in some walk, so
+	// that their condition was actually tested.
+	evaluated map[string]bool
+}
+
+func newIncludeMerger() *includeMerger {
+	return &includeMerger{
+		graph:     IncludeGraph{Nodes: []IncludeNode{}, Edges: []IncludeEdge{}},
+		nodes:     make(map[string]int),
+		edges:     make(map[string]int),
+		evaluated: make(map[string]bool),
+	}
+}
+
+func includeEdgeID(edge IncludeEdge) string {
+	return fmt.Sprintf("%s\x00%d\x00%s", edge.From, edge.Line, edge.To)
+}
+
+func (m *includeMerger) add(graph IncludeGraph) {
+	for _, node := range graph.Nodes {
+		i, ok := m.nodes[node.File]
+		if !ok {
+			m.nodes[node.File] = len(m.graph.Nodes)
+			m.graph.Nodes = append(m.graph.Nodes, node)
+			continue
+		}
+		merged := &m.graph.Nodes[i]
+		merged.Root = merged.Root || node.Root
+		merged.Active = merged.Active || node.Active
+	}
+
+	for _, edge := range graph.Edges {
+		id := includeEdgeID(edge)
+		if edge.Condition != "" && edge.Reason != includeReasonNotLoaded {
+			m.evaluated[id] = true
+		}
+		i, ok := m.edges[id]
+		if !ok {
+			m.edges[id] = len(m.graph.Edges)
+			m.graph.Edges = append(m.graph.Edges, edge)
+			continue
+		}
+		merged := &m.graph.Edges[i]
+		switch {
+		case merged.Active:
+		case edge.Active, merged.Reason == includeReasonNotLoaded:
+			*merged = edge
+		}
+	}
+}
+
+// includeCycles returns every distinct cycle of the graph, each starting at its smallest file.
+func includeCycles(graph IncludeGraph) [][]string {
+	adjacent := make(map[string][]string)
+	for _, edge := range graph.Edges {
+		adjacent[edge.From] = append(adjacent[edge.From], edge.To)
+	}
+
+	const (
+		unvisited = iota
+		inProgress
+		done
+	)
+	state := make(map[string]int)
+	seen := make(map[string]bool)
+	var (
+		cycles [][]string
+		stack  []string
+		visit  func(file string)
+	)
+	visit = func(file string) {
+		state[file] = inProgress
+		stack = append(stack, file)
+		for _, next := range adjacent[file] {
+			switch state[next] {
+			case unvisited:
+				visit(next)
+			case inProgress:
+				start := len(stack) - 1
+				for stack[start] != next {
+					start--
+				}
+				cycle := rotateToSmallest(append([]string(nil), stack[start:]...))
+				if id := strings.Join(cycle, "\x00"); !seen[id] {
+					seen[id] = true
+					cycles = append(cycles, cycle)
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[file] = done
+	}
+
+	for _, node := range graph.Nodes {
+		if state[node.File] == unvisited {
+			visit(node.File)
+		}
+	}
+	return cycles
+}
+
+func rotateToSmallest(cycle []string) []string {
+	smallest := 0
+	for i, file := range cycle {
+		if file < cycle[smallest] {
+			smallest = i
+		}
+	}
+	return append(cycle[smallest:], cycle[:smallest]...)
+}
+
+// orphanIncludeFiles returns the files of include directories that no directive reaches. The
+// directories searched are ~/.gitconfig.d and every *.d directory holding an include target.
+func orphanIncludeFiles(graph IncludeGraph, home string) []string {
+	dirs := make(map[string]bool)
+	if home != "" {
+		dirs[filepath.Join(home, ".gitconfig.d")] = true
+	}
+	known := make(map[string]bool)
+	for _, node := range graph.Nodes {
+		known[node.File] = true
+		if dir := filepath.Dir(node.File); !node.Root && strings.HasSuffix(dir, ".d") {
+			dirs[dir] = true
+		}
+	}
+
+	var orphans []string
+	for dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			if !entry.Type().IsRegular() || strings.HasPrefix(name, ".") || strings.HasSuffix(name, "~") {
+				continue
+			}
+			if file := filepath.Join(dir, name); !known[file] {
+				orphans = append(orphans, file)
+			}
+		}
+	}
+	sort.Strings(orphans)
+	return orphans
+}
+
+// analyzeIncludes reports cycles, directives beyond git's depth limit, missing targets, orphan
+// files and conditions that never matched. evaluated is the set built by includeMerger.
+func analyzeIncludes(graph IncludeGraph, evaluated map[string]bool, home string) []IncludeFinding {
+	findings := []IncludeFinding{}
+	nodes := make(map[string]IncludeNode, len(graph.Nodes))
+	for _, node := range graph.Nodes {
+		nodes[node.File] = node
+	}
+	edgeInto := make(map[string]IncludeEdge)
+	for _, edge := range graph.Edges {
+		edgeInto[edge.From+"\x00"+edge.To] = edge
+	}
+
+	for _, cycle := range includeCycles(graph) {
+		severity := "error"
+		for i, file := range cycle {
+			if !edgeInto[file+"\x00"+cycle[(i+1)%len(cycle)]].Active {
+				severity = "warning"
+			}
+		}
+		last := edgeInto[cycle[len(cycle)-1]+"\x00"+cycle[0]]
+		findings = append(findings, IncludeFinding{
+			Kind:     IncludeFindingCycle,
+			Severity: severity,
+			File:     last.From,
+			Line:     last.Line,
+			Files:    cycle,
+			Message:  fmt.Sprintf("include cycle %s -> %s; git stops with \"exceeded maximum include depth\" when it is loaded", strings.Join(cycle, " -> "), cycle[0]),
+		})
+	}
+
+	for _, edge := range graph.Edges {
+		switch {
+		case edge.Reason == includeReasonDepth:
+			findings = append(findings, IncludeFinding{
+				Kind:     IncludeFindingDepth,
+				Severity: "error",
+				File:     edge.From,
+				Line:     edge.Line,
+				Message:  fmt.Sprintf("including %s %s; git refuses to load the configuration", edge.To, includeReasonDepth),
+			})
+		case !nodes[edge.To].Exists && nodes[edge.To].Error == "":
+			severity := "info"
+			if edge.Active {
+				severity = "warning"
+			}
+			findings = append(findings, IncludeFinding{
+				Kind:     IncludeFindingMissing,
+				Severity: severity,
+				File:     edge.From,
+				Line:     edge.Line,
+				Message:  fmt.Sprintf("%s points at %s, which does not exist; git ignores it silently", edge.Directive, edge.To),
+			})
+		}
+		if edge.Condition != "" && !edge.Active && evaluated[includeEdgeID(edge)] && edge.Reason != includeReasonDepth {
+			findings = append(findings, IncludeFinding{
+				Kind:     IncludeFindingUnmatched,
+				Severity: "info",
+				File:     edge.From,
+				Line:     edge.Line,
+				Message:  fmt.Sprintf("condition %q matches none of the tracked repositories (%s)", edge.Condition, edge.Reason),
+			})
+		}
+	}
+
+	for _, file := range orphanIncludeFiles(graph, home) {
+		findings = append(findings, IncludeFinding{
+			Kind:     IncludeFindingOrphan,
+			Severity: "info",
+			File:     file,
+			Message:  "no include directive reaches this file",
+		})
+	}
+	return findings
+}
+
+// AnalyzeIncludes walks the include graph of the system and per-user config files and of every
+// tracked repository, and reports the problems found in it. Repositories whose configuration
+// cannot be read are left out.
+func (s *Service) AnalyzeIncludes(ctx context.Context) (IncludeAnalysis, error) {
+	select {
+	case <-ctx.Done():
+		return IncludeAnalysis{}, ctx.Err()
+	default:
+	}
+
+	s.mu.RLock()
+	repos := make([]Repository, 0, len(s.repositories))
+	for _, repo := range s.repositories {
+		repos = append(repos, repo)
+	}
+	s.mu.RUnlock()
+	sort.Slice(repos, func(i, j int) bool {
+		return repos[i].Path < repos[j].Path
+	})
+
+	merger := newIncludeMerger()
+	global, _, err := s.includeGraph(ctx, GlobalRepositoryID)
+	if err != nil {
+		return IncludeAnalysis{}, err
+	}
+	merger.add(global)
+
+	analyzed := 0
+	for _, repo := range repos {
+		if err := ctx.Err(); err != nil {
+			return IncludeAnalysis{}, err
+		}
+		matrix, err := s.loadMatrix(ctx, repo.ID, false)
+		if err != nil {
+			continue
+		}
+		graph, _ := walkIncludes(repositoryIncludeRoots(ctx, repo, matrix.Entries), repositoryIncludeContext(repo, matrix.Entries))
+		merger.add(graph)
+		analyzed++
+	}
+
+	home, _ := os.UserHomeDir()
+	return IncludeAnalysis{
+		Graph:        merger.graph,
+		Repositories: analyzed,
+		Findings:     analyzeIncludes(merger.graph, merger.evaluated, home),
+		AnalyzedAt:   timestamp(time.Now()),
+	}, nil
+}
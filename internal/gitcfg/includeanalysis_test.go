@@ -0,0 +1,104 @@
+package gitcfg
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func findingsOfKind(findings []IncludeFinding, kind IncludeFindingKind) []IncludeFinding {
+	var matched []IncludeFinding
+	for _, finding := range findings {
+		if finding.Kind == kind {
+			matched = append(matched, finding)
+		}
+	}
+	return matched
+}
+
+func TestAnalyzeIncludes(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	home := t.TempDir()
+	confd := filepath.Join(dir, "conf.d")
+	if err := os.Mkdir(confd, 0o700); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	root := filepath.Join(dir, "gitconfig")
+	writeTestFile(t, root, "[include]\n\tpath = conf.d/used\n\tpath = loop-a\n\tpath = missing\n"+
+		"[includeIf \"gitdir:/nowhere/\"]\n\tpath = never\n")
+	writeTestFile(t, filepath.Join(confd, "used"), "[user]\n\tname = Alice\n")
+	writeTestFile(t, filepath.Join(confd, "orphan"), "[user]\n\tname = Bob\n")
+	writeTestFile(t, filepath.Join(confd, ".hidden"), "")
+	writeTestFile(t, filepath.Join(dir, "loop-a"), "[include]\n\tpath = loop-b\n")
+	writeTestFile(t, filepath.Join(dir, "loop-b"), "[include]\n\tpath = loop-a\n")
+	writeTestFile(t, filepath.Join(dir, "never"), "")
+
+	merger := newIncludeMerger()
+	for _, gitDir := range []string{"", "/srv/api/.git"} {
+		graph, _ := walkIncludes([]includeRoot{{file: root, scope: ConfigScopeGlobal}}, includeContext{gitDir: gitDir})
+		merger.add(graph)
+	}
+	findings := analyzeIncludes(merger.graph, merger.evaluated, home)
+
+	cycles := findingsOfKind(findings, IncludeFindingCycle)
+	if len(cycles) != 1 || cycles[0].Severity != "error" || len(cycles[0].Files) != 2 || cycles[0].Files[0] != filepath.Join(dir, "loop-a") {
+		t.Fatalf("unexpected cycle findings: %+v", cycles)
+	}
+	if missing := findingsOfKind(findings, IncludeFindingMissing); len(missing) != 1 || missing[0].Line != 4 || missing[0].Severity != "warning" {
+		t.Fatalf("unexpected missing-target findings: %+v", missing)
+	}
+	if orphans := findingsOfKind(findings, IncludeFindingOrphan); len(orphans) != 1 || orphans[0].File != filepath.Join(confd, "orphan") {
+		t.Fatalf("unexpected orphan findings: %+v", orphans)
+	}
+	if unmatched := findingsOfKind(findings, IncludeFindingUnmatched); len(unmatched) != 1 || unmatched[0].Line != 6 {
+		t.Fatalf("unexpected unmatched-condition findings: %+v", unmatched)
+	}
+	for _, node := range merger.graph.Nodes {
+		if node.File == filepath.Join(confd, "used") && (node.Mode != "-rw-------" || node.Size == 0) {
+			t.Fatalf("expected mode and size on %+v", node)
+		}
+	}
+}
+
+func TestAnalyzeIncludesDepthLimit(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	for i := 0; i <= maxIncludeDepth+1; i++ {
+		writeTestFile(t, filepath.Join(dir, fmt.Sprintf("level%d", i)), fmt.Sprintf("[include]\n\tpath = level%d\n", i+1))
+	}
+	graph, _ := walkIncludes([]includeRoot{{file: filepath.Join(dir, "level0"), scope: ConfigScopeGlobal}}, includeContext{})
+	depth := findingsOfKind(analyzeIncludes(graph, nil, ""), IncludeFindingDepth)
+	if len(depth) != 1 || depth[0].File != filepath.Join(dir, fmt.Sprintf("level%d", maxIncludeDepth)) {
+		t.Fatalf("unexpected depth findings: %+v", depth)
+	}
+}
+
+func TestServiceAnalyzeIncludes(t *testing.T) {
+	s := newTestService(t)
+	home := t.TempDir()
+	global := filepath.Join(home, "gitconfig")
+	t.Setenv("GIT_CONFIG_GLOBAL", global)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	repo := newTestRepository(t, s)
+
+	writeTestFile(t, filepath.Join(home, "work"), "[user]\n\temail = alice@example.com\n")
+	writeTestFile(t, global, "[includeIf \"gitdir:"+repo.GitDir+"\"]\n\tpath = work\n"+
+		"[includeIf \"onbranch:release\"]\n\tpath = release\n")
+
+	analysis, err := s.AnalyzeIncludes(context.Background())
+	if err != nil {
+		t.Fatalf("AnalyzeIncludes returned error: %v", err)
+	}
+	if analysis.Repositories != 1 {
+		t.Fatalf("expected one analyzed repository, got %d", analysis.Repositories)
+	}
+	unmatched := findingsOfKind(analysis.Findings, IncludeFindingUnmatched)
+	if len(unmatched) != 1 || unmatched[0].Line != 4 {
+		t.Fatalf("expected only the onbranch include to be unmatched, got %+v", analysis.Findings)
+	}
+}
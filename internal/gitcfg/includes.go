@@ -0,0 +1,440 @@
+package gitcfg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// maxIncludeDepth is git's limit on nested includes (MAX_INCLUDE_DEPTH).
+const maxIncludeDepth = 10
+
+// Reasons recorded on edges that are not followed for reasons other than their condition.
+const includeReasonNotLoaded = "the including file is not loaded"
+
+var includeReasonDepth = fmt.Sprintf("exceeds the maximum include depth of %d", maxIncludeDepth)
+
+// IncludeLink is one include directive on the path from a top-level config file to the file a
+// value was read from.
+type IncludeLink struct {
+	File string `json:"file"`
+	Line int    `json:"line"`
+	// Directive is the variable that caused the include, e.g. include.path or
+	// includeIf.gitdir:~/work/.path.
+	Directive string `json:"directive"`
+	Condition string `json:"condition,omitempty"`
+}
+
+// IncludeNode is a config file taking part in the configuration of a repository.
+type IncludeNode struct {
+	File  string      `json:"file"`
+	Scope ConfigScope `json:"scope"`
+	// Root marks files git reads directly rather than through an include.
+	Root   bool `json:"root"`
+	Exists bool `json:"exists"`
+	// Mode is the permission string of the file, e.g. -rw-r--r--.
+	Mode string `json:"mode,omitempty"`
+	Size int64  `json:"size"`
+	// Active reports whether git loads the file for the repository.
+	Active bool   `json:"active"`
+	Error  string `json:"error,omitempty"`
+}
+
+// IncludeEdge is an include or includeIf directive.
+type IncludeEdge struct {
+	From      string `json:"from"`
+	Line      int    `json:"line"`
+	To        string `json:"to"`
+	Directive string `json:"directive"`
+	Condition string `json:"condition,omitempty"`
+	// Active reports whether git follows the directive for the repository.
+	Active bool `json:"active"`
+	// Reason explains why an edge is not followed.
+	Reason string `json:"reason,omitempty"`
+}
+
+// IncludeGraph describes every config file of a repository and the includes between them.
+type IncludeGraph struct {
+	RepositoryID string        `json:"repositoryId"`
+	Nodes        []IncludeNode `json:"nodes"`
+	Edges        []IncludeEdge `json:"edges"`
+}
+
+// includeContext is the repository state includeIf conditions are evaluated against.
+type includeContext struct {
+	// gitDir is the absolute git directory, or empty outside of a repository.
+	gitDir string
+	// branch is the checked out branch, or empty when HEAD is detached.
+	branch     string
+	remoteURLs []string
+}
+
+// includeRoot is a config file git reads directly.
+type includeRoot struct {
+	file  string
+	scope ConfigScope
+}
+
+type includeWalker struct {
+	ictx     includeContext
+	graph    IncludeGraph
+	nodes    map[string]int
+	edges    map[string]int
+	chains   map[string][]IncludeLink
+	expanded map[string]bool
+}
+
+// walkIncludes builds the include graph reachable from roots. Inactive includes are followed
+// too so that the graph shows every file, but only active files receive an include chain.
+func walkIncludes(roots []includeRoot, ictx includeContext) (IncludeGraph, map[string][]IncludeLink) {
+	w := &includeWalker{
+		ictx:     ictx,
+		graph:    IncludeGraph{Nodes: []IncludeNode{}, Edges: []IncludeEdge{}},
+		nodes:    make(map[string]int),
+		edges:    make(map[string]int),
+		chains:   make(map[string][]IncludeLink),
+		expanded: make(map[string]bool),
+	}
+	for _, root := range roots {
+		w.visit(root.file, root.scope, true, true, nil, nil)
+	}
+	return w.graph, w.chains
+}
+
+func (w *includeWalker) node(file string, scope ConfigScope, root bool) *IncludeNode {
+	if i, ok := w.nodes[file]; ok {
+		return &w.graph.Nodes[i]
+	}
+	w.nodes[file] = len(w.graph.Nodes)
+	w.graph.Nodes = append(w.graph.Nodes, IncludeNode{File: file, Scope: scope, Root: root})
+	return &w.graph.Nodes[len(w.graph.Nodes)-1]
+}
+
+func (w *includeWalker) visit(file string, scope ConfigScope, root, active bool, chain []IncludeLink, stack []string) {
+	node := w.node(file, scope, root)
+	if active {
+		node.Active = true
+		if _, ok := w.chains[file]; !ok && !root {
+			w.chains[file] = append([]IncludeLink(nil), chain...)
+		}
+	}
+
+	for _, seen := range stack {
+		if seen == file {
+			return
+		}
+	}
+	if len(stack) > maxIncludeDepth {
+		return
+	}
+	// A file is expanded at most once per activity state: an inactive expansion is redone
+	// when the file turns out to be active through another path.
+	expansion := fmt.Sprintf("%s\x00%t", file, active)
+	if w.expanded[expansion] {
+		return
+	}
+	w.expanded[expansion] = true
+
+	info, err := os.Stat(file)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			node.Error = err.Error()
+		}
+		return
+	}
+	node.Exists = true
+	node.Mode = info.Mode().String()
+	node.Size = info.Size()
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		node.Error = err.Error()
+		return
+	}
+
+	entries, err := parseConfigFile(data)
+	if err != nil {
+		node.Error = err.Error()
+		return
+	}
+
+	stack = append(stack, file)
+	for _, entry := range entries {
+		directive, condition, ok := includeDirective(entry.key)
+		if !ok || entry.noValue {
+			continue
+		}
+		target, err := resolveIncludePath(entry.value, file)
+		if err != nil {
+			continue
+		}
+
+		edge := IncludeEdge{From: file, Line: entry.line, To: target, Directive: directive, Condition: condition}
+		followed := active
+		switch {
+		case !active:
+			edge.Reason = includeReasonNotLoaded
+		case len(stack) > maxIncludeDepth:
+			edge.Reason = includeReasonDepth
+			followed = false
+		case condition != "":
+			if ok, reason := evalIncludeCondition(condition, file, w.ictx); !ok {
+				edge.Reason = reason
+				followed = false
+			}
+		}
+		edge.Active = followed
+		w.addEdge(edge)
+
+		link := IncludeLink{File: file, Line: entry.line, Directive: directive, Condition: condition}
+		w.visit(target, scope, false, followed, append(chain[:len(chain):len(chain)], link), stack)
+	}
+}
+
+func (w *includeWalker) addEdge(edge IncludeEdge) {
+	id := fmt.Sprintf("%s\x00%d\x00%s", edge.From, edge.Line, edge.To)
+	if i, ok := w.edges[id]; ok {
+		if edge.Active && !w.graph.Edges[i].Active {
+			w.graph.Edges[i] = edge
+		}
+		return
+	}
+	w.edges[id] = len(w.graph.Edges)
+	w.graph.Edges = append(w.graph.Edges, edge)
+}
+
+// includeDirective reports whether key is include.path or includeIf.<condition>.path.
+func includeDirective(key ConfigKey) (directive, condition string, ok bool) {
+	if key.Name != "path" {
+		return "", "", false
+	}
+	switch {
+	case key.Section == "include" && key.Subsection == "":
+		return "include.path", "", true
+	case key.Section == "includeif" && key.Subsection != "":
+		return "includeIf." + key.Subsection + ".path", key.Subsection, true
+	default:
+		return "", "", false
+	}
+}
+
+// resolveIncludePath expands ~ and resolves relative include paths against the directory of
+// the including file.
+func resolveIncludePath(value, from string) (string, error) {
+	path, err := expandPath(value)
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(filepath.Dir(from), path)
+	}
+	return filepath.Clean(path), nil
+}
+
+// evalIncludeCondition mirrors include_condition_is_true. A false result comes with a reason.
+func evalIncludeCondition(condition, from string, ictx includeContext) (bool, string) {
+	switch {
+	case strings.HasPrefix(condition, "gitdir:"):
+		return evalGitdirCondition(strings.TrimPrefix(condition, "gitdir:"), from, ictx, false)
+	case strings.HasPrefix(condition, "gitdir/i:"):
+		return evalGitdirCondition(strings.TrimPrefix(condition, "gitdir/i:"), from, ictx, true)
+	case strings.HasPrefix(condition, "onbranch:"):
+		if ictx.branch == "" {
+			return false, "no branch is checked out"
+		}
+		pattern := strings.TrimPrefix(condition, "onbranch:")
+		if strings.HasSuffix(pattern, "/") {
+			pattern += "**"
+		}
+		if wildmatch(pattern, ictx.branch, false) {
+			return true, ""
+		}
+		return false, fmt.Sprintf("branch %q does not match", ictx.branch)
+	case strings.HasPrefix(condition, "hasconfig:remote.*.url:"):
+		pattern := strings.TrimPrefix(condition, "hasconfig:remote.*.url:")
+		for _, remoteURL := range ictx.remoteURLs {
+			if wildmatch(pattern, remoteURL, false) {
+				return true, ""
+			}
+		}
+		return false, "no remote URL matches"
+	default:
+		return false, "unsupported condition"
+	}
+}
+
+func evalGitdirCondition(pattern, from string, ictx includeContext, fold bool) (bool, string) {
+	if ictx.gitDir == "" {
+		return false, "not inside a repository"
+	}
+
+	expanded, err := expandPath(pattern)
+	if err != nil {
+		return false, err.Error()
+	}
+	switch {
+	case strings.HasPrefix(expanded, "./"):
+		expanded = filepath.Dir(from) + expanded[1:]
+	case !filepath.IsAbs(expanded):
+		expanded = "**/" + expanded
+	}
+	if strings.HasSuffix(expanded, "/") {
+		expanded += "**"
+	}
+
+	candidates := []string{ictx.gitDir}
+	if real := resolveExistingPrefix(ictx.gitDir); real != ictx.gitDir {
+		candidates = append(candidates, real)
+	}
+	for _, dir := range candidates {
+		if wildmatch(expanded, filepath.ToSlash(dir), fold) {
+			return true, ""
+		}
+	}
+	return false, fmt.Sprintf("git directory %s does not match", ictx.gitDir)
+}
+
+// resolveExistingPrefix resolves symlinks in the longest existing prefix of path, so that a
+// directory that does not exist yet matches like its future real path would.
+func resolveExistingPrefix(path string) string {
+	var missing []string
+	for dir := path; ; dir = filepath.Dir(dir) {
+		if real, err := filepath.EvalSymlinks(dir); err == nil {
+			return filepath.Join(append([]string{real}, missing...)...)
+		}
+		if parent := filepath.Dir(dir); parent == dir {
+			return path
+		}
+		missing = append([]string{filepath.Base(dir)}, missing...)
+	}
+}
+
+// globalConfigRoots returns the per-user and system files git reads, in order.
+func globalConfigRoots() []includeRoot {
+	var roots []includeRoot
+	if os.Getenv("GIT_CONFIG_NOSYSTEM") == "" {
+		roots = append(roots, includeRoot{file: systemConfigPath(), scope: ConfigScopeSystem})
+	}
+	if path := os.Getenv("GIT_CONFIG_GLOBAL"); path != "" {
+		return append(roots, includeRoot{file: path, scope: ConfigScopeGlobal})
+	}
+	if xdg := xdgConfigPath(); xdg != "" {
+		roots = append(roots, includeRoot{file: xdg, scope: ConfigScopeGlobal})
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		roots = append(roots, includeRoot{file: filepath.Join(home, ".gitconfig"), scope: ConfigScopeGlobal})
+	}
+	return roots
+}
+
+// repositoryIncludeContext describes repo for includeIf evaluation.
+func repositoryIncludeContext(repo Repository, values map[string]ConfigValue) includeContext {
+	ictx := includeContext{gitDir: repo.GitDir}
+	if head, err := os.ReadFile(filepath.Join(repo.GitDir, "HEAD")); err == nil {
+		if ref, ok := strings.CutPrefix(strings.TrimSpace(string(head)), "ref: refs/heads/"); ok {
+			ictx.branch = ref
+		}
+	}
+	ictx.remoteURLs = includeRemoteURLs(values)
+	return ictx
+}
+
+func includeRemoteURLs(values map[string]ConfigValue) []string {
+	var urls []string
+	for _, remote := range configuredRemotes(ConfigMatrix{Entries: values}) {
+		urls = append(urls, remote...)
+	}
+	sort.Strings(urls)
+	return urls
+}
+
+// repositoryIncludeRoots returns every file git reads directly for repo.
+func repositoryIncludeRoots(ctx context.Context, repo Repository, values map[string]ConfigValue) []includeRoot {
+	roots := globalConfigRoots()
+	if local, err := repositoryConfigPath(ctx, repo, "config"); err == nil {
+		roots = append(roots, includeRoot{file: local, scope: ConfigScopeLocal})
+	}
+	if value, ok := values["extensions.worktreeconfig"]; ok && value.Normalized == "true" {
+		if worktree, err := repositoryConfigPath(ctx, repo, "config.worktree"); err == nil {
+			roots = append(roots, includeRoot{file: worktree, scope: ConfigScopeWorktree})
+		}
+	}
+	return roots
+}
+
+// includeChains returns the include chain of every active included file of a repository, or of
+// the user and system scopes when repo is nil.
+func includeChains(ctx context.Context, repo *Repository, values map[string]ConfigValue) map[string][]IncludeLink {
+	if repo == nil {
+		_, chains := walkIncludes(globalConfigRoots(), includeContext{remoteURLs: includeRemoteURLs(values)})
+		return chains
+	}
+	_, chains := walkIncludes(repositoryIncludeRoots(ctx, *repo, values), repositoryIncludeContext(*repo, values))
+	return chains
+}
+
+// annotateIncludeChains attaches the include chain of every value read from an included file.
+// Relative source files, as git reports them for the local config, are resolved against base.
+func annotateIncludeChains(values map[string]ConfigValue, chains map[string][]IncludeLink, base string) {
+	chainFor := func(source ConfigSource) []IncludeLink {
+		file := source.File
+		if file == "" {
+			return nil
+		}
+		if !filepath.IsAbs(file) && base != "" {
+			file = filepath.Join(base, file)
+		}
+		return chains[filepath.Clean(file)]
+	}
+
+	for key, value := range values {
+		value.Source.IncludeChain = chainFor(value.Source)
+		for i := range value.Overrides {
+			value.Overrides[i].Source.IncludeChain = chainFor(value.Overrides[i].Source)
+		}
+		values[key] = value
+	}
+}
+
+// GetIncludeGraph returns the config files of a repository, or of the user and system scopes
+// for GlobalRepositoryID, together with the include directives connecting them.
+func (s *Service) GetIncludeGraph(ctx context.Context, repositoryID string) (IncludeGraph, error) {
+	select {
+	case <-ctx.Done():
+		return IncludeGraph{}, ctx.Err()
+	default:
+	}
+
+	graph, _, err := s.includeGraph(ctx, repositoryID)
+	return graph, err
+}
+
+func (s *Service) includeGraph(ctx context.Context, repositoryID string) (IncludeGraph, map[string][]IncludeLink, error) {
+	if repositoryID == GlobalRepositoryID {
+		values, err := readGlobalConfig(ctx)
+		if err != nil {
+			return IncludeGraph{}, nil, err
+		}
+		graph, chains := walkIncludes(globalConfigRoots(), includeContext{remoteURLs: includeRemoteURLs(values)})
+		graph.RepositoryID = repositoryID
+		return graph, chains, nil
+	}
+
+	s.mu.RLock()
+	repo, ok := s.repositories[repositoryID]
+	s.mu.RUnlock()
+	if !ok {
+		return IncludeGraph{}, nil, fmt.Errorf("repository %q not found", repositoryID)
+	}
+	values, err := readGitConfig(ctx, repo.Path)
+	if err != nil {
+		return IncludeGraph{}, nil, err
+	}
+	graph, chains := walkIncludes(repositoryIncludeRoots(ctx, repo, values), repositoryIncludeContext(repo, values))
+	graph.RepositoryID = repositoryID
+	return graph, chains, nil
+}
@@ -0,0 +1,154 @@
+package gitcfg
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWildmatch(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		pattern string
+		text    string
+		fold    bool
+		want    bool
+	}{
+		{pattern: "/home/me/work/**", text: "/home/me/work/api/.git", want: true},
+		{pattern: "**/work/**", text: "/home/me/work/api/.git", want: true},
+		{pattern: "**/.git", text: "/srv/api/.git", want: true},
+		{pattern: "/home/*/.git", text: "/home/me/api/.git", want: false},
+		{pattern: "/home/*/.git", text: "/home/me/.git", want: true},
+		{pattern: "feature/**", text: "feature/x/y", want: true},
+		{pattern: "release-?", text: "release-2", want: true},
+		{pattern: "release-[0-9]", text: "release-a", want: false},
+		{pattern: "release-[!0-9]", text: "release-a", want: true},
+		{pattern: "v[[:digit:]]", text: "v7", want: true},
+		{pattern: "/Work/**", text: "/work/api/.git", fold: true, want: true},
+		{pattern: "/Work/**", text: "/work/api/.git", want: false},
+		{pattern: "https://github.com/acme/**", text: "https://github.com/acme/api.git", want: true},
+		{pattern: "*@github.com:acme/*", text: "git@github.com:acme/api.git", want: true},
+	}
+	for _, tt := range tests {
+		if got := wildmatch(tt.pattern, tt.text, tt.fold); got != tt.want {
+			t.Fatalf("wildmatch(%q, %q, %t) = %t, want %t", tt.pattern, tt.text, tt.fold, got, tt.want)
+		}
+	}
+}
+
+func TestEvalIncludeCondition(t *testing.T) {
+	t.Parallel()
+
+	ictx := includeContext{
+		gitDir:     "/srv/work/api/.git",
+		branch:     "feature/login",
+		remoteURLs: []string{"git@github.com:acme/api.git"},
+	}
+	tests := []struct {
+		condition string
+		want      bool
+	}{
+		{condition: "gitdir:/srv/work/", want: true},
+		{condition: "gitdir:work/", want: true},
+		{condition: "gitdir:/srv/personal/", want: false},
+		{condition: "gitdir/i:/SRV/WORK/", want: true},
+		{condition: "gitdir:/SRV/WORK/", want: false},
+		{condition: "gitdir:./work/", want: true},
+		{condition: "onbranch:feature/", want: true},
+		{condition: "onbranch:main", want: false},
+		{condition: "hasconfig:remote.*.url:git@github.com:acme/**", want: true},
+		{condition: "hasconfig:remote.*.url:https://gitlab.com/**", want: false},
+		{condition: "unknown:x", want: false},
+	}
+	for _, tt := range tests {
+		got, reason := evalIncludeCondition(tt.condition, "/srv/.gitconfig", ictx)
+		if got != tt.want {
+			t.Fatalf("evalIncludeCondition(%q) = %t (%s), want %t", tt.condition, got, reason, tt.want)
+		}
+		if !got && reason == "" {
+			t.Fatalf("expected a reason for %q", tt.condition)
+		}
+	}
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
+
+func TestWalkIncludes(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	root := filepath.Join(dir, "gitconfig")
+	work := filepath.Join(dir, "work.gitconfig")
+	signing := filepath.Join(dir, "signing.gitconfig")
+	personal := filepath.Join(dir, "personal.gitconfig")
+	writeTestFile(t, root, "[user]\n\tname = Alice\n"+
+		"[includeIf \"gitdir:/srv/work/\"]\n\tpath = work.gitconfig\n"+
+		"[includeIf \"gitdir:/srv/personal/\"]\n\tpath = personal.gitconfig\n")
+	writeTestFile(t, work, "[include]\n\tpath = signing.gitconfig\n")
+	writeTestFile(t, signing, "[commit]\n\tgpgsign = true\n[include]\n\tpath = missing.gitconfig\n")
+	writeTestFile(t, personal, "[include]\n\tpath = personal.gitconfig\n")
+
+	graph, chains := walkIncludes([]includeRoot{{file: root, scope: ConfigScopeGlobal}}, includeContext{gitDir: "/srv/work/api/.git"})
+	if len(graph.Nodes) != 5 || len(graph.Edges) != 5 {
+		t.Fatalf("expected 5 nodes and 5 edges, got %+v", graph)
+	}
+
+	chain := chains[signing]
+	if len(chain) != 2 || chain[0].File != root || chain[0].Line != 4 || chain[0].Condition != "gitdir:/srv/work/" || chain[1].File != work || chain[1].Directive != "include.path" {
+		t.Fatalf("unexpected chain for signing file: %+v", chain)
+	}
+	if _, ok := chains[personal]; ok {
+		t.Fatal("expected no chain for an inactive include")
+	}
+
+	for _, edge := range graph.Edges {
+		if edge.To == personal && edge.From == root && (edge.Active || edge.Reason == "") {
+			t.Fatalf("expected the personal include to be inactive with a reason, got %+v", edge)
+		}
+	}
+	for _, node := range graph.Nodes {
+		if node.File == filepath.Join(dir, "missing.gitconfig") && (node.Exists || !node.Active) {
+			t.Fatalf("expected the missing file to be active but absent, got %+v", node)
+		}
+	}
+}
+
+func TestIncludeChainInMatrix(t *testing.T) {
+	s := newTestService(t)
+	ctx := context.Background()
+	home := t.TempDir()
+	global := filepath.Join(home, "gitconfig")
+	t.Setenv("GIT_CONFIG_GLOBAL", global)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	repo := newTestRepository(t, s)
+
+	writeTestFile(t, filepath.Join(home, "identity.gitconfig"), "[user]\n\temail = alice@example.com\n")
+	writeTestFile(t, global, "[includeIf \"gitdir:"+repo.GitDir+"\"]\n\tpath = identity.gitconfig\n")
+
+	matrix, err := s.loadMatrix(ctx, repo.ID, true)
+	if err != nil {
+		t.Fatalf("loadMatrix returned error: %v", err)
+	}
+	value, ok := matrix.Lookup("user.email")
+	if !ok {
+		t.Fatal("expected user.email from the included file")
+	}
+	if chain := value.Source.IncludeChain; len(chain) != 1 || chain[0].File != global || chain[0].Line != 2 {
+		t.Fatalf("unexpected include chain: %+v", chain)
+	}
+
+	graph, err := s.GetIncludeGraph(ctx, repo.ID)
+	if err != nil {
+		t.Fatalf("GetIncludeGraph returned error: %v", err)
+	}
+	if graph.RepositoryID != repo.ID || len(graph.Edges) != 1 || !graph.Edges[0].Active {
+		t.Fatalf("unexpected include graph: %+v", graph)
+	}
+}
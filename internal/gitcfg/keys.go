@@ -0,0 +1,142 @@
+package gitcfg
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ConfigKey is the canonical identity of a git configuration variable.
+//
+// Section and variable names are case-insensitive and stored lower-cased, while
+// subsections are case-sensitive and kept verbatim, mirroring git's own rules.
+type ConfigKey struct {
+	Section    string `json:"section"`
+	Subsection string `json:"subsection,omitempty"`
+	Name       string `json:"name"`
+}
+
+// ParseKey parses a dotted key such as "url.https://example.com/.insteadOf" into its canonical form.
+func ParseKey(raw string) (ConfigKey, error) {
+	first := strings.Index(raw, ".")
+	last := strings.LastIndex(raw, ".")
+	if first <= 0 || last == len(raw)-1 {
+		return ConfigKey{}, fmt.Errorf("key %q does not contain a section and a variable name", raw)
+	}
+
+	key := ConfigKey{
+		Section: strings.ToLower(raw[:first]),
+		Name:    strings.ToLower(raw[last+1:]),
+	}
+	if first != last {
+		key.Subsection = raw[first+1 : last]
+	}
+
+	if err := key.validate(); err != nil {
+		return ConfigKey{}, fmt.Errorf("invalid key %q: %w", raw, err)
+	}
+	return key, nil
+}
+
+// CanonicalKey returns the canonical spelling of raw, or raw unchanged when it cannot be parsed.
+func CanonicalKey(raw string) string {
+	key, err := ParseKey(raw)
+	if err != nil {
+		return raw
+	}
+	return key.String()
+}
+
+// String renders the key in the dotted form printed by `git config --list`.
+func (k ConfigKey) String() string {
+	if k.Subsection == "" {
+		return k.Section + "." + k.Name
+	}
+	return k.Section + "." + k.Subsection + "." + k.Name
+}
+
+// SectionName renders the section part of the key, including the subsection when present.
+func (k ConfigKey) SectionName() string {
+	if k.Subsection == "" {
+		return k.Section
+	}
+	return k.Section + "." + k.Subsection
+}
+
+func (k ConfigKey) validate() error {
+	if k.Section == "" {
+		return errors.New("section cannot be empty")
+	}
+	for _, r := range k.Section {
+		if !isKeyChar(r) {
+			return fmt.Errorf("section contains invalid character %q", r)
+		}
+	}
+
+	if strings.ContainsAny(k.Subsection, "\n\x00") {
+		return errors.New("subsection cannot contain newlines")
+	}
+
+	if k.Name == "" {
+		return errors.New("variable name cannot be empty")
+	}
+	if c := k.Name[0]; c < 'a' || c > 'z' {
+		return errors.New("variable name must start with a letter")
+	}
+	for _, r := range k.Name {
+		if !isKeyChar(r) {
+			return fmt.Errorf("variable name contains invalid character %q", r)
+		}
+	}
+	return nil
+}
+
+func isKeyChar(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '-'
+}
+
+// parseSectionHeader parses the body of a config file section header, i.e. the text between
+// the square brackets. Both `[section "Sub"]` and the deprecated `[section.Sub]` forms are
+// supported; as in git, legacy subsections are case-insensitive and therefore lower-cased.
+func parseSectionHeader(header string) (section, subsection string, err error) {
+	header = strings.TrimSpace(header)
+
+	if quote := strings.IndexByte(header, '"'); quote != -1 {
+		section = strings.TrimSpace(header[:quote])
+		rest := header[quote+1:]
+		if !strings.HasSuffix(rest, `"`) {
+			return "", "", fmt.Errorf("unterminated subsection in header %q", header)
+		}
+		rest = rest[:len(rest)-1]
+
+		var b strings.Builder
+		for i := 0; i < len(rest); i++ {
+			c := rest[i]
+			if c == '\\' && i+1 < len(rest) {
+				i++
+				c = rest[i]
+			} else if c == '"' {
+				return "", "", fmt.Errorf("unexpected quote in header %q", header)
+			}
+			b.WriteByte(c)
+		}
+		subsection = b.String()
+	} else if dot := strings.IndexByte(header, '.'); dot != -1 {
+		section = header[:dot]
+		subsection = strings.ToLower(header[dot+1:])
+	} else {
+		section = header
+	}
+
+	key := ConfigKey{Section: strings.ToLower(section), Subsection: subsection, Name: "x"}
+	if err := key.validate(); err != nil {
+		return "", "", fmt.Errorf("invalid section header %q: %w", header, err)
+	}
+	return key.Section, key.Subsection, nil
+}
+
+// Lookup returns the entry for key, matching it by canonical identity rather than spelling.
+func (m ConfigMatrix) Lookup(key string) (ConfigValue, bool) {
+	value, ok := m.Entries[CanonicalKey(key)]
+	return value, ok
+}
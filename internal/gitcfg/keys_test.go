@@ -0,0 +1,85 @@
+package gitcfg
+
+import "testing"
+
+func TestParseKey(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{name: "simple", input: "Core.AutoCRLF", want: "core.autocrlf"},
+		{name: "subsection keeps case", input: "URL.Https://X.InsteadOf", want: "url.Https://X.insteadof"},
+		{name: "subsection with dots", input: "remote.origin.backup.url", want: "remote.origin.backup.url"},
+		{name: "missing name", input: "core.", wantErr: true},
+		{name: "missing section", input: ".name", wantErr: true},
+		{name: "no dot", input: "core", wantErr: true},
+		{name: "name starts with digit", input: "core.1abc", wantErr: true},
+		{name: "invalid section char", input: "co_re.name", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := ParseKey(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseKey(%q) expected error, got %q", tt.input, key.String())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseKey(%q) returned error: %v", tt.input, err)
+			}
+			if got := key.String(); got != tt.want {
+				t.Fatalf("ParseKey(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanonicalKeySubsectionIdentity(t *testing.T) {
+	if CanonicalKey("url.Https://X.insteadOf") == CanonicalKey("url.https://x.insteadOf") {
+		t.Fatalf("differently cased subsections must not collide")
+	}
+	if CanonicalKey("URL.Https://X.INSTEADOF") != CanonicalKey("url.Https://X.insteadof") {
+		t.Fatalf("section and variable names must compare case-insensitively")
+	}
+}
+
+func TestParseSectionHeader(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		input          string
+		wantSection    string
+		wantSubsection string
+		wantErr        bool
+	}{
+		{input: "Core", wantSection: "core"},
+		{input: `remote "Origin"`, wantSection: "remote", wantSubsection: "Origin"},
+		{input: `url "https://x/\"q\""`, wantSection: "url", wantSubsection: `https://x/"q"`},
+		{input: "Branch.Main", wantSection: "branch", wantSubsection: "main"},
+		{input: `remote "unterminated`, wantErr: true},
+		{input: "bad_name", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		section, subsection, err := parseSectionHeader(tt.input)
+		if tt.wantErr {
+			if err == nil {
+				t.Fatalf("parseSectionHeader(%q) expected error", tt.input)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("parseSectionHeader(%q) returned error: %v", tt.input, err)
+		}
+		if section != tt.wantSection || subsection != tt.wantSubsection {
+			t.Fatalf("parseSectionHeader(%q) = (%q, %q), want (%q, %q)", tt.input, section, subsection, tt.wantSection, tt.wantSubsection)
+		}
+	}
+}
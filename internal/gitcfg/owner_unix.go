@@ -0,0 +1,16 @@
+//go:build !windows
+
+package gitcfg
+
+import (
+	"os"
+	"syscall"
+)
+
+// preserveOwner gives file the owner and group recorded in info. Only privileged processes
+// may change the owner, so a failure leaves the file owned by the current user, as git does.
+func preserveOwner(file *os.File, info os.FileInfo) {
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		_ = file.Chown(int(stat.Uid), int(stat.Gid))
+	}
+}
@@ -0,0 +1,6 @@
+package gitcfg
+
+import "os"
+
+// preserveOwner is a no-op on Windows, where files inherit the permissions of their directory.
+func preserveOwner(*os.File, os.FileInfo) {}
@@ -0,0 +1,549 @@
+package gitcfg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Remote is a remote.<name> section of a repository configuration.
+type Remote struct {
+	Name     string   `json:"name"`
+	URLs     []string `json:"urls"`
+	PushURLs []string `json:"pushUrls,omitempty"`
+	Fetch    []string `json:"fetch,omitempty"`
+	Push     []string `json:"push,omitempty"`
+}
+
+// RemoteRequest adds, renames, removes or re-points a remote of a tracked repository. The
+// repository's local config file is edited.
+type RemoteRequest struct {
+	RepositoryID string `json:"repositoryId"`
+	Name         string `json:"name"`
+	// NewName is the target name for RenameRemote.
+	NewName string `json:"newName,omitempty"`
+	// URL is the fetch URL for AddRemote and the new URL for SetRemoteURL.
+	URL string `json:"url,omitempty"`
+	// Push makes SetRemoteURL change the push URL instead of the fetch URL.
+	Push   bool `json:"push,omitempty"`
+	DryRun bool `json:"dryRun"`
+}
+
+// HostMigrationRequest rewrites remote URLs pointing at From to point at To across every
+// tracked repository. From and To are a host optionally followed by path components, such as
+// "github.com/old-org".
+type HostMigrationRequest struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	DryRun bool   `json:"dryRun"`
+}
+
+// HostMigrationSkip names a repository whose remotes could not be read for a migration.
+type HostMigrationSkip struct {
+	RepositoryID   string `json:"repositoryId"`
+	RepositoryName string `json:"repositoryName"`
+	Error          string `json:"error"`
+}
+
+// HostMigrationResult holds the change sets of a migration, one per config file, and the
+// repositories it skipped.
+type HostMigrationResult struct {
+	ChangeSets []ChangeSet         `json:"changeSets"`
+	Skipped    []HostMigrationSkip `json:"skipped"`
+}
+
+// remotesFromMatrix groups remote.<name>.* entries into remotes sorted by name.
+func remotesFromMatrix(matrix ConfigMatrix) []Remote {
+	byName := make(map[string]*Remote)
+	for raw, value := range matrix.Entries {
+		key, err := ParseKey(raw)
+		if err != nil || key.Section != "remote" || key.Subsection == "" {
+			continue
+		}
+		remote, ok := byName[key.Subsection]
+		if !ok {
+			remote = &Remote{Name: key.Subsection, URLs: []string{}}
+			byName[key.Subsection] = remote
+		}
+
+		var target *[]string
+		switch key.Name {
+		case "url":
+			target = &remote.URLs
+		case "pushurl":
+			target = &remote.PushURLs
+		case "fetch":
+			target = &remote.Fetch
+		case "push":
+			target = &remote.Push
+		default:
+			continue
+		}
+		for _, entry := range valuesInOrder(value) {
+			*target = append(*target, entry.Value)
+		}
+	}
+
+	remotes := make([]Remote, 0, len(byName))
+	for _, remote := range byName {
+		remotes = append(remotes, *remote)
+	}
+	sort.Slice(remotes, func(i, j int) bool {
+		return remotes[i].Name < remotes[j].Name
+	})
+	return remotes
+}
+
+func redactRemote(remote Remote) Remote {
+	redactAll := func(values []string) []string {
+		out := make([]string, len(values))
+		for i, value := range values {
+			out[i], _ = redactValue(value)
+		}
+		return out
+	}
+	remote.URLs = redactAll(remote.URLs)
+	if remote.PushURLs != nil {
+		remote.PushURLs = redactAll(remote.PushURLs)
+	}
+	return remote
+}
+
+// readRemotes reads the remotes of the repository at path for Repository.Remotes.
+func readRemotes(ctx context.Context, path string) ([]Remote, error) {
+	values, err := readGitConfig(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	remotes := remotesFromMatrix(ConfigMatrix{Entries: values})
+	for i := range remotes {
+		remotes[i] = redactRemote(remotes[i])
+	}
+	return remotes, nil
+}
+
+// validateRemoteName applies git's rule that refs/remotes/<name>/... must be a valid ref.
+func validateRemoteName(ctx context.Context, name string) error {
+	if name == "" {
+		return errors.New("remote name cannot be empty")
+	}
+	if err := exec.CommandContext(ctx, "git", "check-ref-format", "refs/remotes/"+name+"/test").Run(); err != nil {
+		return fmt.Errorf("%q is not a valid remote name", name)
+	}
+	return nil
+}
+
+func defaultFetchRefspec(name string) string {
+	return "+refs/heads/*:refs/remotes/" + name + "/*"
+}
+
+// ListRemotes returns the remotes of a repository with credentials redacted.
+func (s *Service) ListRemotes(ctx context.Context, repositoryID string) ([]Remote, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	matrix, err := s.loadMatrix(ctx, repositoryID, false)
+	if err != nil {
+		return nil, err
+	}
+	remotes := remotesFromMatrix(matrix)
+	for i := range remotes {
+		remotes[i] = redactRemote(remotes[i])
+	}
+	return remotes, nil
+}
+
+// localEdit prepares an edit of the local config file of a repository and returns the
+// repository matrix for validation.
+func (s *Service) localEdit(ctx context.Context, repositoryID string) (ConfigMatrix, configEdit, error) {
+	s.mu.RLock()
+	repo, ok := s.repositories[repositoryID]
+	s.mu.RUnlock()
+	if !ok {
+		return ConfigMatrix{}, configEdit{}, fmt.Errorf("repository %q not found", repositoryID)
+	}
+
+	matrix, err := s.loadMatrix(ctx, repositoryID, true)
+	if err != nil {
+		return ConfigMatrix{}, configEdit{}, err
+	}
+	path, err := resolveTargetFile(ctx, &repo, ConfigScopeLocal, "")
+	if err != nil {
+		return ConfigMatrix{}, configEdit{}, err
+	}
+	return matrix, configEdit{path: path}, nil
+}
+
+func findRemote(matrix ConfigMatrix, name string) (Remote, bool) {
+	for _, remote := range remotesFromMatrix(matrix) {
+		if remote.Name == name {
+			return remote, true
+		}
+	}
+	return Remote{}, false
+}
+
+// applyRemoteEdit applies edit and refreshes the remotes stored on the repository.
+func (s *Service) applyRemoteEdit(ctx context.Context, req RemoteRequest, key string, edit configEdit) (ChangeSet, error) {
+	cs, err := s.applyEdit(ctx, ChangeSet{
+		RepositoryID: req.RepositoryID,
+		Scope:        ConfigScopeLocal,
+		Key:          key,
+		Operation:    WriteOperationSet,
+	}, edit, req.DryRun)
+	if err != nil || req.DryRun {
+		return cs, err
+	}
+	s.refreshRemotes(ctx, req.RepositoryID)
+	return cs, nil
+}
+
+func (s *Service) refreshRemotes(ctx context.Context, repositoryID string) {
+	s.mu.RLock()
+	repo, ok := s.repositories[repositoryID]
+	s.mu.RUnlock()
+	if !ok {
+		return
+	}
+	remotes, err := readRemotes(ctx, repo.Path)
+
+	s.mu.Lock()
+	if current, ok := s.repositories[repositoryID]; ok {
+		if err != nil {
+			current.RemotesError = fmt.Sprintf("read remotes: %v", err)
+		} else {
+			current.Remotes, current.RemotesError = remotes, ""
+		}
+		s.repositories[repositoryID] = current
+	}
+	s.mu.Unlock()
+}
+
+// AddRemote defines a new remote with the default fetch refspec, like `git remote add`.
+func (s *Service) AddRemote(ctx context.Context, req RemoteRequest) (ChangeSet, error) {
+	if err := validateRemoteName(ctx, req.Name); err != nil {
+		return ChangeSet{}, err
+	}
+	if req.URL == "" {
+		return ChangeSet{}, errors.New("remote url cannot be empty")
+	}
+
+	matrix, edit, err := s.localEdit(ctx, req.RepositoryID)
+	if err != nil {
+		return ChangeSet{}, err
+	}
+	if _, exists := findRemote(matrix, req.Name); exists {
+		return ChangeSet{}, fmt.Errorf("remote %q already exists", req.Name)
+	}
+
+	edit.ops = [][]string{
+		{"remote." + req.Name + ".url", req.URL},
+		{"--add", "remote." + req.Name + ".fetch", defaultFetchRefspec(req.Name)},
+	}
+	return s.applyRemoteEdit(ctx, req, "remote."+req.Name+".url", edit)
+}
+
+// SetRemoteURL replaces every fetch or push URL of a remote with req.URL.
+func (s *Service) SetRemoteURL(ctx context.Context, req RemoteRequest) (ChangeSet, error) {
+	if req.URL == "" {
+		return ChangeSet{}, errors.New("remote url cannot be empty")
+	}
+
+	matrix, edit, err := s.localEdit(ctx, req.RepositoryID)
+	if err != nil {
+		return ChangeSet{}, err
+	}
+	if _, exists := findRemote(matrix, req.Name); !exists {
+		return ChangeSet{}, fmt.Errorf("remote %q not found", req.Name)
+	}
+
+	key := "remote." + req.Name + ".url"
+	if req.Push {
+		key = "remote." + req.Name + ".pushurl"
+	}
+	edit.ops = [][]string{{"--replace-all", key, req.URL}}
+	return s.applyRemoteEdit(ctx, req, key, edit)
+}
+
+// RenameRemote renames a remote section and updates the fetch refspecs, branch.<name>.remote
+// and remote.pushDefault settings that refer to it. Remote-tracking refs are not touched; the
+// next fetch recreates them under the new name.
+func (s *Service) RenameRemote(ctx context.Context, req RemoteRequest) (ChangeSet, error) {
+	if err := validateRemoteName(ctx, req.NewName); err != nil {
+		return ChangeSet{}, err
+	}
+
+	matrix, edit, err := s.localEdit(ctx, req.RepositoryID)
+	if err != nil {
+		return ChangeSet{}, err
+	}
+	remote, exists := findRemote(matrix, req.Name)
+	if !exists {
+		return ChangeSet{}, fmt.Errorf("remote %q not found", req.Name)
+	}
+	if _, taken := findRemote(matrix, req.NewName); taken {
+		return ChangeSet{}, fmt.Errorf("remote %q already exists", req.NewName)
+	}
+
+	edit.ops = append(edit.ops, []string{"--rename-section", "remote." + req.Name, "remote." + req.NewName})
+	oldPrefix := "refs/remotes/" + req.Name + "/"
+	for _, refspec := range remote.Fetch {
+		if strings.Contains(refspec, oldPrefix) {
+			renamed := strings.ReplaceAll(refspec, oldPrefix, "refs/remotes/"+req.NewName+"/")
+			edit.ops = append(edit.ops, []string{"--fixed-value", "--replace-all", "remote." + req.NewName + ".fetch", renamed, refspec})
+		}
+	}
+	edit.ops = append(edit.ops, remoteReferenceOps(matrix, req.Name, func(key string) []string {
+		return []string{key, req.NewName}
+	})...)
+
+	return s.applyRemoteEdit(ctx, req, "remote."+req.NewName, edit)
+}
+
+// RemoveRemote deletes a remote section and the branch and pushDefault settings referring to
+// it, like `git remote remove`.
+func (s *Service) RemoveRemote(ctx context.Context, req RemoteRequest) (ChangeSet, error) {
+	matrix, edit, err := s.localEdit(ctx, req.RepositoryID)
+	if err != nil {
+		return ChangeSet{}, err
+	}
+	if _, exists := findRemote(matrix, req.Name); !exists {
+		return ChangeSet{}, fmt.Errorf("remote %q not found", req.Name)
+	}
+
+	edit.ops = append(edit.ops, []string{"--remove-section", "remote." + req.Name})
+	edit.ops = append(edit.ops, remoteReferenceOps(matrix, req.Name, func(key string) []string {
+		if strings.HasSuffix(key, ".remote") {
+			return []string{"--unset-all", key}
+		}
+		return []string{"--unset", key}
+	})...)
+	for raw, value := range matrix.Entries {
+		key, err := ParseKey(raw)
+		if err != nil || key.Section != "branch" || key.Name != "merge" || value.Source.Scope != ConfigScopeLocal {
+			continue
+		}
+		if remoteValue, ok := matrix.Lookup("branch." + key.Subsection + ".remote"); ok && remoteValue.Value == req.Name && remoteValue.Source.Scope == ConfigScopeLocal {
+			edit.ops = append(edit.ops, []string{"--unset-all", key.String()})
+		}
+	}
+
+	return s.applyRemoteEdit(ctx, req, "remote."+req.Name, edit)
+}
+
+// remoteReferenceOps returns an op, built by op, for every local branch.<name>.remote,
+// branch.<name>.pushRemote and remote.pushDefault entry naming remote.
+func remoteReferenceOps(matrix ConfigMatrix, remote string, op func(key string) []string) [][]string {
+	var keys []string
+	for raw, value := range matrix.Entries {
+		if value.Value != remote || value.Source.Scope != ConfigScopeLocal {
+			continue
+		}
+		key, err := ParseKey(raw)
+		if err != nil {
+			continue
+		}
+		switch {
+		case key.Section == "branch" && key.Subsection != "" && (key.Name == "remote" || key.Name == "pushremote"):
+		case key.Section == "remote" && key.Subsection == "" && key.Name == "pushdefault":
+		default:
+			continue
+		}
+		keys = append(keys, key.String())
+	}
+	sort.Strings(keys)
+
+	ops := make([][]string, 0, len(keys))
+	for _, key := range keys {
+		ops = append(ops, op(key))
+	}
+	return ops
+}
+
+// migrateURL moves raw from one host location to another, keeping the transport, user and
+// the rest of the path. from and to are "host[/path]" locations matched on path components.
+// Both URL syntax and scp-like "user@host:path" addresses are supported.
+func migrateURL(raw, from, to string) (string, bool) {
+	from = strings.Trim(from, "/")
+	to = strings.Trim(to, "/")
+	// Hosts are compared case-insensitively, paths are not.
+	fromHost, fromPath, hasPath := strings.Cut(from, "/")
+	from = strings.ToLower(fromHost)
+	if hasPath {
+		from += "/" + fromPath
+	}
+	relocate := func(location string) (string, string, bool) {
+		rest, ok := strings.CutPrefix(location, from)
+		if !ok || (rest != "" && !strings.HasPrefix(rest, "/")) {
+			return "", "", false
+		}
+		host, path, _ := strings.Cut(to+rest, "/")
+		return host, path, true
+	}
+
+	if !strings.Contains(raw, "://") {
+		colon := strings.Index(raw, ":")
+		if colon < 0 || strings.Contains(raw[:colon], "/") {
+			return raw, false
+		}
+		user, host := "", raw[:colon]
+		if at := strings.LastIndex(host, "@"); at >= 0 {
+			user, host = host[:at+1], host[at+1:]
+		}
+		newHost, newPath, ok := relocate(strings.ToLower(host) + "/" + strings.TrimPrefix(raw[colon+1:], "/"))
+		if !ok {
+			return raw, false
+		}
+		return user + newHost + ":" + newPath, true
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return raw, false
+	}
+	newHost, newPath, ok := relocate(strings.ToLower(u.Host) + u.Path)
+	if !ok {
+		return raw, false
+	}
+	u.Host = newHost
+	u.Path = "/" + newPath
+	u.RawPath = ""
+	return u.String(), true
+}
+
+// MigrateRemoteHost rewrites every locally configured remote URL and push URL matching
+// req.From across all tracked repositories. One change set is returned per modified config
+// file, so worktrees sharing one are migrated once, and all of them share a BatchID so the
+// migration is undone as one step; with DryRun nothing is written. Repositories whose
+// configuration cannot be read are skipped and reported.
+func (s *Service) MigrateRemoteHost(ctx context.Context, req HostMigrationRequest) (HostMigrationResult, error) {
+	select {
+	case <-ctx.Done():
+		return HostMigrationResult{}, ctx.Err()
+	default:
+	}
+
+	if strings.Trim(req.From, "/") == "" || strings.Trim(req.To, "/") == "" {
+		return HostMigrationResult{}, errors.New("source and target locations cannot be empty")
+	}
+
+	s.mu.RLock()
+	ids := make([]string, 0, len(s.repositories))
+	names := make(map[string]string, len(s.repositories))
+	for id, repo := range s.repositories {
+		if repo.Status != RepoStatusDubiousOwnership {
+			ids = append(ids, id)
+			names[id] = repo.Name
+		}
+	}
+	s.mu.RUnlock()
+	sort.Strings(ids)
+
+	type pending struct {
+		repositoryID string
+		edit         configEdit
+	}
+	var edits []pending
+	result := HostMigrationResult{ChangeSets: []ChangeSet{}, Skipped: []HostMigrationSkip{}}
+	seen := make(map[string]bool)
+	fileOf := make(map[string]string)
+	for _, id := range ids {
+		matrix, edit, err := s.localEdit(ctx, id)
+		if err != nil {
+			if ctx.Err() != nil {
+				return HostMigrationResult{}, ctx.Err()
+			}
+			result.Skipped = append(result.Skipped, HostMigrationSkip{RepositoryID: id, RepositoryName: names[id], Error: err.Error()})
+			continue
+		}
+		// Worktrees share the config file of their main repository.
+		file := resolveExistingPrefix(filepath.Clean(edit.path))
+		fileOf[id] = file
+		if seen[file] {
+			continue
+		}
+		seen[file] = true
+		for raw, value := range matrix.Entries {
+			key, err := ParseKey(raw)
+			if err != nil || key.Section != "remote" || key.Subsection == "" || (key.Name != "url" && key.Name != "pushurl") {
+				continue
+			}
+			for _, entry := range valuesInOrder(value) {
+				if entry.Source.Scope != ConfigScopeLocal {
+					continue
+				}
+				if migrated, ok := migrateURL(entry.Value, req.From, req.To); ok {
+					edit.ops = append(edit.ops, []string{"--fixed-value", "--replace-all", raw, migrated, entry.Value})
+				}
+			}
+		}
+		if len(edit.ops) > 0 {
+			sort.Slice(edit.ops, func(i, j int) bool {
+				return strings.Join(edit.ops[i], "\x00") < strings.Join(edit.ops[j], "\x00")
+			})
+			edits = append(edits, pending{repositoryID: id, edit: edit})
+		}
+	}
+
+	if req.DryRun {
+		for _, item := range edits {
+			cs, err := s.applyRemoteEdit(ctx, RemoteRequest{RepositoryID: item.repositoryID, DryRun: true}, "remote.*.url", item.edit)
+			if err != nil {
+				return HostMigrationResult{}, fmt.Errorf("migrate remotes of %q: %w", item.repositoryID, err)
+			}
+			result.ChangeSets = append(result.ChangeSets, cs)
+		}
+		return result, nil
+	}
+
+	// The repositories are migrated as one batch: all config files are locked first, and if
+	// writing one fails the ones already written are restored.
+	sort.Slice(edits, func(i, j int) bool { return edits[i].edit.path < edits[j].edit.path })
+	batchID := uuid.NewString()
+	changeSets := make([]ChangeSet, 0, len(edits))
+	files := make([]fileChange, 0, len(edits))
+	for _, item := range edits {
+		lock, err := lockFile(item.edit.path)
+		if err != nil {
+			return HostMigrationResult{}, err
+		}
+		defer lock.release()
+		before, after, err := previewEdit(ctx, item.edit)
+		if err != nil {
+			return HostMigrationResult{}, fmt.Errorf("migrate remotes of %q: %w", item.repositoryID, err)
+		}
+		files = append(files, fileChange{path: item.edit.path, before: before, after: after, existed: true, lock: lock})
+		changeSets = append(changeSets, ChangeSet{
+			RepositoryID: item.repositoryID,
+			Scope:        ConfigScopeLocal,
+			Key:          "remote.*.url",
+			Operation:    WriteOperationSet,
+			BatchID:      batchID,
+		})
+	}
+	changes, err := s.recordBatch(changeSets, files)
+	if err != nil {
+		return HostMigrationResult{}, err
+	}
+	migrated := make(map[string]bool, len(edits))
+	for _, item := range edits {
+		migrated[fileOf[item.repositoryID]] = true
+	}
+	for _, id := range ids {
+		if migrated[fileOf[id]] {
+			s.refreshRemotes(ctx, id)
+		}
+	}
+	result.ChangeSets = changes
+	return result, nil
+}
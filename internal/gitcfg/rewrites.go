@@ -0,0 +1,326 @@
+package gitcfg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// URLRewrite is a single url.<base>.insteadOf or url.<base>.pushInsteadOf entry.
+type URLRewrite struct {
+	// Base is the replacement URL prefix, i.e. the subsection of the url section.
+	Base string `json:"base"`
+	// Prefix is the URL prefix that gets replaced by Base.
+	Prefix string       `json:"prefix"`
+	Push   bool         `json:"push"`
+	Source ConfigSource `json:"source"`
+}
+
+// URLRewriteRequest adds or removes a rewrite rule in the file selected by Scope or TargetPath.
+type URLRewriteRequest struct {
+	RepositoryID string      `json:"repositoryId,omitempty"`
+	Scope        ConfigScope `json:"scope"`
+	TargetPath   string      `json:"targetPath,omitempty"`
+	Base         string      `json:"base"`
+	Prefix       string      `json:"prefix"`
+	Push         bool        `json:"push"`
+	DryRun       bool        `json:"dryRun"`
+}
+
+// RewriteSimulation shows how git rewrites a URL for fetching and pushing.
+type RewriteSimulation struct {
+	Input string `json:"input"`
+	// Remote is set when Input named a configured remote rather than a URL.
+	Remote    string      `json:"remote,omitempty"`
+	FetchURLs []string    `json:"fetchUrls"`
+	PushURLs  []string    `json:"pushUrls"`
+	FetchRule *URLRewrite `json:"fetchRule,omitempty"`
+	PushRule  *URLRewrite `json:"pushRule,omitempty"`
+}
+
+func (r URLRewriteRequest) key() string {
+	name := "insteadOf"
+	if r.Push {
+		name = "pushInsteadOf"
+	}
+	return "url." + r.Base + "." + name
+}
+
+// collectURLRewrites extracts every rewrite rule from matrix, including overridden values.
+func collectURLRewrites(matrix ConfigMatrix) []URLRewrite {
+	var rewrites []URLRewrite
+	for key, value := range matrix.Entries {
+		parsed, err := ParseKey(key)
+		if err != nil || parsed.Section != "url" || parsed.Subsection == "" {
+			continue
+		}
+		var push bool
+		switch parsed.Name {
+		case "insteadof":
+		case "pushinsteadof":
+			push = true
+		default:
+			continue
+		}
+		for _, entry := range valuesInOrder(value) {
+			rewrites = append(rewrites, URLRewrite{
+				Base:   parsed.Subsection,
+				Prefix: entry.Value,
+				Push:   push,
+				Source: entry.Source,
+			})
+		}
+	}
+
+	// git keeps one rule list per base in the order the bases first appear and scans them in
+	// that order, so a tie between equally long prefixes goes to the base configured first.
+	type group struct {
+		push bool
+		base string
+	}
+	first := make(map[group]int)
+	for _, rule := range rewrites {
+		g := group{rule.Push, rule.Base}
+		if order, ok := first[g]; !ok || rule.Source.Order < order {
+			first[g] = rule.Source.Order
+		}
+	}
+	sort.SliceStable(rewrites, func(i, j int) bool {
+		a, b := rewrites[i], rewrites[j]
+		if fa, fb := first[group{a.Push, a.Base}], first[group{b.Push, b.Base}]; fa != fb {
+			return fa < fb
+		}
+		return a.Source.Order < b.Source.Order
+	})
+	return rewrites
+}
+
+// rewriteURL applies git's longest-prefix rule: among all rules of the requested kind whose
+// prefix matches url, the longest prefix wins and the first one in rewrites wins a tie.
+// collectURLRewrites orders rules so that this matches git's config order.
+func rewriteURL(url string, rewrites []URLRewrite, push bool) (string, *URLRewrite) {
+	var best *URLRewrite
+	for i := range rewrites {
+		rule := &rewrites[i]
+		if rule.Push != push || rule.Prefix == "" || !strings.HasPrefix(url, rule.Prefix) {
+			continue
+		}
+		if best == nil || len(rule.Prefix) > len(best.Prefix) {
+			best = rule
+		}
+	}
+	if best == nil {
+		return url, nil
+	}
+	rule := *best
+	return rule.Base + url[len(rule.Prefix):], &rule
+}
+
+// simulateRewrite mirrors remote.c: fetch URLs and explicit push URLs go through insteadOf,
+// while remotes without a pushurl derive push URLs from their plain URL via pushInsteadOf,
+// falling back to the fetch URL when no push rule matches.
+func simulateRewrite(urls, pushURLs []string, rewrites []URLRewrite) RewriteSimulation {
+	sim := RewriteSimulation{FetchURLs: []string{}, PushURLs: []string{}}
+
+	for _, url := range urls {
+		rewritten, rule := rewriteURL(url, rewrites, false)
+		sim.FetchURLs = append(sim.FetchURLs, rewritten)
+		if sim.FetchRule == nil {
+			sim.FetchRule = rule
+		}
+	}
+
+	if len(pushURLs) > 0 {
+		for _, url := range pushURLs {
+			rewritten, rule := rewriteURL(url, rewrites, false)
+			sim.PushURLs = append(sim.PushURLs, rewritten)
+			if sim.PushRule == nil {
+				sim.PushRule = rule
+			}
+		}
+		return sim
+	}
+
+	for i, url := range urls {
+		rewritten, rule := rewriteURL(url, rewrites, true)
+		if rule == nil {
+			sim.PushURLs = append(sim.PushURLs, sim.FetchURLs[i])
+			continue
+		}
+		sim.PushURLs = append(sim.PushURLs, rewritten)
+		if sim.PushRule == nil {
+			sim.PushRule = rule
+		}
+	}
+	if sim.PushRule == nil {
+		sim.PushRule = sim.FetchRule
+	}
+	return sim
+}
+
+// ListURLRewrites returns the rewrite rules visible to a repository across all scopes, or the
+// global ones when repositoryID is GlobalRepositoryID. Credentials in bases are redacted.
+func (s *Service) ListURLRewrites(ctx context.Context, repositoryID string) ([]URLRewrite, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	matrix, err := s.matrixFor(ctx, repositoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	rewrites := collectURLRewrites(matrix)
+	for i := range rewrites {
+		rewrites[i] = redactURLRewrite(rewrites[i])
+	}
+	return rewrites, nil
+}
+
+// AddURLRewrite appends a rewrite rule, refusing exact duplicates in the target file.
+func (s *Service) AddURLRewrite(ctx context.Context, req URLRewriteRequest) (ChangeSet, error) {
+	if err := validateRewriteRequest(req); err != nil {
+		return ChangeSet{}, err
+	}
+
+	if req.RepositoryID != "" || req.Scope == ConfigScopeGlobal {
+		repositoryID := req.RepositoryID
+		if repositoryID == "" {
+			repositoryID = GlobalRepositoryID
+		}
+		matrix, err := s.matrixFor(ctx, repositoryID)
+		if err != nil {
+			return ChangeSet{}, err
+		}
+		for _, existing := range collectURLRewrites(matrix) {
+			if existing.Base == req.Base && existing.Prefix == req.Prefix && existing.Push == req.Push && existing.Source.Scope == req.Scope {
+				return ChangeSet{}, fmt.Errorf("rewrite of %q already exists in %s", req.Prefix, existing.Source.File)
+			}
+		}
+	}
+
+	return s.WriteConfig(ctx, WriteRequest{
+		RepositoryID: req.RepositoryID,
+		Scope:        req.Scope,
+		Key:          req.key(),
+		Value:        req.Prefix,
+		Operation:    WriteOperationAdd,
+		TargetPath:   req.TargetPath,
+		DryRun:       req.DryRun,
+	})
+}
+
+// RemoveURLRewrite removes exactly one rewrite rule, leaving other prefixes of the same base
+// intact. Redacted bases as returned by ListURLRewrites are resolved to the stored rule.
+func (s *Service) RemoveURLRewrite(ctx context.Context, req URLRewriteRequest) (ChangeSet, error) {
+	if err := validateRewriteRequest(req); err != nil {
+		return ChangeSet{}, err
+	}
+
+	if strings.Contains(req.Base, redactedMarker) || strings.Contains(req.Prefix, redactedMarker) {
+		repositoryID := req.RepositoryID
+		if repositoryID == "" {
+			repositoryID = GlobalRepositoryID
+		}
+		matrix, err := s.matrixFor(ctx, repositoryID)
+		if err != nil {
+			return ChangeSet{}, err
+		}
+		for _, existing := range collectURLRewrites(matrix) {
+			redacted := redactURLRewrite(existing)
+			if redacted.Base == req.Base && redacted.Prefix == req.Prefix && existing.Push == req.Push {
+				req.Base, req.Prefix = existing.Base, existing.Prefix
+				break
+			}
+		}
+	}
+
+	return s.WriteConfig(ctx, WriteRequest{
+		RepositoryID: req.RepositoryID,
+		Scope:        req.Scope,
+		Key:          req.key(),
+		Operation:    WriteOperationUnset,
+		MatchValue:   req.Prefix,
+		TargetPath:   req.TargetPath,
+		DryRun:       req.DryRun,
+	})
+}
+
+// SimulateURLRewrite shows the fetch and push URLs git would use for input, which is either a
+// URL or the name of a remote configured in the repository.
+func (s *Service) SimulateURLRewrite(ctx context.Context, repositoryID, input string) (RewriteSimulation, error) {
+	select {
+	case <-ctx.Done():
+		return RewriteSimulation{}, ctx.Err()
+	default:
+	}
+
+	if input == "" {
+		return RewriteSimulation{}, errors.New("url cannot be empty")
+	}
+
+	matrix, err := s.matrixFor(ctx, repositoryID)
+	if err != nil {
+		return RewriteSimulation{}, err
+	}
+
+	urls := []string{input}
+	var pushURLs []string
+	var remote string
+	if value, ok := matrix.Lookup("remote." + input + ".url"); ok {
+		remote = input
+		urls = urls[:0]
+		for _, entry := range valuesInOrder(value) {
+			urls = append(urls, entry.Value)
+		}
+		if value, ok := matrix.Lookup("remote." + input + ".pushurl"); ok {
+			for _, entry := range valuesInOrder(value) {
+				pushURLs = append(pushURLs, entry.Value)
+			}
+		}
+	}
+
+	sim := simulateRewrite(urls, pushURLs, collectURLRewrites(matrix))
+	sim.Input = input
+	sim.Remote = remote
+	return redactRewriteSimulation(sim), nil
+}
+
+func validateRewriteRequest(req URLRewriteRequest) error {
+	if req.Base == "" {
+		return errors.New("rewrite base cannot be empty")
+	}
+	if req.Prefix == "" {
+		return errors.New("rewrite prefix cannot be empty")
+	}
+	return nil
+}
+
+func redactURLRewrite(rule URLRewrite) URLRewrite {
+	rule.Base, _ = redactValue(rule.Base)
+	rule.Prefix, _ = redactValue(rule.Prefix)
+	return rule
+}
+
+func redactRewriteSimulation(sim RewriteSimulation) RewriteSimulation {
+	sim.Input, _ = redactValue(sim.Input)
+	for i := range sim.FetchURLs {
+		sim.FetchURLs[i], _ = redactValue(sim.FetchURLs[i])
+	}
+	for i := range sim.PushURLs {
+		sim.PushURLs[i], _ = redactValue(sim.PushURLs[i])
+	}
+	if sim.FetchRule != nil {
+		rule := redactURLRewrite(*sim.FetchRule)
+		sim.FetchRule = &rule
+	}
+	if sim.PushRule != nil {
+		rule := redactURLRewrite(*sim.PushRule)
+		sim.PushRule = &rule
+	}
+	return sim
+}
@@ -0,0 +1,67 @@
+package gitcfg
+
+import "testing"
+
+func TestSimulateRewriteLongestPrefixWins(t *testing.T) {
+	rewrites := []URLRewrite{
+		{Base: "git@mirror:", Prefix: "https://github.com/"},
+		{Base: "git@mirror:team/", Prefix: "https://github.com/team/"},
+		{Base: "ssh://push-gw/", Prefix: "https://github.com/", Push: true},
+	}
+
+	sim := simulateRewrite([]string{"https://github.com/team/api.git"}, nil, rewrites)
+
+	if got, want := sim.FetchURLs[0], "git@mirror:team/api.git"; got != want {
+		t.Fatalf("fetch url = %q, want %q", got, want)
+	}
+	if sim.FetchRule == nil || sim.FetchRule.Prefix != "https://github.com/team/" {
+		t.Fatalf("expected the longest prefix to win, got %+v", sim.FetchRule)
+	}
+	if got, want := sim.PushURLs[0], "ssh://push-gw/team/api.git"; got != want {
+		t.Fatalf("push url = %q, want %q", got, want)
+	}
+	if sim.PushRule == nil || !sim.PushRule.Push {
+		t.Fatalf("expected pushInsteadOf rule to be reported, got %+v", sim.PushRule)
+	}
+}
+
+func TestSimulateRewritePushFallsBackToFetch(t *testing.T) {
+	rewrites := []URLRewrite{
+		{Base: "git@mirror:", Prefix: "https://github.com/"},
+	}
+
+	sim := simulateRewrite([]string{"https://github.com/org/repo.git"}, nil, rewrites)
+	if got, want := sim.PushURLs[0], "git@mirror:org/repo.git"; got != want {
+		t.Fatalf("push url = %q, want %q", got, want)
+	}
+}
+
+func TestSimulateRewriteExplicitPushURL(t *testing.T) {
+	rewrites := []URLRewrite{
+		{Base: "git@mirror:", Prefix: "https://github.com/"},
+		{Base: "ssh://push-gw/", Prefix: "https://github.com/", Push: true},
+	}
+
+	sim := simulateRewrite(
+		[]string{"https://github.com/org/repo.git"},
+		[]string{"https://github.com/org/repo-push.git"},
+		rewrites,
+	)
+	if got, want := sim.PushURLs[0], "git@mirror:org/repo-push.git"; got != want {
+		t.Fatalf("explicit push urls only use insteadOf: got %q, want %q", got, want)
+	}
+}
+
+func TestCollectURLRewritesKeepsConfigOrder(t *testing.T) {
+	matrix := ConfigMatrix{Entries: buildConfigValues([]gitConfigEntry{
+		{key: "url.zz:.insteadof", value: "https://x/", scope: ConfigScopeGlobal, order: 0},
+		{key: "url.aa:.insteadof", value: "https://x/", scope: ConfigScopeGlobal, order: 1},
+		{key: "url.aa:.insteadof", value: "https://y/", scope: ConfigScopeLocal, order: 2},
+	})}
+
+	// git scans the bases in the order they first appear, so zz: wins the tie.
+	sim := simulateRewrite([]string{"https://x/repo.git"}, nil, collectURLRewrites(matrix))
+	if got, want := sim.FetchURLs[0], "zz:repo.git"; got != want {
+		t.Fatalf("fetch url = %q, want %q", got, want)
+	}
+}
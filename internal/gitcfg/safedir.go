@@ -0,0 +1,196 @@
+package gitcfg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// SafeDirectory is one safe.directory entry of the global configuration.
+type SafeDirectory struct {
+	Path   string       `json:"path"`
+	Source ConfigSource `json:"source"`
+	// Stale is set when the entry points at a directory that no longer exists.
+	Stale bool `json:"stale"`
+	// Note explains entries with special meaning, such as "*" or the empty reset value.
+	Note string `json:"note,omitempty"`
+}
+
+// dubiousOwnershipError reports that git refused to open a repository owned by another user.
+type dubiousOwnershipError struct {
+	path string
+}
+
+func (e *dubiousOwnershipError) Error() string {
+	return fmt.Sprintf("detected dubious ownership in repository at %q", e.path)
+}
+
+var dubiousOwnershipPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`detected dubious ownership in repository at '([^']*)'`),
+	// git 2.35.2 to 2.35.x used a different wording.
+	regexp.MustCompile(`unsafe repository \('([^']*)' is owned by someone else\)`),
+}
+
+// parseDubiousOwnership extracts the repository path from git's dubious ownership failure,
+// returning nil when stderr reports a different problem.
+func parseDubiousOwnership(stderr string) *dubiousOwnershipError {
+	for _, pattern := range dubiousOwnershipPatterns {
+		if match := pattern.FindStringSubmatch(stderr); match != nil {
+			return &dubiousOwnershipError{path: match[1]}
+		}
+	}
+	return nil
+}
+
+// dubiousRepository describes a repository git refused to open, so that it can still be listed
+// and trusted from the UI.
+func dubiousRepository(root string, dubious *dubiousOwnershipError) Repository {
+	path := dubious.path
+	if path == "" {
+		path = root
+	}
+	return Repository{
+		ID:            ensureID(path),
+		Name:          filepath.Base(path),
+		Path:          path,
+		Root:          root,
+		Type:          RepositoryTypeStandard,
+		LastScanTime:  timestamp(time.Now()),
+		Status:        RepoStatusDubiousOwnership,
+		SafeDirectory: path,
+	}
+}
+
+// safeDirectoryStale reports whether a safe.directory value names a path that no longer exists.
+// The wildcard, the empty reset value and "<dir>/*" prefixes whose directory exists are kept.
+func safeDirectoryStale(value string) bool {
+	if value == "" || value == "*" {
+		return false
+	}
+	path, err := expandPath(strings.TrimSuffix(value, "/*"))
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(path)
+	return errors.Is(err, os.ErrNotExist)
+}
+
+func collectSafeDirectories(matrix ConfigMatrix) []SafeDirectory {
+	value, ok := matrix.Lookup("safe.directory")
+	if !ok {
+		return []SafeDirectory{}
+	}
+
+	entries := valuesInOrder(value)
+	dirs := make([]SafeDirectory, 0, len(entries))
+	for _, entry := range entries {
+		dir := SafeDirectory{Path: entry.Value, Source: entry.Source, Stale: safeDirectoryStale(entry.Value)}
+		switch entry.Value {
+		case "":
+			dir.Note = "resets the list of safe directories defined before it"
+		case "*":
+			dir.Note = "disables the ownership check for every repository"
+		}
+		dirs = append(dirs, dir)
+	}
+	return dirs
+}
+
+// ListSafeDirectories returns the safe.directory entries of the global configuration, flagging
+// those that point at missing directories.
+func (s *Service) ListSafeDirectories(ctx context.Context) ([]SafeDirectory, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	matrix, err := s.loadGlobalMatrix(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return collectSafeDirectories(matrix), nil
+}
+
+// TrustRepository adds the safe.directory entry git asked for when it refused to open a
+// repository because of dubious ownership.
+func (s *Service) TrustRepository(ctx context.Context, repositoryID string, dryRun bool) (ChangeSet, error) {
+	select {
+	case <-ctx.Done():
+		return ChangeSet{}, ctx.Err()
+	default:
+	}
+
+	s.mu.RLock()
+	repo, ok := s.repositories[repositoryID]
+	s.mu.RUnlock()
+	if !ok {
+		return ChangeSet{}, fmt.Errorf("repository %q not found", repositoryID)
+	}
+	if repo.Status != RepoStatusDubiousOwnership || repo.SafeDirectory == "" {
+		return ChangeSet{}, fmt.Errorf("repository %q is not blocked by the ownership check", repo.Name)
+	}
+
+	existing, err := s.ListSafeDirectories(ctx)
+	if err != nil {
+		return ChangeSet{}, err
+	}
+	for _, dir := range existing {
+		if dir.Path == repo.SafeDirectory {
+			return ChangeSet{}, fmt.Errorf("%s is already listed in safe.directory", repo.SafeDirectory)
+		}
+	}
+
+	return s.WriteConfig(ctx, WriteRequest{
+		RepositoryID: repositoryID,
+		Scope:        ConfigScopeGlobal,
+		Key:          "safe.directory",
+		Value:        repo.SafeDirectory,
+		Operation:    WriteOperationAdd,
+		DryRun:       dryRun,
+	})
+}
+
+// PruneSafeDirectories removes every stale safe.directory entry from the global config file.
+// Entries coming from included files are reported by ListSafeDirectories but left untouched.
+func (s *Service) PruneSafeDirectories(ctx context.Context, dryRun bool) (ChangeSet, error) {
+	select {
+	case <-ctx.Done():
+		return ChangeSet{}, ctx.Err()
+	default:
+	}
+
+	path, err := globalConfigPath()
+	if err != nil {
+		return ChangeSet{}, err
+	}
+	dirs, err := s.ListSafeDirectories(ctx)
+	if err != nil {
+		return ChangeSet{}, err
+	}
+
+	var ops [][]string
+	seen := make(map[string]bool)
+	for _, dir := range dirs {
+		if !dir.Stale || seen[dir.Path] || !sameFile(dir.Source.File, path) {
+			continue
+		}
+		seen[dir.Path] = true
+		ops = append(ops, []string{"--fixed-value", "--unset-all", "safe.directory", dir.Path})
+	}
+	if len(ops) == 0 {
+		return ChangeSet{}, errors.New("no stale safe.directory entries found")
+	}
+
+	return s.applyEdit(ctx, ChangeSet{
+		RepositoryID: GlobalRepositoryID,
+		Scope:        ConfigScopeGlobal,
+		Key:          "safe.directory",
+		Operation:    WriteOperationUnsetAll,
+	}, configEdit{path: path, ops: ops}, dryRun)
+}
@@ -0,0 +1,135 @@
+package gitcfg
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseDubiousOwnership(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		stderr string
+		want   string
+		ok     bool
+	}{
+		{
+			stderr: "fatal: detected dubious ownership in repository at '/srv/repo'\nTo add an exception for this directory, call:\n\n\tgit config --global --add safe.directory /srv/repo\n",
+			want:   "/srv/repo",
+			ok:     true,
+		},
+		{
+			stderr: "fatal: unsafe repository ('/srv/old' is owned by someone else)\n",
+			want:   "/srv/old",
+			ok:     true,
+		},
+		{stderr: "fatal: not a git repository (or any of the parent directories): .git\n"},
+	}
+
+	for _, tt := range tests {
+		got := parseDubiousOwnership(tt.stderr)
+		if (got != nil) != tt.ok {
+			t.Fatalf("parseDubiousOwnership(%q) = %v, want ok=%v", tt.stderr, got, tt.ok)
+		}
+		if got != nil && got.path != tt.want {
+			t.Fatalf("parseDubiousOwnership(%q) path = %q, want %q", tt.stderr, got.path, tt.want)
+		}
+	}
+}
+
+func TestPruneSafeDirectories(t *testing.T) {
+	s := newTestService(t)
+	ctx := context.Background()
+
+	existing := t.TempDir()
+	missing := filepath.Join(t.TempDir(), "gone")
+	global := filepath.Join(t.TempDir(), "gitconfig")
+	content := "[safe]\n\tdirectory = " + existing + "\n\tdirectory = " + missing + "\n\tdirectory = *\n"
+	if err := os.WriteFile(global, []byte(content), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	t.Setenv("GIT_CONFIG_GLOBAL", global)
+
+	dirs, err := s.ListSafeDirectories(ctx)
+	if err != nil {
+		t.Fatalf("ListSafeDirectories returned error: %v", err)
+	}
+	if len(dirs) != 3 || dirs[0].Stale || !dirs[1].Stale || dirs[2].Stale || dirs[2].Note == "" {
+		t.Fatalf("unexpected safe directories: %+v", dirs)
+	}
+
+	cs, err := s.PruneSafeDirectories(ctx, false)
+	if err != nil {
+		t.Fatalf("PruneSafeDirectories returned error: %v", err)
+	}
+	if !strings.Contains(cs.Diff, "-\tdirectory = "+missing) {
+		t.Fatalf("expected diff to remove the stale entry, got:\n%s", cs.Diff)
+	}
+
+	data, err := os.ReadFile(global)
+	if err != nil {
+		t.Fatalf("read config: %v", err)
+	}
+	if strings.Contains(string(data), missing) || !strings.Contains(string(data), existing) {
+		t.Fatalf("expected only the stale entry to be pruned, got:\n%s", data)
+	}
+
+	if _, err := s.PruneSafeDirectories(ctx, false); err == nil {
+		t.Fatal("expected an error when nothing is left to prune")
+	}
+}
+
+func TestSafeDirectoriesWithoutGlobalConfig(t *testing.T) {
+	s := newTestService(t)
+	ctx := context.Background()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	t.Setenv("GIT_CONFIG_GLOBAL", "")
+	os.Unsetenv("GIT_CONFIG_GLOBAL")
+
+	dirs, err := s.ListSafeDirectories(ctx)
+	if err != nil || len(dirs) != 0 {
+		t.Fatalf("expected no safe directories without a global config, got %+v (%v)", dirs, err)
+	}
+	if _, err := s.AnalyzeIncludes(ctx); err != nil {
+		t.Fatalf("AnalyzeIncludes returned error: %v", err)
+	}
+
+	repo := dubiousRepository(home, &dubiousOwnershipError{path: filepath.Join(home, "repo")})
+	s.repositories[repo.ID] = repo
+	if _, err := s.TrustRepository(ctx, repo.ID, false); err != nil {
+		t.Fatalf("TrustRepository returned error: %v", err)
+	}
+	if got := gitConfigGet(t, home, "--file", filepath.Join(home, ".gitconfig"), "safe.directory"); got != repo.SafeDirectory {
+		t.Fatalf("expected %s to be trusted, got %q", repo.SafeDirectory, got)
+	}
+}
+
+func TestPruneSafeDirectoriesThroughSymlink(t *testing.T) {
+	s := newTestService(t)
+	ctx := context.Background()
+
+	dotfiles, home := t.TempDir(), t.TempDir()
+	missing := filepath.Join(t.TempDir(), "gone")
+	real := filepath.Join(dotfiles, "gitconfig")
+	if err := os.WriteFile(real, []byte("[safe]\n\tdirectory = "+missing+"\n"), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if err := os.Symlink(real, filepath.Join(home, ".gitconfig")); err != nil {
+		t.Skipf("symlinks are not available: %v", err)
+	}
+	t.Setenv("HOME", home)
+	t.Setenv("GIT_CONFIG_GLOBAL", "")
+	os.Unsetenv("GIT_CONFIG_GLOBAL")
+
+	if _, err := s.PruneSafeDirectories(ctx, false); err != nil {
+		t.Fatalf("PruneSafeDirectories returned error: %v", err)
+	}
+	if data, _ := os.ReadFile(real); strings.Contains(string(data), missing) {
+		t.Fatalf("expected the stale entry to be pruned, got:\n%s", data)
+	}
+}
@@ -0,0 +1,293 @@
+package gitcfg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// matrixCacheTTL bounds how long a cached matrix is reused even when none of its source
+// files changed, so that newly created include targets are eventually noticed.
+const matrixCacheTTL = 30 * time.Second
+
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// cachedMatrix is a configuration matrix together with the state of the files it was read from.
+type cachedMatrix struct {
+	matrix    ConfigMatrix
+	fetchedAt time.Time
+	files     map[string]fileStamp
+}
+
+// newCachedMatrix records the matrix along with the state of its source files; relative
+// origins reported by git are resolved against baseDir.
+func newCachedMatrix(matrix ConfigMatrix, baseDir string, now time.Time) cachedMatrix {
+	files := make(map[string]fileStamp)
+	track := func(source ConfigSource) {
+		if source.File == "" {
+			return
+		}
+		path := source.File
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(baseDir, path)
+		}
+		files[path] = statFile(path)
+	}
+	for _, value := range matrix.Entries {
+		track(value.Source)
+		for _, override := range value.Overrides {
+			track(override.Source)
+		}
+	}
+
+	return cachedMatrix{matrix: matrix, fetchedAt: now, files: files}
+}
+
+func (c cachedMatrix) fresh(now time.Time) bool {
+	if now.Sub(c.fetchedAt) > matrixCacheTTL {
+		return false
+	}
+	for path, stamp := range c.files {
+		if statFile(path) != stamp {
+			return false
+		}
+	}
+	return true
+}
+
+func statFile(path string) fileStamp {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileStamp{}
+	}
+	return fileStamp{modTime: info.ModTime(), size: info.Size()}
+}
+
+// SearchQuery filters configuration entries across tracked repositories. Empty fields match
+// everything.
+type SearchQuery struct {
+	// KeyPattern is a glob such as "remote.*.url", or a regular expression when KeyRegex is set.
+	KeyPattern string `json:"keyPattern,omitempty"`
+	KeyRegex   bool   `json:"keyRegex,omitempty"`
+	// ValuePattern is a regular expression matched against the raw value.
+	ValuePattern string        `json:"valuePattern,omitempty"`
+	Scopes       []ConfigScope `json:"scopes,omitempty"`
+	// IncludeOverrides also reports values shadowed by a higher priority scope.
+	IncludeOverrides bool `json:"includeOverrides,omitempty"`
+	ForceRefresh     bool `json:"forceRefresh,omitempty"`
+}
+
+// SearchResult is a single configuration value matching a SearchQuery.
+type SearchResult struct {
+	RepositoryID   string       `json:"repositoryId"`
+	RepositoryName string       `json:"repositoryName"`
+	Key            string       `json:"key"`
+	Value          string       `json:"value"`
+	Source         ConfigSource `json:"source"`
+	Overridden     bool         `json:"overridden"`
+}
+
+// SearchSkip names a repository whose configuration could not be read during a search.
+type SearchSkip struct {
+	RepositoryID   string `json:"repositoryId"`
+	RepositoryName string `json:"repositoryName"`
+	Error          string `json:"error"`
+}
+
+// SearchResponse holds the matches of a search and the repositories it had to skip, such as
+// those git refuses to open because of dubious ownership.
+type SearchResponse struct {
+	Results []SearchResult `json:"results"`
+	Skipped []SearchSkip   `json:"skipped"`
+}
+
+type searchMatcher struct {
+	key    *regexp.Regexp
+	value  *regexp.Regexp
+	scopes []ConfigScope
+}
+
+func compileSearchQuery(query SearchQuery) (searchMatcher, error) {
+	var m searchMatcher
+
+	if query.KeyPattern != "" {
+		pattern := query.KeyPattern
+		if !query.KeyRegex {
+			pattern = globToRegexp(pattern)
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return searchMatcher{}, fmt.Errorf("invalid key pattern: %w", err)
+		}
+		m.key = re
+	}
+
+	if query.ValuePattern != "" {
+		re, err := regexp.Compile(query.ValuePattern)
+		if err != nil {
+			return searchMatcher{}, fmt.Errorf("invalid value pattern: %w", err)
+		}
+		m.value = re
+	}
+
+	m.scopes = query.Scopes
+	return m, nil
+}
+
+// globToRegexp converts a key glob into an anchored regular expression. Like git, it folds
+// case in the section and variable name, the parts before the first and after the last dot,
+// but not in the subsection between them. Unlike path.Match, "*" also matches "/" and "." so
+// that URL subsections can be matched.
+func globToRegexp(glob string) string {
+	convert := func(part string) string {
+		var b strings.Builder
+		for _, r := range part {
+			switch r {
+			case '*':
+				b.WriteString(".*")
+			case '?':
+				b.WriteString(".")
+			default:
+				b.WriteString(regexp.QuoteMeta(string(r)))
+			}
+		}
+		return b.String()
+	}
+
+	first, last := strings.Index(glob, "."), strings.LastIndex(glob, ".")
+	if first < 0 {
+		return "^(?i:" + convert(glob) + ")$"
+	}
+	return "^(?i:" + convert(glob[:first]) + ")" + convert(glob[first:last+1]) + "(?i:" + convert(glob[last+1:]) + ")$"
+}
+
+func (m searchMatcher) matches(key, value string, source ConfigSource) bool {
+	if m.key != nil && !m.key.MatchString(key) {
+		return false
+	}
+	if m.value != nil && !m.value.MatchString(value) {
+		return false
+	}
+	if len(m.scopes) > 0 && !slices.Contains(m.scopes, source.Scope) {
+		return false
+	}
+	return true
+}
+
+func searchMatrix(repo Repository, matrix ConfigMatrix, m searchMatcher, includeOverrides bool) []SearchResult {
+	var results []SearchResult
+	for key, value := range matrix.Entries {
+		if m.matches(key, value.Value, value.Source) {
+			results = append(results, SearchResult{
+				RepositoryID:   repo.ID,
+				RepositoryName: repo.Name,
+				Key:            key,
+				Value:          value.Value,
+				Source:         value.Source,
+			})
+		}
+		if !includeOverrides {
+			continue
+		}
+		for _, override := range value.Overrides {
+			if m.matches(key, override.Value, override.Source) {
+				results = append(results, SearchResult{
+					RepositoryID:   repo.ID,
+					RepositoryName: repo.Name,
+					Key:            key,
+					Value:          override.Value,
+					Source:         override.Source,
+					Overridden:     true,
+				})
+			}
+		}
+	}
+	return results
+}
+
+// Search looks for configuration entries matching query in every scanned repository. Matrices
+// are loaded concurrently and cached copies are reused while their source files are unchanged.
+// Patterns are matched against the raw values, but results are redacted. Repositories whose
+// configuration cannot be read are skipped and reported rather than failing the search.
+func (s *Service) Search(ctx context.Context, query SearchQuery) (SearchResponse, error) {
+	select {
+	case <-ctx.Done():
+		return SearchResponse{}, ctx.Err()
+	default:
+	}
+
+	if query.KeyPattern == "" && query.ValuePattern == "" && len(query.Scopes) == 0 {
+		return SearchResponse{}, errors.New("search query cannot be empty")
+	}
+
+	matcher, err := compileSearchQuery(query)
+	if err != nil {
+		return SearchResponse{}, err
+	}
+
+	s.mu.RLock()
+	repos := make([]Repository, 0, len(s.repositories))
+	for _, repo := range s.repositories {
+		repos = append(repos, repo)
+	}
+	s.mu.RUnlock()
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	response := SearchResponse{Results: []SearchResult{}, Skipped: []SearchSkip{}}
+	sem := make(chan struct{}, runtime.NumCPU())
+
+	for _, repo := range repos {
+		wg.Add(1)
+		go func(repo Repository) {
+			defer wg.Done()
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			matrix, err := s.loadMatrix(ctx, repo.ID, query.ForceRefresh)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				response.Skipped = append(response.Skipped, SearchSkip{RepositoryID: repo.ID, RepositoryName: repo.Name, Error: err.Error()})
+				return
+			}
+			for _, result := range searchMatrix(repo, matrix, matcher, query.IncludeOverrides) {
+				result.Key, _ = redactValue(result.Key)
+				result.Value, _ = redactValue(result.Value)
+				response.Results = append(response.Results, result)
+			}
+		}(repo)
+	}
+	wg.Wait()
+
+	results := response.Results
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].RepositoryName != results[j].RepositoryName {
+			return results[i].RepositoryName < results[j].RepositoryName
+		}
+		if results[i].Key != results[j].Key {
+			return results[i].Key < results[j].Key
+		}
+		return !results[i].Overridden && results[j].Overridden
+	})
+	sort.Slice(response.Skipped, func(i, j int) bool {
+		return response.Skipped[i].RepositoryName < response.Skipped[j].RepositoryName
+	})
+	return response, nil
+}
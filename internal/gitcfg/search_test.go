@@ -0,0 +1,107 @@
+package gitcfg
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSearchMatrix(t *testing.T) {
+	repo := Repository{ID: "repo-1", Name: "api"}
+	matrix := ConfigMatrix{
+		RepositoryID: repo.ID,
+		Entries: map[string]ConfigValue{
+			"remote.origin.url": {
+				Key:    "remote.origin.url",
+				Value:  "https://gitlab.old.example.com/team/api.git",
+				Source: ConfigSource{Scope: ConfigScopeLocal},
+			},
+			"branch.main.remote": {
+				Key:    "branch.main.remote",
+				Value:  "origin",
+				Source: ConfigSource{Scope: ConfigScopeLocal},
+			},
+			"http.sslverify": {
+				Key:    "http.sslverify",
+				Value:  "false",
+				Source: ConfigSource{Scope: ConfigScopeLocal},
+				Overrides: []ConfigOverride{
+					{Value: "true", Source: ConfigSource{Scope: ConfigScopeGlobal}},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name  string
+		query SearchQuery
+		want  int
+	}{
+		{name: "key glob", query: SearchQuery{KeyPattern: "remote.*.URL"}, want: 1},
+		{name: "value regex", query: SearchQuery{ValuePattern: `gitlab\.old`}, want: 1},
+		{name: "folded section and name", query: SearchQuery{KeyPattern: "BRANCH.main.Remote"}, want: 1},
+		{name: "case-sensitive subsection", query: SearchQuery{KeyPattern: "branch.Main.*"}, want: 0},
+		{name: "key regex", query: SearchQuery{KeyPattern: `^http\.`, KeyRegex: true}, want: 1},
+		{name: "scope filter", query: SearchQuery{KeyPattern: "http.sslverify", Scopes: []ConfigScope{ConfigScopeGlobal}}, want: 0},
+		{name: "scope filter with overrides", query: SearchQuery{KeyPattern: "http.sslverify", Scopes: []ConfigScope{ConfigScopeGlobal}, IncludeOverrides: true}, want: 1},
+	}
+
+	for _, tt := range tests {
+		matcher, err := compileSearchQuery(tt.query)
+		if err != nil {
+			t.Fatalf("%s: compileSearchQuery returned error: %v", tt.name, err)
+		}
+		if got := len(searchMatrix(repo, matrix, matcher, tt.query.IncludeOverrides)); got != tt.want {
+			t.Fatalf("%s: expected %d results, got %d", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestCachedMatrixFreshness(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config")
+	if err := os.WriteFile(path, []byte("[core]\n\tbare = false\n"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	now := time.Now()
+	matrix := ConfigMatrix{Entries: map[string]ConfigValue{
+		"core.bare": {Key: "core.bare", Source: ConfigSource{Scope: ConfigScopeLocal, File: "config"}},
+	}}
+	cached := newCachedMatrix(matrix, dir, now)
+
+	if !cached.fresh(now) {
+		t.Fatalf("expected freshly cached matrix to be fresh")
+	}
+	if cached.fresh(now.Add(2 * matrixCacheTTL)) {
+		t.Fatalf("expected matrix to expire after the TTL")
+	}
+
+	if err := os.WriteFile(path, []byte("[core]\n\tbare = true\n\tfilemode = true\n"), 0o644); err != nil {
+		t.Fatalf("rewrite config: %v", err)
+	}
+	if cached.fresh(now) {
+		t.Fatalf("expected matrix to be stale after its source file changed")
+	}
+}
+
+func TestSearchSkipsUnreadableRepositories(t *testing.T) {
+	s := newTestService(t)
+	repo := newTestRepository(t, s)
+	runGit(t, repo.Path, "config", "user.name", "Alice")
+	broken := Repository{ID: "broken", Name: "broken", Path: filepath.Join(t.TempDir(), "gone")}
+	s.repositories[broken.ID] = broken
+
+	response, err := s.Search(context.Background(), SearchQuery{KeyPattern: "user.name", Scopes: []ConfigScope{ConfigScopeLocal}})
+	if err != nil {
+		t.Fatalf("Search returned error: %v", err)
+	}
+	if len(response.Results) != 1 || response.Results[0].RepositoryID != repo.ID {
+		t.Fatalf("expected the readable repository to be searched, got %+v", response.Results)
+	}
+	if len(response.Skipped) != 1 || response.Skipped[0].RepositoryID != broken.ID || response.Skipped[0].Error == "" {
+		t.Fatalf("expected the broken repository to be reported, got %+v", response.Skipped)
+	}
+}
@@ -0,0 +1,206 @@
+package gitcfg
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// redactedMarker replaces secret material in values that leave the service.
+const redactedMarker = "****"
+
+type secretKind string
+
+const (
+	secretKindURLPassword secretKind = "url-password"
+	secretKindURLToken    secretKind = "url-token"
+	secretKindAuthHeader  secretKind = "auth-header"
+	secretKindToken       secretKind = "token"
+)
+
+type secretFinding struct {
+	kind  secretKind
+	match string
+}
+
+// urlUserinfoPattern finds "scheme://userinfo@" prefixes anywhere in a value, which covers
+// remote URLs as well as insteadOf bases and extraHeader payloads.
+var urlUserinfoPattern = regexp.MustCompile(`([a-zA-Z][a-zA-Z0-9+.-]*://)([^/@\s]+)@`)
+
+// authHeaderPattern matches credentials carried in HTTP authorization headers.
+var authHeaderPattern = regexp.MustCompile(`(?i)\b(bearer|basic|token)\s+([A-Za-z0-9._~+/=-]{8,})`)
+
+// tokenPatterns lists well-known access token formats.
+var tokenPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{20,}\b`),
+	regexp.MustCompile(`\bgithub_pat_[A-Za-z0-9_]{20,}\b`),
+	regexp.MustCompile(`\bglpat-[A-Za-z0-9_-]{20,}\b`),
+	regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}\b`),
+	regexp.MustCompile(`\bAKIA[0-9A-Z]{16}\b`),
+}
+
+// detectSecrets reports credentials embedded in value.
+func detectSecrets(value string) []secretFinding {
+	var findings []secretFinding
+
+	for _, m := range urlUserinfoPattern.FindAllStringSubmatch(value, -1) {
+		userinfo, err := url.PathUnescape(m[2])
+		if err != nil {
+			userinfo = m[2]
+		}
+		if _, password, ok := strings.Cut(userinfo, ":"); ok {
+			if password != "" {
+				findings = append(findings, secretFinding{kind: secretKindURLPassword, match: m[0]})
+			}
+			continue
+		}
+		if looksLikeToken(userinfo) {
+			findings = append(findings, secretFinding{kind: secretKindURLToken, match: m[0]})
+		}
+	}
+
+	for _, m := range authHeaderPattern.FindAllString(value, -1) {
+		findings = append(findings, secretFinding{kind: secretKindAuthHeader, match: m})
+	}
+
+	for _, pattern := range tokenPatterns {
+		for _, m := range pattern.FindAllString(value, -1) {
+			if !findingsCover(findings, m) {
+				findings = append(findings, secretFinding{kind: secretKindToken, match: m})
+			}
+		}
+	}
+
+	return findings
+}
+
+func findingsCover(findings []secretFinding, match string) bool {
+	for _, f := range findings {
+		if strings.Contains(f.match, match) {
+			return true
+		}
+	}
+	return false
+}
+
+// looksLikeToken distinguishes access tokens used as a URL username from ordinary user names
+// such as "git" in ssh://git@host.
+func looksLikeToken(s string) bool {
+	for _, pattern := range tokenPatterns {
+		if pattern.MatchString(s) {
+			return true
+		}
+	}
+	return len(s) >= 32 && !strings.ContainsAny(s, " .")
+}
+
+// redactValue masks credentials in value, leaving the surrounding text intact so the value
+// remains recognisable.
+func redactValue(value string) (string, bool) {
+	redacted := urlUserinfoPattern.ReplaceAllStringFunc(value, func(m string) string {
+		parts := urlUserinfoPattern.FindStringSubmatch(m)
+		scheme, userinfo := parts[1], parts[2]
+		if user, password, ok := strings.Cut(userinfo, ":"); ok && password != "" {
+			return scheme + user + ":" + redactedMarker + "@"
+		}
+		if decoded, err := url.PathUnescape(userinfo); err == nil && looksLikeToken(decoded) {
+			return scheme + redactedMarker + "@"
+		}
+		return m
+	})
+
+	redacted = authHeaderPattern.ReplaceAllString(redacted, "${1} "+redactedMarker)
+
+	for _, pattern := range tokenPatterns {
+		redacted = pattern.ReplaceAllString(redacted, redactedMarker)
+	}
+
+	return redacted, redacted != value
+}
+
+// redactMatrix returns a copy of matrix with secret material masked in every value. Keys stay
+// as they are so that entries remain distinct and editable; a key carrying credentials, as
+// url.<base>.insteadOf can in its subsection, gets a masked DisplayKey instead.
+func redactMatrix(matrix ConfigMatrix) ConfigMatrix {
+	entries := make(map[string]ConfigValue, len(matrix.Entries))
+	for key, value := range matrix.Entries {
+		entries[key] = redactConfigValue(value)
+	}
+	matrix.Entries = entries
+	return matrix
+}
+
+func redactConfigValue(value ConfigValue) ConfigValue {
+	var redacted bool
+	if displayKey, keyRedacted := redactValue(value.Key); keyRedacted {
+		value.DisplayKey = displayKey
+		value.Redacted = true
+	}
+	value.Value, redacted = redactValue(value.Value)
+	if redacted {
+		value.Normalized, _ = redactValue(value.Normalized)
+		value.Redacted = true
+	}
+
+	if len(value.Overrides) > 0 {
+		overrides := make([]ConfigOverride, len(value.Overrides))
+		for i, override := range value.Overrides {
+			var overrideRedacted bool
+			override.Value, overrideRedacted = redactValue(override.Value)
+			if overrideRedacted {
+				override.Normalized, _ = redactValue(override.Normalized)
+				value.Redacted = true
+			}
+			overrides[i] = override
+		}
+		value.Overrides = overrides
+	}
+	return value
+}
+
+// secretIssues turns embedded credentials found in matrix into diagnostic issues. Messages
+// only ever mention the redacted key.
+func secretIssues(matrix ConfigMatrix) []DiagnosticIssue {
+	var issues []DiagnosticIssue
+	report := func(key, label, text string, source ConfigSource) {
+		for _, finding := range detectSecrets(text) {
+			issues = append(issues, DiagnosticIssue{
+				Severity:    "warning",
+				Message:     fmt.Sprintf("%s contains an embedded credential (%s)", label, finding.kind),
+				Suggestion:  secretSuggestion(key, finding.kind),
+				RelatedFile: source.File,
+			})
+		}
+	}
+
+	for key, value := range matrix.Entries {
+		label, _ := redactValue(key)
+		report(key, "key "+label, key, value.Source)
+		report(key, label, value.Value, value.Source)
+		for _, override := range value.Overrides {
+			report(key, label, override.Value, override.Source)
+		}
+	}
+	return issues
+}
+
+func secretSuggestion(key string, kind secretKind) string {
+	switch {
+	case kind == secretKindAuthHeader && strings.HasPrefix(key, "http."):
+		return "Move the header into a credential helper or an environment-specific config that is not shared."
+	case strings.HasPrefix(key, "url."):
+		return "Remove the credential from the rewrite base and let a credential helper supply it."
+	default:
+		return "Remove the credential from the URL and store it with a credential helper instead."
+	}
+}
+
+// redactDiff masks credentials line by line so diffs stay aligned.
+func redactDiff(diff string) string {
+	lines := strings.Split(diff, "\n")
+	for i, line := range lines {
+		lines[i], _ = redactValue(line)
+	}
+	return strings.Join(lines, "\n")
+}
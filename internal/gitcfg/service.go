@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
 	"path/filepath"
 	"sort"
 	"sync"
@@ -24,6 +25,7 @@ type RepositoryService interface {
 // ConfigurationService handles reading and writing git configuration data.
 type ConfigurationService interface {
 	GetEffectiveConfig(ctx context.Context, repositoryID string) (ConfigMatrix, error)
+	RevealValue(ctx context.Context, repositoryID, key string) (ConfigValue, error)
 	WriteConfig(ctx context.Context, req WriteRequest) (ChangeSet, error)
 	ListChangeSets(repositoryID string) []ChangeSet
 	Rollback(ctx context.Context, changeSetID string) (ChangeSet, error)
@@ -37,6 +39,137 @@ type RuleService interface {
 	ToggleRule(ctx context.Context, id string, enabled bool) (IncludeRule, error)
 }
 
+// RewriteService manages url.<base>.insteadOf and pushInsteadOf rules.
+type RewriteService interface {
+	ListURLRewrites(ctx context.Context, repositoryID string) ([]URLRewrite, error)
+	AddURLRewrite(ctx context.Context, req URLRewriteRequest) (ChangeSet, error)
+	RemoveURLRewrite(ctx context.Context, req URLRewriteRequest) (ChangeSet, error)
+	SimulateURLRewrite(ctx context.Context, repositoryID, input string) (RewriteSimulation, error)
+}
+
+// URLMatchService resolves URL-scoped settings such as http.<url>.proxy.
+type URLMatchService interface {
+	GetRemoteHTTPSettings(ctx context.Context, repositoryID string) ([]RemoteHTTPSettings, error)
+}
+
+// AliasService inspects alias.* definitions.
+type AliasService interface {
+	ListAliases(ctx context.Context, repositoryID string) ([]Alias, error)
+	ExpandAlias(ctx context.Context, repositoryID, name string, args []string) (AliasExpansion, error)
+}
+
+// SafeDirectoryService manages safe.directory entries for repositories owned by other users.
+type SafeDirectoryService interface {
+	ListSafeDirectories(ctx context.Context) ([]SafeDirectory, error)
+	TrustRepository(ctx context.Context, repositoryID string, dryRun bool) (ChangeSet, error)
+	PruneSafeDirectories(ctx context.Context, dryRun bool) (ChangeSet, error)
+}
+
+// CredentialService inspects credential helpers and the files of the store helper.
+type CredentialService interface {
+	ListCredentialConfig(ctx context.Context, repositoryID string) ([]CredentialEntry, error)
+	ResolveCredentialHelpers(ctx context.Context, repositoryID, input string) (CredentialResolution, error)
+	ListStoredCredentials(ctx context.Context, repositoryID string) ([]StoredCredential, error)
+	DeleteStoredCredential(ctx context.Context, repositoryID, id string, dryRun bool) (ChangeSet, error)
+}
+
+// RemoteService manages the remotes of tracked repositories.
+type RemoteService interface {
+	ListRemotes(ctx context.Context, repositoryID string) ([]Remote, error)
+	AddRemote(ctx context.Context, req RemoteRequest) (ChangeSet, error)
+	SetRemoteURL(ctx context.Context, req RemoteRequest) (ChangeSet, error)
+	RenameRemote(ctx context.Context, req RemoteRequest) (ChangeSet, error)
+	RemoveRemote(ctx context.Context, req RemoteRequest) (ChangeSet, error)
+	MigrateRemoteHost(ctx context.Context, req HostMigrationRequest) (HostMigrationResult, error)
+}
+
+// BranchService manages branch.<name> tracking configuration.
+type BranchService interface {
+	ListBranchConfigs(ctx context.Context, repositoryID string) ([]BranchConfig, error)
+	SetBranchUpstream(ctx context.Context, req BranchRequest) (ChangeSet, error)
+	SetBranchRebase(ctx context.Context, req BranchRequest) (ChangeSet, error)
+	ListStaleSections(ctx context.Context, repositoryID string) (StaleSections, error)
+	CleanStaleSections(ctx context.Context, repositoryID string, remotes []string, dryRun bool) (ChangeSet, error)
+}
+
+// IncludeService describes how config files include each other.
+type IncludeService interface {
+	GetIncludeGraph(ctx context.Context, repositoryID string) (IncludeGraph, error)
+	AnalyzeIncludes(ctx context.Context) (IncludeAnalysis, error)
+}
+
+// SimulationService evaluates configuration under hypothetical environments and locations.
+type SimulationService interface {
+	SimulateEnvironment(ctx context.Context, req SimulationRequest) (SimulationResult, error)
+	EvaluatePath(ctx context.Context, req WhatIfRequest) (WhatIfResult, error)
+}
+
+// ImpactService previews how changes outside of repositories affect every tracked repository.
+type ImpactService interface {
+	PreviewWriteImpact(ctx context.Context, req WriteRequest) (ImpactReport, error)
+	PreviewRuleImpact(ctx context.Context, rule IncludeRule) (ImpactReport, error)
+}
+
+// StagingService queues changes and applies them together.
+type StagingService interface {
+	StageWrite(ctx context.Context, req WriteRequest) (StagedChange, error)
+	StageRule(ctx context.Context, rule IncludeRule, remove bool) (StagedChange, error)
+	ListStaged() []StagedChange
+	ReorderStaged(ids []string) ([]StagedChange, error)
+	DropStaged(id string) error
+	ClearStaged()
+	PreviewStaged(ctx context.Context) (StagedPreview, error)
+	ApplyStaged(ctx context.Context, baseHashes map[string]string) (BatchResult, error)
+}
+
+// ArchiveService exports the configuration into an archive and restores it elsewhere.
+type ArchiveService interface {
+	ExportArchive(ctx context.Context, req ExportRequest) (ArchiveManifest, error)
+	PreviewImport(ctx context.Context, req ImportRequest) (ImportPlan, error)
+	ImportArchive(ctx context.Context, req ImportRequest) (ImportResult, error)
+}
+
+// BackupService browses, prunes and restores the backups taken before each change.
+type BackupService interface {
+	ListBackups(ctx context.Context, filePath string) ([]BackupFile, error)
+	GetBackupRetention() (BackupRetention, error)
+	SetBackupRetention(ctx context.Context, policy BackupRetention) (int, error)
+	PreviewRestore(ctx context.Context, backupID string) (ChangeSet, error)
+	RestoreBackup(ctx context.Context, backupID, expectedHash string) (ChangeSet, error)
+}
+
+// UndoService walks back and forth through the edits of the session.
+type UndoService interface {
+	Undo(ctx context.Context) (EditResult, error)
+	Redo(ctx context.Context) (EditResult, error)
+	GetEditHistory() EditHistory
+}
+
+// AuditService reads and verifies the audit log of configuration changes.
+type AuditService interface {
+	QueryAuditLog(ctx context.Context, q AuditQuery) ([]AuditEntry, error)
+	VerifyAuditLog(ctx context.Context) (AuditVerification, error)
+	GetKeyHistory(ctx context.Context, repositoryID, key string) (KeyHistory, error)
+}
+
+// CatalogService answers questions about known git configuration variables.
+type CatalogService interface {
+	DescribeKey(key string) (KeyInfo, error)
+	CompleteKeys(prefix string, limit int) []KeyInfo
+}
+
+// SearchService queries configuration across every tracked repository.
+type SearchService interface {
+	Search(ctx context.Context, query SearchQuery) (SearchResponse, error)
+}
+
+// CommandAuditService flags configuration values that make git run programs.
+type CommandAuditService interface {
+	AuditCommandKeys(ctx context.Context, repositoryID string) ([]CommandKeyFinding, error)
+	TrustCommandKey(ctx context.Context, repositoryID, key, fingerprint string) error
+	UntrustCommandKey(repositoryID, key, fingerprint string) error
+}
+
 // DiagnosticsService evaluates data parity between internal state and git CLI output.
 type DiagnosticsService interface {
 	RunDiagnostics(ctx context.Context, repositoryID string) (DiagnosticsReport, error)
@@ -48,7 +181,22 @@ type Service struct {
 	roots        map[string]struct{}
 	repositories map[string]Repository
 	includeRules map[string]IncludeRule
+	staged       []StagedChange
+	undo         []*editStep
+	redo         []*editStep
 	changeSets   map[string]ChangeSet
+	matrices     map[string]cachedMatrix
+	dataDir      string
+
+	// auditTail caches the last entry of the audit log at auditTailPath so that appending
+	// does not re-read the log; it is nil until the log has been read.
+	auditTail     *AuditEntry
+	auditTailPath string
+	// editSeq counts the steps pushed onto the undo stack.
+	editSeq uint64
+	// replayMu serialises Undo and Redo, which each take a step off one stack and put it on
+	// the other once its files are written.
+	replayMu sync.Mutex
 }
 
 // NewService constructs a new in-memory Service instance primed with sensible defaults.
@@ -58,6 +206,8 @@ func NewService() *Service {
 		repositories: make(map[string]Repository),
 		includeRules: make(map[string]IncludeRule),
 		changeSets:   make(map[string]ChangeSet),
+		matrices:     make(map[string]cachedMatrix),
+		dataDir:      defaultDataDir(),
 	}
 }
 
@@ -106,6 +256,10 @@ func (s *Service) ResolveRepository(ctx context.Context, path string) (Repositor
 	}
 
 	repo, err := buildRepository(ctx, path)
+	var dubious *dubiousOwnershipError
+	if errors.As(err, &dubious) {
+		return dubiousRepository(path, dubious), nil
+	}
 	if err != nil {
 		return Repository{}, fmt.Errorf("选中的目录不是有效的 Git 仓库: %w", err)
 	}
@@ -137,6 +291,10 @@ func (s *Service) Scan(ctx context.Context, opts ScanOptions) ([]Repository, err
 		}
 
 		repo, err := buildRepository(ctx, root)
+		var dubious *dubiousOwnershipError
+		if errors.As(err, &dubious) {
+			repo, err = dubiousRepository(root, dubious), nil
+		}
 		if err != nil {
 			return nil, fmt.Errorf("discover repository at %q: %w", root, err)
 		}
@@ -154,12 +312,21 @@ func (s *Service) Scan(ctx context.Context, opts ScanOptions) ([]Repository, err
 
 	s.mu.Lock()
 	s.repositories = discovered
+	for id := range s.matrices {
+		if _, ok := discovered[id]; !ok || opts.ForceRefresh {
+			delete(s.matrices, id)
+		}
+	}
 	s.mu.Unlock()
 
 	return results, nil
 }
 
-// GetGlobalConfig returns the global git configuration for the current user.
+// GlobalRepositoryID is the pseudo repository ID under which the global configuration is reported.
+const GlobalRepositoryID = "global"
+
+// GetGlobalConfig returns the global git configuration for the current user, with
+// credentials redacted.
 func (s *Service) GetGlobalConfig(ctx context.Context) (ConfigMatrix, error) {
 	select {
 	case <-ctx.Done():
@@ -167,39 +334,97 @@ func (s *Service) GetGlobalConfig(ctx context.Context) (ConfigMatrix, error) {
 	default:
 	}
 
+	matrix, err := s.loadGlobalMatrix(ctx)
+	if err != nil {
+		return ConfigMatrix{}, err
+	}
+	return redactMatrix(matrix), nil
+}
+
+func (s *Service) loadGlobalMatrix(ctx context.Context) (ConfigMatrix, error) {
 	values, err := readGlobalConfig(ctx)
 	if err != nil {
 		return ConfigMatrix{}, err
 	}
+	annotateIncludeChains(values, includeChains(ctx, nil, values), "")
 
 	matrix := ConfigMatrix{
-		RepositoryID: "global",
+		RepositoryID: GlobalRepositoryID,
 		Entries:      values,
 		RetrievedAt:  timestamp(time.Now()),
 	}
 	return matrix, nil
 }
 
-// GetEffectiveConfig resolves repo configuration via the git CLI.
+// GetEffectiveConfig resolves repo configuration via the git CLI, with credentials redacted.
 func (s *Service) GetEffectiveConfig(ctx context.Context, repositoryID string) (ConfigMatrix, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
 	select {
 	case <-ctx.Done():
 		return ConfigMatrix{}, ctx.Err()
 	default:
 	}
 
+	matrix, err := s.loadMatrix(ctx, repositoryID, true)
+	if err != nil {
+		return ConfigMatrix{}, err
+	}
+	return redactMatrix(matrix), nil
+}
+
+// RevealValue returns the unredacted entry for key. The key may be given either verbatim or
+// in the redacted spelling returned by GetEffectiveConfig.
+func (s *Service) RevealValue(ctx context.Context, repositoryID, key string) (ConfigValue, error) {
+	select {
+	case <-ctx.Done():
+		return ConfigValue{}, ctx.Err()
+	default:
+	}
+
+	matrix, err := s.matrixFor(ctx, repositoryID)
+	if err != nil {
+		return ConfigValue{}, err
+	}
+
+	if value, ok := matrix.Lookup(key); ok {
+		return value, nil
+	}
+	for rawKey, value := range matrix.Entries {
+		if redacted, _ := redactValue(rawKey); redacted == key {
+			return value, nil
+		}
+	}
+	return ConfigValue{}, fmt.Errorf("key %q not found", key)
+}
+
+// matrixFor loads the unredacted matrix for a repository, or the global configuration when
+// repositoryID is GlobalRepositoryID.
+func (s *Service) matrixFor(ctx context.Context, repositoryID string) (ConfigMatrix, error) {
+	if repositoryID == GlobalRepositoryID {
+		return s.loadGlobalMatrix(ctx)
+	}
+	return s.loadMatrix(ctx, repositoryID, false)
+}
+
+// loadMatrix returns the configuration matrix of a tracked repository, reusing the cached
+// copy unless refresh is set or the cache entry is stale.
+func (s *Service) loadMatrix(ctx context.Context, repositoryID string, refresh bool) (ConfigMatrix, error) {
+	s.mu.RLock()
 	repo, ok := s.repositories[repositoryID]
+	cached, cachedOK := s.matrices[repositoryID]
+	s.mu.RUnlock()
+
 	if !ok {
 		return ConfigMatrix{}, fmt.Errorf("repository %q not found", repositoryID)
 	}
+	if !refresh && cachedOK && cached.fresh(time.Now()) {
+		return cached.matrix, nil
+	}
 
 	values, err := readGitConfig(ctx, repo.Path)
 	if err != nil {
 		return ConfigMatrix{}, err
 	}
+	annotateIncludeChains(values, includeChains(ctx, &repo, values), repo.Path)
 
 	matrix := ConfigMatrix{
 		RepositoryID: repositoryID,
@@ -207,10 +432,15 @@ func (s *Service) GetEffectiveConfig(ctx context.Context, repositoryID string) (
 		RetrievedAt:  timestamp(time.Now()),
 	}
 
+	s.mu.Lock()
+	s.matrices[repositoryID] = newCachedMatrix(matrix, repo.Path, time.Now())
+	s.mu.Unlock()
+
 	return matrix, nil
 }
 
-// WriteConfig records the request as a synthetic change set.
+// WriteConfig validates the request and applies it to the config file selected by its
+// scope or target path. Dry runs return the change set without touching the file.
 func (s *Service) WriteConfig(ctx context.Context, req WriteRequest) (ChangeSet, error) {
 	select {
 	case <-ctx.Done():
@@ -218,24 +448,62 @@ func (s *Service) WriteConfig(ctx context.Context, req WriteRequest) (ChangeSet,
 	default:
 	}
 
-	if req.Key == "" {
-		return ChangeSet{}, errors.New("key cannot be empty")
+	req, edit, err := s.prepareWrite(ctx, req)
+	if err != nil {
+		return ChangeSet{}, err
 	}
-
-	cs := ChangeSet{
-		ID:           uuid.NewString(),
+	return s.applyEdit(ctx, ChangeSet{
 		RepositoryID: req.RepositoryID,
 		Scope:        req.Scope,
-		FilePath:     req.TargetPath,
-		Diff:         fmt.Sprintf("+ %s = %q\n", req.Key, req.Value),
-		CreatedAt:    timestamp(time.Now()),
+		Key:          req.Key,
+		Operation:    req.Operation,
+	}, edit, req.DryRun)
+}
+
+// prepareWrite validates req and resolves the file it targets. The returned request carries
+// the canonical key and operation, while the edit keeps the caller's spelling of the key.
+func (s *Service) prepareWrite(ctx context.Context, req WriteRequest) (WriteRequest, configEdit, error) {
+	if req.Key == "" {
+		return WriteRequest{}, configEdit{}, errors.New("key cannot be empty")
+	}
+	key, err := ParseKey(req.Key)
+	if err != nil {
+		return WriteRequest{}, configEdit{}, err
+	}
+	// git keeps the spelling it is given, so the file is written with the caller's casing
+	// while the change set records the canonical key.
+	spelled := req
+	req.Key = key.String()
+	if req.Operation == "" {
+		req.Operation = WriteOperationSet
+	}
+	if req.Operation == WriteOperationSet || req.Operation == WriteOperationAdd {
+		if err := validateValue(req.Key, req.Value); err != nil {
+			return WriteRequest{}, configEdit{}, err
+		}
 	}
 
-	s.mu.Lock()
-	s.changeSets[cs.ID] = cs
-	s.mu.Unlock()
+	var repo *Repository
+	if req.RepositoryID != "" && req.RepositoryID != GlobalRepositoryID {
+		s.mu.RLock()
+		found, ok := s.repositories[req.RepositoryID]
+		s.mu.RUnlock()
+		if !ok {
+			return WriteRequest{}, configEdit{}, fmt.Errorf("repository %q not found", req.RepositoryID)
+		}
+		repo = &found
+	}
 
-	return cs, nil
+	path, err := resolveTargetFile(ctx, repo, req.Scope, req.TargetPath)
+	if err != nil {
+		return WriteRequest{}, configEdit{}, err
+	}
+
+	ops, err := writeOps(spelled)
+	if err != nil {
+		return WriteRequest{}, configEdit{}, err
+	}
+	return req, configEdit{path: path, ops: ops, expectedHash: req.ExpectedHash}, nil
 }
 
 // ListChangeSets returns the stored changes for a repository.
@@ -256,7 +524,9 @@ func (s *Service) ListChangeSets(repositoryID string) []ChangeSet {
 	return changes
 }
 
-// Rollback returns the stored change set. Actual rollback is to be implemented.
+// Rollback restores the file touched by a change set to its previous contents. It is refused
+// when the file no longer holds the contents the change set produced, so later edits are never
+// discarded. The rollback is itself recorded as a change set that references the reverted one.
 func (s *Service) Rollback(ctx context.Context, changeSetID string) (ChangeSet, error) {
 	select {
 	case <-ctx.Done():
@@ -265,16 +535,56 @@ func (s *Service) Rollback(ctx context.Context, changeSetID string) (ChangeSet,
 	}
 
 	s.mu.RLock()
-	defer s.mu.RUnlock()
-
 	cs, ok := s.changeSets[changeSetID]
+	s.mu.RUnlock()
 	if !ok {
 		return ChangeSet{}, fmt.Errorf("changeset %q not found", changeSetID)
 	}
-	return cs, nil
+
+	var restored []byte
+	if !cs.Created {
+		if cs.BackupPath == "" {
+			return ChangeSet{}, fmt.Errorf("changeset %q has no backup", changeSetID)
+		}
+		content, err := readBackup(cs.BackupPath)
+		if err != nil {
+			return ChangeSet{}, err
+		}
+		restored = content
+	}
+
+	lock, err := lockFile(cs.FilePath)
+	if err != nil {
+		return ChangeSet{}, err
+	}
+	defer lock.release()
+	current, err := os.ReadFile(cs.FilePath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return ChangeSet{}, fmt.Errorf("read %s: %w", cs.FilePath, err)
+	}
+	existed := err == nil
+	if existed != (cs.AfterHash != "") || existed && contentHash(current) != cs.AfterHash {
+		return ChangeSet{}, fmt.Errorf("%s has changed since changeset %q; rolling it back would discard those changes", cs.FilePath, changeSetID)
+	}
+
+	revert := ChangeSet{
+		RepositoryID: cs.RepositoryID,
+		Scope:        cs.Scope,
+		Key:          cs.Key,
+		Operation:    WriteOperationRollback,
+		RevertsID:    cs.ID,
+	}
+	return s.recordChange(revert, fileChange{
+		path:    cs.FilePath,
+		before:  current,
+		after:   restored,
+		existed: existed,
+		remove:  cs.Created,
+		lock:    lock,
+	})
 }
 
-// ListRules returns includeIf rules tracked in memory.
+// ListRules returns the includeIf rules managed by the application.
 func (s *Service) ListRules(ctx context.Context) ([]IncludeRule, error) {
 	select {
 	case <-ctx.Done():
@@ -296,7 +606,141 @@ func (s *Service) ListRules(ctx context.Context) ([]IncludeRule, error) {
 	return rules, nil
 }
 
-// UpsertRule stores a rule in memory.
+// rulesByID returns a copy of the stored rules.
+func (s *Service) rulesByID() map[string]IncludeRule {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	rules := make(map[string]IncludeRule, len(s.includeRules))
+	for id, rule := range s.includeRules {
+		rules[id] = rule
+	}
+	return rules
+}
+
+// ruleDirective returns the includeIf variable and value that apply rule.
+func ruleDirective(rule IncludeRule) (key, value string) {
+	return CanonicalKey("includeIf." + ruleCondition(rule) + ".path"), rule.TargetPath
+}
+
+// ruleDirectives returns the include directives a config file holds, keyed by variable and
+// value.
+func ruleDirectives(content []byte) map[string]bool {
+	present := make(map[string]bool)
+	entries, err := parseConfigFile(content)
+	if err != nil {
+		return present
+	}
+	for _, entry := range entries {
+		if _, _, ok := includeDirective(entry.key); ok && !entry.noValue {
+			present[entry.key.String()+"\x00"+entry.value] = true
+		}
+	}
+	return present
+}
+
+// ruleOps returns the git config arguments that move the global config file from the rule
+// state before to after, nil meaning absent. present lists the directives the file holds and
+// is updated, so that a directive is only removed when it is there and added when it is not.
+func ruleOps(before, after *IncludeRule, present map[string]bool) [][]string {
+	var oldKey, oldValue, newKey, newValue string
+	if before != nil && before.Enabled {
+		oldKey, oldValue = ruleDirective(*before)
+	}
+	if after != nil && after.Enabled {
+		newKey, newValue = ruleDirective(*after)
+	}
+	if oldKey == newKey && oldValue == newValue {
+		return nil
+	}
+
+	var ops [][]string
+	if id := oldKey + "\x00" + oldValue; oldKey != "" && present[id] {
+		ops = append(ops, []string{"--fixed-value", "--unset-all", oldKey, oldValue})
+		delete(present, id)
+	}
+	if id := newKey + "\x00" + newValue; newKey != "" && !present[id] {
+		ops = append(ops, []string{"--add", newKey, newValue})
+		present[id] = true
+	}
+	return ops
+}
+
+// globalRuleDirectives returns the global config file rules are written to and the include
+// directives it holds.
+func globalRuleDirectives() (string, map[string]bool, error) {
+	path, err := globalConfigPath()
+	if err != nil {
+		return "", nil, err
+	}
+	content, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	return path, ruleDirectives(content), nil
+}
+
+// ruleChangeSet describes the write that moves a rule from before to after.
+func ruleChangeSet(before, after *IncludeRule) ChangeSet {
+	cs := ChangeSet{RepositoryID: GlobalRepositoryID, Scope: ConfigScopeGlobal, Operation: WriteOperationAdd}
+	rule := after
+	if rule == nil || !rule.Enabled {
+		rule, cs.Operation = before, WriteOperationUnset
+	}
+	if rule != nil {
+		cs.Key, _ = ruleDirective(*rule)
+	}
+	return cs
+}
+
+// saveRule stores rule, or deletes the rule with id when rule is nil, and writes the includeIf
+// directive that applies it to the global config file. The file and the rule are one undo step.
+func (s *Service) saveRule(ctx context.Context, id string, rule *IncludeRule, label string) error {
+	path, err := globalConfigPath()
+	if err != nil {
+		return err
+	}
+	lock, err := lockFile(path)
+	if err != nil {
+		return err
+	}
+	defer lock.release()
+
+	s.mu.RLock()
+	stored, existed := s.includeRules[id]
+	s.mu.RUnlock()
+	previous := ruleState(stored, existed)
+
+	path, present, err := globalRuleDirectives()
+	if err != nil {
+		return err
+	}
+	edit := configEdit{path: path, ops: ruleOps(previous, rule, present)}
+	cs := ruleChangeSet(previous, rule)
+	cs.BatchID = uuid.NewString()
+	if len(edit.ops) > 0 {
+		before, after, err := previewEdit(ctx, edit)
+		if err != nil {
+			return err
+		}
+		_, statErr := os.Stat(edit.path)
+		if _, err := s.recordChange(cs, fileChange{path: edit.path, before: before, after: after, existed: statErr == nil, lock: lock}); err != nil {
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	if rule == nil {
+		delete(s.includeRules, id)
+	} else {
+		s.includeRules[id] = *rule
+	}
+	s.pushEditStepLocked(editStep{label: label, batchID: cs.BatchID, rules: []editRule{{id: id, before: previous, after: rule}}})
+	s.mu.Unlock()
+	return nil
+}
+
+// UpsertRule stores a rule and writes its includeIf directive to the global config file,
+// replacing the directive of the rule it updates.
 func (s *Service) UpsertRule(ctx context.Context, rule IncludeRule) (IncludeRule, error) {
 	select {
 	case <-ctx.Done():
@@ -316,14 +760,16 @@ func (s *Service) UpsertRule(ctx context.Context, rule IncludeRule) (IncludeRule
 	}
 	rule.LastUpdated = timestamp(time.Now())
 
-	s.mu.Lock()
-	s.includeRules[rule.ID] = rule
-	s.mu.Unlock()
-
+	if err := s.saveRule(ctx, rule.ID, &rule, "save rule "+rule.Pattern); err != nil {
+		return IncludeRule{}, err
+	}
+	if err := s.auditRule(AuditRuleUpsert, rule); err != nil {
+		return rule, err
+	}
 	return rule, nil
 }
 
-// DeleteRule removes a rule from the in-memory store.
+// DeleteRule removes a rule and its includeIf directive.
 func (s *Service) DeleteRule(ctx context.Context, id string) error {
 	select {
 	case <-ctx.Done():
@@ -331,14 +777,20 @@ func (s *Service) DeleteRule(ctx context.Context, id string) error {
 	default:
 	}
 
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	delete(s.includeRules, id)
-	return nil
+	s.mu.RLock()
+	rule, ok := s.includeRules[id]
+	s.mu.RUnlock()
+	if !ok {
+		return nil
+	}
+	if err := s.saveRule(ctx, id, nil, "delete rule "+rule.Pattern); err != nil {
+		return err
+	}
+	return s.auditRule(AuditRuleDelete, rule)
 }
 
-// ToggleRule flips the enabled state of a stored rule.
+// ToggleRule flips the enabled state of a stored rule, adding or removing its includeIf
+// directive.
 func (s *Service) ToggleRule(ctx context.Context, id string, enabled bool) (IncludeRule, error) {
 	select {
 	case <-ctx.Done():
@@ -346,21 +798,45 @@ func (s *Service) ToggleRule(ctx context.Context, id string, enabled bool) (Incl
 	default:
 	}
 
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
+	s.mu.RLock()
 	rule, ok := s.includeRules[id]
+	s.mu.RUnlock()
 	if !ok {
 		return IncludeRule{}, fmt.Errorf("rule %q not found", id)
 	}
 
 	rule.Enabled = enabled
 	rule.LastUpdated = timestamp(time.Now())
-	s.includeRules[id] = rule
+	label := "disable rule "
+	if enabled {
+		label = "enable rule "
+	}
+	if err := s.saveRule(ctx, id, &rule, label+rule.Pattern); err != nil {
+		return IncludeRule{}, err
+	}
+	if err := s.auditRule(AuditRuleToggle, rule); err != nil {
+		return rule, err
+	}
 	return rule, nil
 }
 
-// RunDiagnostics returns a canned report.
+// DescribeKey returns the catalogue documentation for key.
+func (s *Service) DescribeKey(key string) (KeyInfo, error) {
+	info, ok := LookupKeyInfo(key)
+	if !ok {
+		return KeyInfo{}, fmt.Errorf("key %q is not in the catalogue", key)
+	}
+	return info, nil
+}
+
+// CompleteKeys suggests catalogue keys starting with prefix, returning at most limit entries
+// when limit is positive.
+func (s *Service) CompleteKeys(prefix string, limit int) []KeyInfo {
+	return CompleteKeys(prefix, limit)
+}
+
+// RunDiagnostics inspects the configuration of a repository, or of the global scope when
+// repositoryID is GlobalRepositoryID, and reports potential problems.
 func (s *Service) RunDiagnostics(ctx context.Context, repositoryID string) (DiagnosticsReport, error) {
 	select {
 	case <-ctx.Done():
@@ -372,16 +848,20 @@ func (s *Service) RunDiagnostics(ctx context.Context, repositoryID string) (Diag
 		return DiagnosticsReport{}, errors.New("repositoryID cannot be empty")
 	}
 
+	matrix, err := s.matrixFor(ctx, repositoryID)
+	if err != nil {
+		return DiagnosticsReport{}, err
+	}
+
+	trusted, err := s.trustedCommands(repositoryID)
+	if err != nil {
+		return DiagnosticsReport{}, err
+	}
+
 	report := DiagnosticsReport{
 		RepositoryID: repositoryID,
 		CheckedAt:    timestamp(time.Now()),
-		Issues: []DiagnosticIssue{
-			{
-				Severity:   "info",
-				Message:    "Diagnostic subsystem is not yet wired to git CLI.",
-				Suggestion: "Implement git config parity check.",
-			},
-		},
+		Issues:       append(secretIssues(matrix), commandIssues(auditCommandKeys(matrix, trusted))...),
 	}
 	return report, nil
 }
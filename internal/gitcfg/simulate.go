@@ -0,0 +1,250 @@
+package gitcfg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ConfigPair is a variable injected into the configuration from outside of any file.
+type ConfigPair struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// EnvOverride sets or, with Unset, removes an environment variable for a simulation.
+type EnvOverride struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+	Unset bool   `json:"unset,omitempty"`
+}
+
+// SimulationRequest describes a hypothetical environment in which to evaluate the
+// configuration of a repository.
+type SimulationRequest struct {
+	RepositoryID string `json:"repositoryId"`
+	// Env changes the variables that decide which files git reads: HOME, XDG_CONFIG_HOME,
+	// GIT_CONFIG_GLOBAL, GIT_CONFIG_SYSTEM and GIT_CONFIG_NOSYSTEM.
+	Env []EnvOverride `json:"env,omitempty"`
+	// EnvConfig is appended to GIT_CONFIG_COUNT, GIT_CONFIG_KEY_<n> and GIT_CONFIG_VALUE_<n>.
+	EnvConfig []ConfigPair `json:"envConfig,omitempty"`
+	// CommandLine is passed as -c key=value, which git applies after EnvConfig.
+	CommandLine []ConfigPair `json:"commandLine,omitempty"`
+}
+
+// SimulationResult holds the keys whose effective value or provenance differs between the
+// current environment and the simulated one. Matrix has the simulated values and Baseline the
+// current ones, so keys present in only one of them are added or removed by the simulation.
+type SimulationResult struct {
+	Matrix   ConfigMatrix `json:"matrix"`
+	Baseline ConfigMatrix `json:"baseline"`
+}
+
+// simulationEnvVars are the environment variables a simulation may change.
+var simulationEnvVars = map[string]bool{
+	"HOME":                true,
+	"XDG_CONFIG_HOME":     true,
+	"GIT_CONFIG_GLOBAL":   true,
+	"GIT_CONFIG_SYSTEM":   true,
+	"GIT_CONFIG_NOSYSTEM": true,
+}
+
+// simulationEnv builds the environment of a simulated git run from base. It returns the
+// environment and the number of GIT_CONFIG_COUNT entries, which git reads before -c options.
+func simulationEnv(base []string, req SimulationRequest) ([]string, int, error) {
+	vars := make(map[string]string)
+	var order []string
+	for _, kv := range base {
+		name, value, _ := strings.Cut(kv, "=")
+		if _, ok := vars[name]; !ok {
+			order = append(order, name)
+		}
+		vars[name] = value
+	}
+
+	var pairs []ConfigPair
+	if raw, ok := vars["GIT_CONFIG_COUNT"]; ok && raw != "" {
+		count, err := strconv.Atoi(raw)
+		if err != nil || count < 0 {
+			return nil, 0, fmt.Errorf("invalid GIT_CONFIG_COUNT %q in the current environment", raw)
+		}
+		for i := 0; i < count; i++ {
+			pairs = append(pairs, ConfigPair{Key: vars[fmt.Sprintf("GIT_CONFIG_KEY_%d", i)], Value: vars[fmt.Sprintf("GIT_CONFIG_VALUE_%d", i)]})
+		}
+	}
+	for _, pair := range req.EnvConfig {
+		if _, err := ParseKey(pair.Key); err != nil {
+			return nil, 0, fmt.Errorf("env config %q: %w", pair.Key, err)
+		}
+		pairs = append(pairs, pair)
+	}
+
+	for _, override := range req.Env {
+		if !simulationEnvVars[override.Name] {
+			return nil, 0, fmt.Errorf("environment variable %q cannot be simulated", override.Name)
+		}
+		if override.Unset {
+			delete(vars, override.Name)
+			continue
+		}
+		if _, ok := vars[override.Name]; !ok {
+			order = append(order, override.Name)
+		}
+		vars[override.Name] = override.Value
+	}
+
+	env := make([]string, 0, len(vars)+2*len(pairs)+1)
+	for _, name := range order {
+		value, ok := vars[name]
+		if !ok || name == "GIT_CONFIG_COUNT" || strings.HasPrefix(name, "GIT_CONFIG_KEY_") || strings.HasPrefix(name, "GIT_CONFIG_VALUE_") {
+			continue
+		}
+		env = append(env, name+"="+value)
+	}
+	if len(pairs) > 0 {
+		env = append(env, fmt.Sprintf("GIT_CONFIG_COUNT=%d", len(pairs)))
+		for i, pair := range pairs {
+			env = append(env, fmt.Sprintf("GIT_CONFIG_KEY_%d=%s", i, pair.Key), fmt.Sprintf("GIT_CONFIG_VALUE_%d=%s", i, pair.Value))
+		}
+	}
+	return env, len(pairs), nil
+}
+
+// commandLineArgs turns pairs into -c options.
+func commandLineArgs(pairs []ConfigPair) ([]string, error) {
+	var args []string
+	for _, pair := range pairs {
+		if _, err := ParseKey(pair.Key); err != nil {
+			return nil, fmt.Errorf("command line config %q: %w", pair.Key, err)
+		}
+		if strings.Contains(pair.Key, "=") {
+			return nil, fmt.Errorf("command line config %q: key cannot contain '='", pair.Key)
+		}
+		args = append(args, "-c", pair.Key+"="+pair.Value)
+	}
+	return args, nil
+}
+
+// readSimulatedConfig lists the configuration of repoPath as git sees it with env and the
+// given -c options. git reports both environment and -c values with the command scope; the
+// first envCount of them come from the environment and are relabelled ConfigScopeEnv.
+func readSimulatedConfig(ctx context.Context, repoPath string, env []string, envCount int, commandLine []string) (map[string]ConfigValue, error) {
+	args := append([]string{"-C", repoPath}, commandLine...)
+	args = append(args, "config", "--null", "--show-origin", "--show-scope", "--list")
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Env = env
+
+	output, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return nil, gitError(err, string(exitErr.Stderr))
+		}
+		return nil, fmt.Errorf("git config failed: %w", err)
+	}
+
+	entries, err := parseGitConfigOutput(output)
+	if err != nil {
+		return nil, err
+	}
+	for i := range entries {
+		if entries[i].scope != ConfigScopeCommand {
+			continue
+		}
+		if envCount > 0 {
+			entries[i].scope = ConfigScopeEnv
+			envCount--
+		}
+	}
+	return buildConfigValues(entries), nil
+}
+
+// sameConfigValue reports whether a and b resolve to the same values from the same places.
+func sameConfigValue(a, b ConfigValue) bool {
+	sameSource := func(x, y ConfigSource) bool {
+		return x.Scope == y.Scope && x.File == y.File && x.Line == y.Line
+	}
+	if a.Value != b.Value || !sameSource(a.Source, b.Source) || len(a.Overrides) != len(b.Overrides) {
+		return false
+	}
+	for i := range a.Overrides {
+		if a.Overrides[i].Value != b.Overrides[i].Value || !sameSource(a.Overrides[i].Source, b.Overrides[i].Source) {
+			return false
+		}
+	}
+	return true
+}
+
+// SimulateEnvironment evaluates the configuration of a repository under a hypothetical
+// environment and command line and returns the keys that change.
+func (s *Service) SimulateEnvironment(ctx context.Context, req SimulationRequest) (SimulationResult, error) {
+	select {
+	case <-ctx.Done():
+		return SimulationResult{}, ctx.Err()
+	default:
+	}
+
+	s.mu.RLock()
+	repo, ok := s.repositories[req.RepositoryID]
+	s.mu.RUnlock()
+	if !ok {
+		return SimulationResult{}, fmt.Errorf("repository %q not found", req.RepositoryID)
+	}
+
+	commandLine, err := commandLineArgs(req.CommandLine)
+	if err != nil {
+		return SimulationResult{}, err
+	}
+	baseEnv, baseCount, err := simulationEnv(os.Environ(), SimulationRequest{})
+	if err != nil {
+		return SimulationResult{}, err
+	}
+	env, envCount, err := simulationEnv(os.Environ(), req)
+	if err != nil {
+		return SimulationResult{}, err
+	}
+
+	before, err := readSimulatedConfig(ctx, repo.Path, baseEnv, baseCount, nil)
+	if err != nil {
+		return SimulationResult{}, err
+	}
+	after, err := readSimulatedConfig(ctx, repo.Path, env, envCount, commandLine)
+	if err != nil {
+		return SimulationResult{}, fmt.Errorf("simulated environment: %w", err)
+	}
+
+	now := timestamp(time.Now())
+	result := SimulationResult{
+		Matrix:   ConfigMatrix{RepositoryID: repo.ID, Entries: map[string]ConfigValue{}, RetrievedAt: now},
+		Baseline: ConfigMatrix{RepositoryID: repo.ID, Entries: map[string]ConfigValue{}, RetrievedAt: now},
+	}
+	keys := make(map[string]bool)
+	for key := range before {
+		keys[key] = true
+	}
+	for key := range after {
+		keys[key] = true
+	}
+	for key := range keys {
+		old, hadOld := before[key]
+		updated, hasNew := after[key]
+		if hadOld && hasNew && sameConfigValue(old, updated) {
+			continue
+		}
+		if hadOld {
+			result.Baseline.Entries[key] = old
+		}
+		if hasNew {
+			result.Matrix.Entries[key] = updated
+		}
+	}
+
+	result.Matrix = redactMatrix(result.Matrix)
+	result.Baseline = redactMatrix(result.Baseline)
+	return result, nil
+}
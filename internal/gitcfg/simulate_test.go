@@ -0,0 +1,91 @@
+package gitcfg
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestSimulationEnv(t *testing.T) {
+	t.Parallel()
+
+	base := []string{"HOME=/home/me", "GIT_CONFIG_COUNT=1", "GIT_CONFIG_KEY_0=core.pager", "GIT_CONFIG_VALUE_0=cat", "GIT_CONFIG_NOSYSTEM=1"}
+	env, count, err := simulationEnv(base, SimulationRequest{
+		Env: []EnvOverride{
+			{Name: "HOME", Value: "/home/other"},
+			{Name: "GIT_CONFIG_NOSYSTEM", Unset: true},
+			{Name: "XDG_CONFIG_HOME", Value: "/tmp/xdg"},
+		},
+		EnvConfig: []ConfigPair{{Key: "user.name", Value: "Bob"}},
+	})
+	if err != nil {
+		t.Fatalf("simulationEnv returned error: %v", err)
+	}
+	want := []string{
+		"HOME=/home/other", "XDG_CONFIG_HOME=/tmp/xdg", "GIT_CONFIG_COUNT=2",
+		"GIT_CONFIG_KEY_0=core.pager", "GIT_CONFIG_VALUE_0=cat",
+		"GIT_CONFIG_KEY_1=user.name", "GIT_CONFIG_VALUE_1=Bob",
+	}
+	if count != 2 || len(env) != len(want) {
+		t.Fatalf("expected %q with two entries, got %q (%d)", want, env, count)
+	}
+	for i := range want {
+		if env[i] != want[i] {
+			t.Fatalf("expected %q, got %q", want, env)
+		}
+	}
+
+	if _, _, err := simulationEnv(base, SimulationRequest{Env: []EnvOverride{{Name: "PATH", Value: "/bin"}}}); err == nil {
+		t.Fatal("expected unsupported variables to be rejected")
+	}
+	if _, err := commandLineArgs([]ConfigPair{{Key: "nokey", Value: "x"}}); err == nil {
+		t.Fatal("expected an invalid -c key to be rejected")
+	}
+}
+
+func TestSimulateEnvironment(t *testing.T) {
+	s := newTestService(t)
+	home := t.TempDir()
+	global := filepath.Join(home, "gitconfig")
+	other := filepath.Join(home, "other.gitconfig")
+	t.Setenv("GIT_CONFIG_GLOBAL", global)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv("GIT_CONFIG_COUNT", "")
+	repo := newTestRepository(t, s)
+
+	writeTestFile(t, global, "[user]\n\tname = Alice\n[color]\n\tui = auto\n[core]\n\tpager = less\n")
+	writeTestFile(t, other, "[user]\n\tname = Bob\n[core]\n\tpager = less\n")
+
+	result, err := s.SimulateEnvironment(context.Background(), SimulationRequest{
+		RepositoryID: repo.ID,
+		Env:          []EnvOverride{{Name: "GIT_CONFIG_GLOBAL", Value: other}},
+		EnvConfig:    []ConfigPair{{Key: "core.editor", Value: "vim"}},
+		CommandLine:  []ConfigPair{{Key: "core.editor", Value: "nano"}},
+	})
+	if err != nil {
+		t.Fatalf("SimulateEnvironment returned error: %v", err)
+	}
+
+	if name := result.Matrix.Entries["user.name"]; name.Value != "Bob" || name.Source.File != other {
+		t.Fatalf("expected user.name from the simulated global file, got %+v", name)
+	}
+	if _, ok := result.Baseline.Entries["user.name"]; !ok {
+		t.Fatal("expected the baseline value of user.name")
+	}
+	if _, ok := result.Matrix.Entries["color.ui"]; ok {
+		t.Fatal("expected color.ui to be removed")
+	}
+	if _, ok := result.Baseline.Entries["color.ui"]; !ok {
+		t.Fatal("expected the removed color.ui in the baseline")
+	}
+	editor := result.Matrix.Entries["core.editor"]
+	if editor.Value != "nano" || editor.Source.Scope != ConfigScopeCommand || len(editor.Overrides) != 1 || editor.Overrides[0].Source.Scope != ConfigScopeEnv {
+		t.Fatalf("unexpected core.editor: %+v", editor)
+	}
+	if pager := result.Matrix.Entries["core.pager"]; pager.Source.File != other {
+		t.Fatalf("expected core.pager to be reported with its new file, got %+v", pager)
+	}
+	if _, ok := result.Matrix.Entries["core.bare"]; ok {
+		t.Fatal("expected unchanged local values to be left out")
+	}
+}
@@ -0,0 +1,501 @@
+package gitcfg
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StagedChangeKind selects what a staged change does when the queue is applied.
+type StagedChangeKind string
+
+const (
+	StagedWrite      StagedChangeKind = "write"
+	StagedRuleUpsert StagedChangeKind = "rule-upsert"
+	StagedRuleDelete StagedChangeKind = "rule-delete"
+)
+
+// StagedChange is an entry of the pending-changes queue. Write is set for writes and unsets,
+// Rule for rule edits; FilePath is the file a write targets.
+type StagedChange struct {
+	ID          string           `json:"id"`
+	Kind        StagedChangeKind `json:"kind"`
+	Description string           `json:"description"`
+	Write       *WriteRequest    `json:"write,omitempty"`
+	Rule        *IncludeRule     `json:"rule,omitempty"`
+	FilePath    string           `json:"filePath,omitempty"`
+	StagedAt    string           `json:"stagedAt"`
+}
+
+// StagedPreview is the combined effect of the queue. Files holds one change set per file,
+// without an ID, as a dry run would return it.
+type StagedPreview struct {
+	Changes []StagedChange `json:"changes"`
+	Files   []ChangeSet    `json:"files"`
+	Diff    string         `json:"diff"`
+}
+
+// BatchResult describes an applied queue.
+type BatchResult struct {
+	BatchID    string        `json:"batchId"`
+	ChangeSets []ChangeSet   `json:"changeSets"`
+	Rules      []IncludeRule `json:"rules,omitempty"`
+	// AuditWarnings lists rule changes that were applied but could not be audited.
+	AuditWarnings []string `json:"auditWarnings,omitempty"`
+}
+
+// stagedFile collects the staged writes to one file in queue order.
+type stagedFile struct {
+	edit configEdit
+	cs   ChangeSet
+	keys []string
+}
+
+// stagedFiles groups the writes of items by file, ordered by the first write to each file.
+// Rule edits become writes of their includeIf directives to the global config file.
+func (s *Service) stagedFiles(ctx context.Context, items []StagedChange) ([]*stagedFile, error) {
+	var files []*stagedFile
+	byPath := make(map[string]*stagedFile)
+	file := func(path string, cs ChangeSet) *stagedFile {
+		f, ok := byPath[path]
+		if !ok {
+			cs.Key = ""
+			cs.Operation = WriteOperationBatch
+			f = &stagedFile{edit: configEdit{path: path}, cs: cs}
+			byPath[path] = f
+			files = append(files, f)
+		}
+		return f
+	}
+
+	// Each rule edit is compared with the rule as the earlier items of the queue leave it.
+	var (
+		rules      map[string]IncludeRule
+		rulesPath  string
+		directives map[string]bool
+	)
+	for _, item := range items {
+		if item.Kind == StagedWrite {
+			req, edit, err := s.prepareWrite(ctx, *item.Write)
+			if err != nil {
+				return nil, fmt.Errorf("staged change %q: %w", item.Description, err)
+			}
+			f := file(edit.path, ChangeSet{RepositoryID: req.RepositoryID, Scope: req.Scope})
+			f.edit.ops = append(f.edit.ops, edit.ops...)
+			if err := f.expect(edit.expectedHash); err != nil {
+				return nil, fmt.Errorf("staged change %q: %w", item.Description, err)
+			}
+			f.keys = append(f.keys, req.Key)
+			continue
+		}
+
+		if rules == nil {
+			path, present, err := globalRuleDirectives()
+			if err != nil {
+				return nil, err
+			}
+			rulesPath, directives, rules = path, present, s.rulesByID()
+		}
+		stored, existed := rules[item.Rule.ID]
+		var next *IncludeRule
+		if item.Kind == StagedRuleUpsert {
+			next = item.Rule
+			rules[item.Rule.ID] = *item.Rule
+		} else {
+			delete(rules, item.Rule.ID)
+		}
+		ops := ruleOps(ruleState(stored, existed), next, directives)
+		if len(ops) == 0 {
+			continue
+		}
+		cs := ruleChangeSet(ruleState(stored, existed), next)
+		f := file(rulesPath, cs)
+		f.edit.ops = append(f.edit.ops, ops...)
+		f.keys = append(f.keys, cs.Key)
+	}
+	for _, file := range files {
+		// A change set names its key only when all of its writes touch the same one.
+		file.cs.Key = file.keys[0]
+		for _, key := range file.keys[1:] {
+			if key != file.cs.Key {
+				file.cs.Key = ""
+				break
+			}
+		}
+	}
+	return files, nil
+}
+
+// expect records that the file must have hash when the queue is applied.
+func (file *stagedFile) expect(hash string) error {
+	if hash == "" || hash == file.edit.expectedHash {
+		return nil
+	}
+	if file.edit.expectedHash != "" {
+		return fmt.Errorf("the staged changes to %s expect different versions of the file; stage them again", file.edit.path)
+	}
+	file.edit.expectedHash = hash
+	return nil
+}
+
+func (s *Service) stageChange(change StagedChange) StagedChange {
+	change.ID = uuid.NewString()
+	change.StagedAt = timestamp(time.Now())
+	s.mu.Lock()
+	s.staged = append(s.staged, change)
+	s.mu.Unlock()
+	return change
+}
+
+// StageWrite validates req and queues it instead of applying it. DryRun is ignored.
+func (s *Service) StageWrite(ctx context.Context, req WriteRequest) (StagedChange, error) {
+	select {
+	case <-ctx.Done():
+		return StagedChange{}, ctx.Err()
+	default:
+	}
+
+	req.DryRun = false
+	normalized, edit, err := s.prepareWrite(ctx, req)
+	if err != nil {
+		return StagedChange{}, err
+	}
+	description := fmt.Sprintf("%s %s", normalized.Operation, normalized.Key)
+	if normalized.Operation == WriteOperationSet || normalized.Operation == WriteOperationAdd {
+		value, _ := redactValue(normalized.Value)
+		description += " = " + value
+	}
+	return s.stageChange(StagedChange{
+		Kind:        StagedWrite,
+		Description: description,
+		Write:       &req,
+		FilePath:    edit.path,
+	}), nil
+}
+
+// StageRule queues storing rule, or deleting the rule with its ID when remove is set.
+func (s *Service) StageRule(ctx context.Context, rule IncludeRule, remove bool) (StagedChange, error) {
+	select {
+	case <-ctx.Done():
+		return StagedChange{}, ctx.Err()
+	default:
+	}
+
+	if remove {
+		s.mu.RLock()
+		stored, ok := s.includeRules[rule.ID]
+		s.mu.RUnlock()
+		if !ok {
+			return StagedChange{}, fmt.Errorf("rule %q not found", rule.ID)
+		}
+		return s.stageChange(StagedChange{
+			Kind:        StagedRuleDelete,
+			Description: "delete rule " + stored.Pattern,
+			Rule:        &stored,
+		}), nil
+	}
+
+	if rule.Pattern == "" {
+		return StagedChange{}, errors.New("pattern cannot be empty")
+	}
+	if rule.TargetPath == "" {
+		return StagedChange{}, errors.New("targetPath cannot be empty")
+	}
+	if rule.ID == "" {
+		rule.ID = uuid.NewString()
+	}
+	return s.stageChange(StagedChange{
+		Kind:        StagedRuleUpsert,
+		Description: fmt.Sprintf("include %s for %s", rule.TargetPath, rule.Pattern),
+		Rule:        &rule,
+	}), nil
+}
+
+// ListStaged returns the queue in application order.
+func (s *Service) ListStaged() []StagedChange {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]StagedChange{}, s.staged...)
+}
+
+// ReorderStaged rearranges the queue; ids must list every staged change exactly once.
+func (s *Service) ReorderStaged(ids []string) ([]StagedChange, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(ids) != len(s.staged) {
+		return nil, fmt.Errorf("expected %d staged change IDs, got %d", len(s.staged), len(ids))
+	}
+	byID := make(map[string]StagedChange, len(s.staged))
+	for _, change := range s.staged {
+		byID[change.ID] = change
+	}
+	reordered := make([]StagedChange, 0, len(ids))
+	for _, id := range ids {
+		change, ok := byID[id]
+		if !ok {
+			return nil, fmt.Errorf("staged change %q not found or listed twice", id)
+		}
+		delete(byID, id)
+		reordered = append(reordered, change)
+	}
+	s.staged = reordered
+	return append([]StagedChange{}, reordered...), nil
+}
+
+// DropStaged removes one change from the queue.
+func (s *Service) DropStaged(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, change := range s.staged {
+		if change.ID == id {
+			s.staged = append(s.staged[:i:i], s.staged[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("staged change %q not found", id)
+}
+
+// ClearStaged empties the queue.
+func (s *Service) ClearStaged() {
+	s.mu.Lock()
+	s.staged = nil
+	s.mu.Unlock()
+}
+
+// PreviewStaged returns the combined diff of the queue without touching any file.
+func (s *Service) PreviewStaged(ctx context.Context) (StagedPreview, error) {
+	select {
+	case <-ctx.Done():
+		return StagedPreview{}, ctx.Err()
+	default:
+	}
+
+	items := s.ListStaged()
+	files, err := s.stagedFiles(ctx, items)
+	if err != nil {
+		return StagedPreview{}, err
+	}
+
+	preview := StagedPreview{Changes: items, Files: []ChangeSet{}}
+	var diff strings.Builder
+	for _, file := range files {
+		cs, err := s.applyEdit(ctx, file.cs, file.edit, true)
+		if err != nil {
+			return StagedPreview{}, err
+		}
+		preview.Files = append(preview.Files, cs)
+		diff.WriteString(cs.Diff)
+	}
+	preview.Diff = diff.String()
+	return preview, nil
+}
+
+// ApplyStaged applies the whole queue as one transaction. baseHashes maps the files of a
+// PreviewStaged result to their BaseHash; when given, it must cover exactly the files of the
+// queue. Every target file is locked with git's lock protocol first and checked against those
+// hashes and the ExpectedHash of its writes; if writing any file fails, the files already
+// written are restored and the queue is kept. Rules are stored once all files, including the
+// includeIf directives of rule edits, are written.
+func (s *Service) ApplyStaged(ctx context.Context, baseHashes map[string]string) (BatchResult, error) {
+	select {
+	case <-ctx.Done():
+		return BatchResult{}, ctx.Err()
+	default:
+	}
+
+	items := s.ListStaged()
+	if len(items) == 0 {
+		return BatchResult{}, errors.New("no staged changes")
+	}
+	files, err := s.stagedFiles(ctx, items)
+	if err != nil {
+		return BatchResult{}, err
+	}
+	if len(baseHashes) > 0 {
+		if len(baseHashes) != len(files) {
+			return BatchResult{}, errors.New("the staged changes changed since the preview; preview them again")
+		}
+		for _, file := range files {
+			hash, ok := baseHashes[file.edit.path]
+			if !ok {
+				return BatchResult{}, errors.New("the staged changes changed since the preview; preview them again")
+			}
+			if err := file.expect(hash); err != nil {
+				return BatchResult{}, err
+			}
+		}
+	}
+
+	paths := make([]string, 0, len(files))
+	for _, file := range files {
+		paths = append(paths, file.edit.path)
+	}
+	sort.Strings(paths)
+	locks := make(map[string]*fileLock, len(paths))
+	for _, path := range paths {
+		lock, err := lockFile(path)
+		if err != nil {
+			return BatchResult{}, err
+		}
+		defer lock.release()
+		locks[path] = lock
+	}
+
+	changes := make([]fileChange, 0, len(files))
+	for _, file := range files {
+		before, after, err := previewEdit(ctx, file.edit)
+		if err != nil {
+			return BatchResult{}, err
+		}
+		if err := checkExpectedHash(file.edit, before); err != nil {
+			return BatchResult{}, err
+		}
+		_, statErr := os.Stat(file.edit.path)
+		changes = append(changes, fileChange{path: file.edit.path, before: before, after: after, existed: statErr == nil, lock: locks[file.edit.path]})
+	}
+
+	result := BatchResult{BatchID: uuid.NewString()}
+	var changeSets []ChangeSet
+	var written []fileChange
+	for i, file := range files {
+		if bytes.Equal(changes[i].before, changes[i].after) && changes[i].existed {
+			continue
+		}
+		cs := file.cs
+		cs.BatchID = result.BatchID
+		changeSets = append(changeSets, cs)
+		written = append(written, changes[i])
+	}
+	if result.ChangeSets, err = s.recordBatch(changeSets, written); err != nil {
+		return BatchResult{}, err
+	}
+
+	applied := make(map[string]bool, len(items))
+	var deleted []IncludeRule
+	var ruleEdits []editRule
+	s.mu.Lock()
+	for _, item := range items {
+		applied[item.ID] = true
+		if item.Rule == nil {
+			continue
+		}
+		previous, existed := s.includeRules[item.Rule.ID]
+		switch item.Kind {
+		case StagedRuleUpsert:
+			rule := *item.Rule
+			rule.LastUpdated = timestamp(time.Now())
+			s.includeRules[rule.ID] = rule
+			result.Rules = append(result.Rules, rule)
+			ruleEdits = append(ruleEdits, editRule{id: rule.ID, before: ruleState(previous, existed), after: ruleState(rule, true)})
+		case StagedRuleDelete:
+			delete(s.includeRules, item.Rule.ID)
+			deleted = append(deleted, *item.Rule)
+			ruleEdits = append(ruleEdits, editRule{id: item.Rule.ID, before: ruleState(previous, existed)})
+		}
+	}
+	if len(ruleEdits) > 0 {
+		s.pushEditStepLocked(editStep{label: "apply staged changes", batchID: result.BatchID, rules: ruleEdits})
+	}
+	remaining := s.staged[:0:0]
+	for _, change := range s.staged {
+		if !applied[change.ID] {
+			remaining = append(remaining, change)
+		}
+	}
+	s.staged = remaining
+	s.mu.Unlock()
+
+	var errs []error
+	for _, rule := range result.Rules {
+		errs = append(errs, s.auditRule(AuditRuleUpsert, rule))
+	}
+	for _, rule := range deleted {
+		errs = append(errs, s.auditRule(AuditRuleDelete, rule))
+	}
+	result.AuditWarnings = auditWarnings(errs)
+	return result, nil
+}
+
+// recordBatch records changes[i] as changeSets[i], in order. If one of them fails, the files
+// already written are restored and the error is returned.
+func (s *Service) recordBatch(changeSets []ChangeSet, changes []fileChange) ([]ChangeSet, error) {
+	recorded := []ChangeSet{}
+	for i, cs := range changeSets {
+		written, err := s.recordChange(cs, changes[i])
+		if err != nil {
+			if undoErr := s.undoBatch(recorded, changes); undoErr != nil {
+				return nil, fmt.Errorf("%w; restoring the files already written failed: %v", err, undoErr)
+			}
+			return nil, err
+		}
+		recorded = append(recorded, written)
+	}
+	return recorded, nil
+}
+
+// undoBatch restores the files of the change sets recorded so far and forgets them. A file
+// that no longer holds what the batch wrote is left alone, so an edit made in between is kept.
+func (s *Service) undoBatch(recorded []ChangeSet, changes []fileChange) error {
+	before := make(map[string]fileChange, len(changes))
+	for _, change := range changes {
+		before[change.path] = change
+	}
+
+	if len(recorded) > 0 {
+		s.mu.Lock()
+		s.forgetBatchLocked(recorded[0].BatchID)
+		s.mu.Unlock()
+	}
+
+	var errs []error
+	for i := len(recorded) - 1; i >= 0; i-- {
+		cs := recorded[i]
+		change := before[cs.FilePath]
+		if err := restoreUnchanged(change); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		s.mu.Lock()
+		delete(s.changeSets, cs.ID)
+		s.mu.Unlock()
+		if err := s.dropBackups(cs.ID); err != nil {
+			errs = append(errs, err)
+		}
+		// The log is append-only, so the restore is recorded rather than the entry removed.
+		undo := fileChange{path: cs.FilePath, before: change.after, after: change.before, existed: true, remove: !change.existed}
+		cs.Operation = AuditBatchUndo
+		cs.Diff = redactDiff(unifiedDiff(cs.FilePath, string(change.after), string(change.before)))
+		if err := s.auditChange(cs, undo); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// restoreUnchanged puts back the contents change replaced, provided the file still holds the
+// contents change wrote.
+func restoreUnchanged(change fileChange) error {
+	lock, err := lockFile(change.path)
+	if err != nil {
+		return err
+	}
+	current, err := os.ReadFile(change.path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		lock.release()
+		return fmt.Errorf("read %s: %w", change.path, err)
+	}
+	if exists := err == nil; exists == change.remove || !bytes.Equal(current, change.after) {
+		lock.release()
+		return fmt.Errorf("%s was changed by someone else after it was written; it was not restored", change.path)
+	}
+	return lock.commit(change.before, change.existed)
+}
@@ -0,0 +1,194 @@
+package gitcfg
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStagedQueueApply(t *testing.T) {
+	s := newTestService(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.gitconfig")
+	second := filepath.Join(dir, "second.gitconfig")
+	global := filepath.Join(dir, "gitconfig")
+	writeTestFile(t, first, "[user]\n\tname = Alice\n")
+	t.Setenv("GIT_CONFIG_GLOBAL", global)
+
+	name, err := s.StageWrite(ctx, WriteRequest{TargetPath: first, Key: "user.name", Value: "Bob", DryRun: true})
+	if err != nil {
+		t.Fatalf("StageWrite returned error: %v", err)
+	}
+	email, err := s.StageWrite(ctx, WriteRequest{TargetPath: second, Key: "user.email", Value: "bob@example.com"})
+	if err != nil {
+		t.Fatalf("StageWrite returned error: %v", err)
+	}
+	dropped, err := s.StageWrite(ctx, WriteRequest{TargetPath: first, Key: "core.editor", Value: "vim"})
+	if err != nil {
+		t.Fatalf("StageWrite returned error: %v", err)
+	}
+	rename, err := s.StageWrite(ctx, WriteRequest{TargetPath: first, Key: "user.name", Value: "Carol"})
+	if err != nil {
+		t.Fatalf("StageWrite returned error: %v", err)
+	}
+	rule, err := s.StageRule(ctx, IncludeRule{Pattern: "~/work/", TargetPath: second, Enabled: true}, false)
+	if err != nil {
+		t.Fatalf("StageRule returned error: %v", err)
+	}
+	if _, err := s.StageWrite(ctx, WriteRequest{TargetPath: first, Key: "invalid"}); err == nil {
+		t.Fatal("expected an invalid write to be rejected when staged")
+	}
+
+	if err := s.DropStaged(dropped.ID); err != nil {
+		t.Fatalf("DropStaged returned error: %v", err)
+	}
+	if _, err := s.ReorderStaged([]string{rename.ID, email.ID}); err == nil {
+		t.Fatal("expected an incomplete order to be rejected")
+	}
+	if _, err := s.ReorderStaged([]string{rename.ID, email.ID, name.ID, rule.ID}); err != nil {
+		t.Fatalf("ReorderStaged returned error: %v", err)
+	}
+
+	preview, err := s.PreviewStaged(ctx)
+	if err != nil {
+		t.Fatalf("PreviewStaged returned error: %v", err)
+	}
+	if len(preview.Files) != 3 || !preview.Files[1].Created || preview.Files[2].FilePath != global || !strings.Contains(preview.Diff, "+\tname = Bob") || strings.Contains(preview.Diff, "editor") {
+		t.Fatalf("unexpected preview: %+v", preview)
+	}
+	if _, err := os.Stat(second); !os.IsNotExist(err) {
+		t.Fatal("expected the preview to leave files untouched")
+	}
+
+	baseHashes := make(map[string]string)
+	for _, file := range preview.Files {
+		baseHashes[file.FilePath] = file.BaseHash
+	}
+	original, err := os.ReadFile(first)
+	if err != nil {
+		t.Fatalf("read %s: %v", first, err)
+	}
+	writeTestFile(t, first, string(original)+"# edited\n")
+	if _, err := s.ApplyStaged(ctx, baseHashes); err == nil || !strings.Contains(err.Error(), "changed since the preview") {
+		t.Fatalf("expected a file changed since the preview to be refused, got %v", err)
+	}
+	writeTestFile(t, first, string(original))
+	if _, err := s.ApplyStaged(ctx, map[string]string{first: baseHashes[first]}); err == nil {
+		t.Fatal("expected hashes that do not cover the queue to be refused")
+	}
+
+	result, err := s.ApplyStaged(ctx, baseHashes)
+	if err != nil {
+		t.Fatalf("ApplyStaged returned error: %v", err)
+	}
+	if len(result.ChangeSets) != 3 || len(result.Rules) != 1 {
+		t.Fatalf("unexpected batch result: %+v", result)
+	}
+	for _, cs := range result.ChangeSets {
+		if cs.BatchID != result.BatchID || cs.Operation != WriteOperationBatch {
+			t.Fatalf("expected change sets to carry the batch, got %+v", cs)
+		}
+	}
+	if got := gitConfigGet(t, dir, "--file", first, "user.name"); got != "Bob" {
+		t.Fatalf("expected the reordered writes to leave Bob, got %q", got)
+	}
+	if got := gitConfigGet(t, dir, "--file", second, "user.email"); got != "bob@example.com" {
+		t.Fatalf("expected user.email in the second file, got %q", got)
+	}
+	if got := gitConfigGet(t, dir, "--file", global, "includeIf.gitdir:~/work/.path"); got != second {
+		t.Fatalf("expected the staged rule to write its include, got %q", got)
+	}
+	if len(s.ListStaged()) != 0 {
+		t.Fatal("expected the queue to be empty after applying")
+	}
+	if _, err := os.Stat(first + ".lock"); !os.IsNotExist(err) {
+		t.Fatal("expected locks to be released")
+	}
+	if rules, _ := s.ListRules(ctx); len(rules) != 1 {
+		t.Fatalf("expected the staged rule to be stored, got %+v", rules)
+	}
+}
+
+func TestStagedConflictingExpectedHashes(t *testing.T) {
+	s := newTestService(t)
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "profile.gitconfig")
+	writeTestFile(t, path, "[user]\n\tname = Alice\n")
+
+	for _, req := range []WriteRequest{
+		{TargetPath: path, Key: "user.name", Value: "Bob", ExpectedHash: contentHash([]byte("[user]\n\tname = Alice\n"))},
+		{TargetPath: path, Key: "user.email", Value: "bob@example.com", ExpectedHash: contentHash([]byte("other"))},
+	} {
+		if _, err := s.StageWrite(ctx, req); err != nil {
+			t.Fatalf("StageWrite returned error: %v", err)
+		}
+	}
+	if _, err := s.ApplyStaged(ctx, nil); err == nil || !strings.Contains(err.Error(), "different versions") {
+		t.Fatalf("expected conflicting expected hashes to be refused, got %v", err)
+	}
+}
+
+func TestApplyStagedRollsBackOnFailure(t *testing.T) {
+	s := newTestService(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+	created := filepath.Join(dir, "a.gitconfig")
+	existing := filepath.Join(dir, "b.gitconfig")
+	writeTestFile(t, existing, "[user]\n\tname = Alice\n")
+
+	// Backups cannot be written below a regular file, so the second file fails after the
+	// first one has been created.
+	s.dataDir = filepath.Join(dir, "not-a-directory")
+	writeTestFile(t, s.dataDir, "")
+
+	for _, req := range []WriteRequest{
+		{TargetPath: created, Key: "user.email", Value: "alice@example.com"},
+		{TargetPath: existing, Key: "user.name", Value: "Bob"},
+	} {
+		if _, err := s.StageWrite(ctx, req); err != nil {
+			t.Fatalf("StageWrite returned error: %v", err)
+		}
+	}
+
+	if _, err := s.ApplyStaged(ctx, nil); err == nil {
+		t.Fatal("expected ApplyStaged to fail")
+	}
+	if _, err := os.Stat(created); !os.IsNotExist(err) {
+		t.Fatal("expected the created file to be removed again")
+	}
+	if got := gitConfigGet(t, dir, "--file", existing, "user.name"); got != "Alice" {
+		t.Fatalf("expected the existing file to be untouched, got %q", got)
+	}
+	if len(s.ListStaged()) != 2 || len(s.ListChangeSets("")) != 0 {
+		t.Fatal("expected the queue to be kept and no change set to be recorded")
+	}
+
+	writeTestFile(t, existing+".lock", "")
+	if _, err := s.ApplyStaged(ctx, nil); err == nil || !strings.Contains(err.Error(), "locked") {
+		t.Fatalf("expected a held lock to stop the batch, got %v", err)
+	}
+}
+
+func TestRestoreUnchangedKeepsLaterEdits(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "profile.gitconfig")
+	change := fileChange{path: path, before: []byte("[user]\n\tname = Alice\n"), after: []byte("[user]\n\tname = Bob\n"), existed: true}
+
+	writeTestFile(t, path, "[user]\n\tname = Carol\n")
+	if err := restoreUnchanged(change); err == nil || !strings.Contains(err.Error(), "not restored") {
+		t.Fatalf("expected an edited file to be left alone, got %v", err)
+	}
+	if got := gitConfigGet(t, filepath.Dir(path), "--file", path, "user.name"); got != "Carol" {
+		t.Fatalf("expected the later edit to be kept, got %q", got)
+	}
+
+	writeTestFile(t, path, string(change.after))
+	if err := restoreUnchanged(change); err != nil {
+		t.Fatalf("restoreUnchanged returned error: %v", err)
+	}
+	if got := gitConfigGet(t, filepath.Dir(path), "--file", path, "user.name"); got != "Alice" {
+		t.Fatalf("expected the previous contents to be restored, got %q", got)
+	}
+}
@@ -25,6 +25,12 @@ type Repository struct {
 	GitDir       string         `json:"gitDir"`
 	LastScanTime string         `json:"lastScanTime"`
 	Status       RepoStatus     `json:"status"`
+	Remotes      []Remote       `json:"remotes,omitempty"`
+	// RemotesError explains why Remotes could not be read; the rest of the scan is unaffected.
+	RemotesError string `json:"remotesError,omitempty"`
+	// SafeDirectory is the path git asks to add to safe.directory when Status is
+	// RepoStatusDubiousOwnership.
+	SafeDirectory string `json:"safeDirectory,omitempty"`
 }
 
 // RepoStatus describes the freshness of a repository scan.
@@ -34,6 +40,9 @@ const (
 	RepoStatusIdle     RepoStatus = "idle"
 	RepoStatusScanning RepoStatus = "scanning"
 	RepoStatusError    RepoStatus = "error"
+	// RepoStatusDubiousOwnership marks repositories git refuses to open because they are owned
+	// by another user and not listed in safe.directory.
+	RepoStatusDubiousOwnership RepoStatus = "dubious-ownership"
 )
 
 // ConfigScope represents the scope layer of a git configuration value.
@@ -46,6 +55,8 @@ const (
 	ConfigScopeLocal    ConfigScope = "local"
 	ConfigScopeWorktree ConfigScope = "worktree"
 	ConfigScopeEnv      ConfigScope = "env"
+	// ConfigScopeCommand is what git reports for -c options and GIT_CONFIG_COUNT entries.
+	ConfigScopeCommand ConfigScope = "command"
 )
 
 // ConfigSource tracks where a value originates from for provenance information.
@@ -53,19 +64,34 @@ type ConfigSource struct {
 	Scope ConfigScope `json:"scope"`
 	File  string      `json:"file,omitempty"`
 	Line  int         `json:"line,omitempty"`
+	// Order is the position of the value among everything git read, which orders values of
+	// different keys the way git saw them.
+	Order int `json:"order,omitempty"`
+	// IncludeChain lists the include directives, outermost first, that led git to File.
+	// It is empty for values read from a top-level config file.
+	IncludeChain []IncludeLink `json:"includeChain,omitempty"`
 }
 
 // ConfigOverride captures values that were overridden by a higher priority scope.
 type ConfigOverride struct {
-	Value     string       `json:"value"`
-	Source    ConfigSource `json:"source"`
-	Timestamp string       `json:"timestamp"`
+	Value      string       `json:"value"`
+	Normalized string       `json:"normalized,omitempty"`
+	Source     ConfigSource `json:"source"`
+	Timestamp  string       `json:"timestamp"`
 }
 
 // ConfigValue represents the resolved value and the provenance metadata for a key.
+// Value holds the raw string from the config file; Normalized is its interpretation
+// according to Type, and TypeError explains why a typed value could not be interpreted.
+// Redacted is set when credentials in the key or any value were masked.
 type ConfigValue struct {
 	Key          string           `json:"key"`
+	DisplayKey   string           `json:"displayKey,omitempty"`
 	Value        string           `json:"value"`
+	Type         ValueType        `json:"type"`
+	Normalized   string           `json:"normalized,omitempty"`
+	TypeError    string           `json:"typeError,omitempty"`
+	Redacted     bool             `json:"redacted,omitempty"`
 	Source       ConfigSource     `json:"source"`
 	Overrides    []ConfigOverride `json:"overrides,omitempty"`
 	LastModified string           `json:"lastModified"`
@@ -95,24 +121,45 @@ type RuleConflict struct {
 }
 
 // WriteRequest contains the inputs for writing or updating configuration.
+// MatchValue restricts set and unset operations to the value equal to it, which is needed
+// to address a single entry of a multi-valued key.
 type WriteRequest struct {
-	RepositoryID string      `json:"repositoryId"`
-	Scope        ConfigScope `json:"scope"`
-	Key          string      `json:"key"`
-	Value        string      `json:"value"`
-	TargetPath   string      `json:"targetPath,omitempty"`
-	DryRun       bool        `json:"dryRun"`
+	RepositoryID string         `json:"repositoryId"`
+	Scope        ConfigScope    `json:"scope"`
+	Key          string         `json:"key"`
+	Value        string         `json:"value"`
+	Operation    WriteOperation `json:"operation,omitempty"`
+	MatchValue   string         `json:"matchValue,omitempty"`
+	TargetPath   string         `json:"targetPath,omitempty"`
+	DryRun       bool           `json:"dryRun"`
+	// ExpectedHash is the BaseHash of a previous dry run; the write is refused if the file
+	// has changed since.
+	ExpectedHash string `json:"expectedHash,omitempty"`
 }
 
 // ChangeSet describes the diff generated by a rewrite along with backup metadata.
+// Created is set when the change created FilePath, in which case there is no backup.
 type ChangeSet struct {
-	ID           string      `json:"id"`
-	RepositoryID string      `json:"repositoryId"`
-	Scope        ConfigScope `json:"scope"`
-	FilePath     string      `json:"filePath"`
-	Diff         string      `json:"diff"`
-	BackupPath   string      `json:"backupPath"`
-	CreatedAt    string      `json:"createdAt"`
+	ID           string         `json:"id"`
+	RepositoryID string         `json:"repositoryId"`
+	Scope        ConfigScope    `json:"scope"`
+	Key          string         `json:"key,omitempty"`
+	Operation    WriteOperation `json:"operation,omitempty"`
+	FilePath     string         `json:"filePath"`
+	Diff         string         `json:"diff"`
+	BackupPath   string         `json:"backupPath"`
+	// BaseHash is the hash of the file the diff was computed against.
+	BaseHash string `json:"baseHash,omitempty"`
+	// AfterHash is the hash of the contents the change left behind; it is empty when the change
+	// removed the file.
+	AfterHash string `json:"afterHash,omitempty"`
+	Created   bool   `json:"created,omitempty"`
+	RevertsID string `json:"revertsId,omitempty"`
+	// BatchID groups the change sets applied together from the staged queue.
+	BatchID   string `json:"batchId,omitempty"`
+	CreatedAt string `json:"createdAt"`
+	// AuditWarning is set when the change was written but could not be added to the audit log.
+	AuditWarning string `json:"auditWarning,omitempty"`
 }
 
 // DiagnosticsReport contains parity information between internal parsing and git CLI output.
@@ -0,0 +1,392 @@
+package gitcfg
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"time"
+)
+
+// maxEditSteps bounds the undo history of a session.
+const maxEditSteps = 100
+
+// EditStep summarises one entry of the undo or redo stack. A step covers everything one
+// operation changed: a write, a rollback, a rule edit or a whole staged batch.
+type EditStep struct {
+	Label        string   `json:"label"`
+	Files        []string `json:"files,omitempty"`
+	RuleIDs      []string `json:"ruleIds,omitempty"`
+	Roots        []string `json:"roots,omitempty"`
+	ChangeSetIDs []string `json:"changeSetIds,omitempty"`
+	BatchID      string   `json:"batchId,omitempty"`
+	At           string   `json:"at"`
+}
+
+// EditHistory lists the steps Undo and Redo would apply, next one first.
+type EditHistory struct {
+	Undo []EditStep `json:"undo"`
+	Redo []EditStep `json:"redo"`
+}
+
+// EditResult describes an undone or redone step and the change sets recording it.
+type EditResult struct {
+	Step       EditStep    `json:"step"`
+	ChangeSets []ChangeSet `json:"changeSets"`
+	// AuditWarnings lists rule changes that were replayed but could not be audited.
+	AuditWarnings []string `json:"auditWarnings,omitempty"`
+}
+
+// editFile is the contents of one file before and after a step.
+type editFile struct {
+	path          string
+	before        []byte
+	after         []byte
+	existedBefore bool
+	existsAfter   bool
+	cs            ChangeSet
+}
+
+// editRule is the state of one include rule before and after a step; nil means absent.
+type editRule struct {
+	id     string
+	before *IncludeRule
+	after  *IncludeRule
+}
+
+type editStep struct {
+	label   string
+	batchID string
+	at      string
+	// seq orders steps by when they were pushed, see Service.editSeq.
+	seq   uint64
+	files []editFile
+	rules []editRule
+	// roots lists the scanning roots the step added.
+	roots []string
+}
+
+func (step *editStep) summary() EditStep {
+	out := EditStep{Label: step.label, BatchID: step.batchID, At: step.at}
+	for _, file := range step.files {
+		out.Files = append(out.Files, file.path)
+		out.ChangeSetIDs = append(out.ChangeSetIDs, file.cs.ID)
+	}
+	for _, rule := range step.rules {
+		out.RuleIDs = append(out.RuleIDs, rule.id)
+	}
+	out.Roots = step.roots
+	return out
+}
+
+// ruleState returns a copy of rule when ok is set.
+func ruleState(rule IncludeRule, ok bool) *IncludeRule {
+	if !ok {
+		return nil
+	}
+	return &rule
+}
+
+// sameRuleState reports whether a rule is still in the state a step left it in.
+func sameRuleState(a, b *IncludeRule) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Pattern == b.Pattern && a.TargetPath == b.TargetPath && a.Enabled == b.Enabled && a.LastUpdated == b.LastUpdated
+}
+
+// mergeEditRules appends more to rules, folding changes to the same rule into one that goes
+// from its first state to its last.
+func mergeEditRules(rules, more []editRule) []editRule {
+	for _, rule := range more {
+		i := slices.IndexFunc(rules, func(r editRule) bool { return r.id == rule.id })
+		if i < 0 {
+			rules = append(rules, rule)
+			continue
+		}
+		rules[i].after = rule.after
+	}
+	return rules
+}
+
+// pushEditStepLocked adds step to the undo stack, merging it into the newest step of the same
+// batch, and invalidates the redo stack. s.mu must be held.
+func (s *Service) pushEditStepLocked(step editStep) {
+	s.redo = nil
+	s.editSeq++
+	if n := len(s.undo); step.batchID != "" && n > 0 && s.undo[n-1].batchID == step.batchID {
+		top := s.undo[n-1]
+		// Rule changes name the step after the rule rather than the file write applying it.
+		if len(step.rules) > 0 {
+			top.label = step.label
+		}
+		top.files = append(top.files, step.files...)
+		top.rules = mergeEditRules(top.rules, step.rules)
+		top.roots = append(top.roots, step.roots...)
+		return
+	}
+	step.rules = mergeEditRules(nil, step.rules)
+	step.at = timestamp(time.Now())
+	step.seq = s.editSeq
+	s.undo = append(s.undo, &step)
+	s.trimUndoLocked()
+}
+
+func (s *Service) trimUndoLocked() {
+	if len(s.undo) > maxEditSteps {
+		s.undo = s.undo[len(s.undo)-maxEditSteps:]
+	}
+}
+
+// restackStepLocked puts a step taken off a stack when the edit sequence stood at claimed
+// onto the undo stack, below the steps pushed since, or onto the redo stack, unless a newer
+// edit has emptied it. s.mu must be held.
+func (s *Service) restackStepLocked(step *editStep, toUndo bool, claimed uint64) {
+	if !toUndo {
+		if s.editSeq == claimed {
+			s.redo = append(s.redo, step)
+		}
+		return
+	}
+	i := slices.IndexFunc(s.undo, func(other *editStep) bool { return other.seq > claimed })
+	if i < 0 {
+		i = len(s.undo)
+	}
+	s.undo = slices.Insert(s.undo, i, step)
+	s.trimUndoLocked()
+}
+
+// rememberChange records a file change made by an operation so that it can be undone.
+func (s *Service) rememberChange(cs ChangeSet, change fileChange) {
+	if cs.Operation == WriteOperationUndo || cs.Operation == WriteOperationRedo {
+		return
+	}
+	label := fmt.Sprintf("%s %s", cs.Operation, cs.Key)
+	switch {
+	case cs.Operation == WriteOperationImport:
+		label = "import archive"
+	case cs.Operation == WriteOperationBatch:
+		label = "apply staged changes"
+	case cs.Key == "":
+		label = fmt.Sprintf("%s %s", cs.Operation, filepath.Base(cs.FilePath))
+	}
+	s.mu.Lock()
+	s.pushEditStepLocked(editStep{
+		label:   label,
+		batchID: cs.BatchID,
+		files: []editFile{{
+			path:          change.path,
+			before:        change.before,
+			after:         change.after,
+			existedBefore: change.existed,
+			existsAfter:   !change.remove,
+			cs:            cs,
+		}},
+	})
+	s.mu.Unlock()
+}
+
+// forgetBatchLocked drops the step of a batch that was undone because it failed. s.mu must
+// be held.
+func (s *Service) forgetBatchLocked(batchID string) {
+	if n := len(s.undo); batchID != "" && n > 0 && s.undo[n-1].batchID == batchID {
+		s.undo = s.undo[:n-1]
+	}
+}
+
+// GetEditHistory returns the undo and redo stacks of the session.
+func (s *Service) GetEditHistory() EditHistory {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	history := EditHistory{Undo: []EditStep{}, Redo: []EditStep{}}
+	for i := len(s.undo) - 1; i >= 0; i-- {
+		history.Undo = append(history.Undo, s.undo[i].summary())
+	}
+	for i := len(s.redo) - 1; i >= 0; i-- {
+		history.Redo = append(history.Redo, s.redo[i].summary())
+	}
+	return history
+}
+
+// Undo reverts the most recent step of the session. Every file the step touched must still
+// hold the contents the step left behind; otherwise nothing is changed.
+func (s *Service) Undo(ctx context.Context) (EditResult, error) {
+	return s.replayEdit(ctx, true)
+}
+
+// Redo reapplies the most recently undone step. Any new edit empties the redo stack.
+func (s *Service) Redo(ctx context.Context) (EditResult, error) {
+	return s.replayEdit(ctx, false)
+}
+
+func (s *Service) replayEdit(ctx context.Context, undo bool) (EditResult, error) {
+	select {
+	case <-ctx.Done():
+		return EditResult{}, ctx.Err()
+	default:
+	}
+
+	s.replayMu.Lock()
+	defer s.replayMu.Unlock()
+
+	operation, from := WriteOperationRedo, &s.redo
+	if undo {
+		operation, from = WriteOperationUndo, &s.undo
+	}
+	s.mu.RLock()
+	if len(*from) == 0 {
+		s.mu.RUnlock()
+		return EditResult{}, fmt.Errorf("nothing to %s", operation)
+	}
+	step := (*from)[len(*from)-1]
+	s.mu.RUnlock()
+
+	changeSets, warnings, err := s.replayStep(step, operation)
+	if err != nil {
+		return EditResult{}, err
+	}
+	return EditResult{Step: step.summary(), ChangeSets: changeSets, AuditWarnings: warnings}, nil
+}
+
+// replayStep moves every file, rule and root of step to its state before the step when operation is
+// WriteOperationUndo, or after it otherwise. It returns the change sets recording the files and
+// the audit failures of its rules.
+func (s *Service) replayStep(step *editStep, operation WriteOperation) ([]ChangeSet, []string, error) {
+	undo := operation == WriteOperationUndo
+	files := append([]editFile{}, step.files...)
+	if undo {
+		for i, j := 0, len(files)-1; i < j; i, j = i+1, j-1 {
+			files[i], files[j] = files[j], files[i]
+		}
+	}
+
+	paths := make([]string, 0, len(files))
+	for _, file := range files {
+		paths = append(paths, file.path)
+	}
+	sort.Strings(paths)
+	locks := make(map[string]*fileLock, len(paths))
+	for _, path := range paths {
+		lock, err := lockFile(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		defer lock.release()
+		locks[path] = lock
+	}
+
+	changes := make([]fileChange, 0, len(files))
+	for _, file := range files {
+		expected, expectedExists, target, targetExists := file.after, file.existsAfter, file.before, file.existedBefore
+		if !undo {
+			expected, expectedExists, target, targetExists = file.before, file.existedBefore, file.after, file.existsAfter
+		}
+		current, err := os.ReadFile(file.path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, nil, fmt.Errorf("read %s: %w", file.path, err)
+		}
+		exists := err == nil
+		if exists != expectedExists || !bytes.Equal(current, expected) {
+			return nil, nil, fmt.Errorf("%s has changed since %q; %s would discard those changes", file.path, step.label, operation)
+		}
+		changes = append(changes, fileChange{path: file.path, before: current, after: target, existed: exists, remove: !targetExists, lock: locks[file.path]})
+	}
+
+	// The step is taken off its stack, and its rules moved, only once it is known to still be
+	// on top, so that an edit made meanwhile is neither undone out of order nor lost.
+	from := &s.redo
+	if undo {
+		from = &s.undo
+	}
+	s.mu.Lock()
+	if n := len(*from); n == 0 || (*from)[n-1] != step {
+		s.mu.Unlock()
+		return nil, nil, fmt.Errorf("the edit history changed while preparing to %s %q; try again", operation, step.label)
+	}
+	for _, rule := range step.rules {
+		expected := rule.after
+		if !undo {
+			expected = rule.before
+		}
+		current, ok := s.includeRules[rule.id]
+		if !sameRuleState(ruleState(current, ok), expected) {
+			s.mu.Unlock()
+			return nil, nil, fmt.Errorf("rule %q has changed since %q; %s would discard those changes", rule.id, step.label, operation)
+		}
+	}
+	*from = (*from)[:len(*from)-1]
+	claimed := s.editSeq
+	s.moveRulesLocked(step, undo)
+	s.mu.Unlock()
+
+	changeSets := make([]ChangeSet, 0, len(files))
+	for _, file := range files {
+		cs := ChangeSet{
+			RepositoryID: file.cs.RepositoryID,
+			Scope:        file.cs.Scope,
+			Key:          file.cs.Key,
+			Operation:    operation,
+		}
+		if undo {
+			cs.RevertsID = file.cs.ID
+		}
+		changeSets = append(changeSets, cs)
+	}
+	recorded, err := s.recordBatch(changeSets, changes)
+	s.mu.Lock()
+	if err != nil {
+		s.moveRulesLocked(step, !undo)
+		s.restackStepLocked(step, undo, claimed)
+		s.mu.Unlock()
+		return nil, nil, err
+	}
+	s.restackStepLocked(step, !undo, claimed)
+	s.mu.Unlock()
+
+	var errs []error
+	for _, rule := range step.rules {
+		current, target := rule.after, rule.before
+		if !undo {
+			current, target = rule.before, rule.after
+		}
+		switch {
+		case target != nil:
+			errs = append(errs, s.auditRule(AuditRuleUpsert, *target))
+		case current != nil:
+			errs = append(errs, s.auditRule(AuditRuleDelete, *current))
+		}
+	}
+	return recorded, auditWarnings(errs), nil
+}
+
+// moveRulesLocked sets the rules and roots of step to their state before it when undo is set,
+// or after it otherwise. A rule that is not in the opposite state, because it was edited
+// meanwhile, is left alone. s.mu must be held.
+func (s *Service) moveRulesLocked(step *editStep, undo bool) {
+	for _, root := range step.roots {
+		if undo {
+			delete(s.roots, root)
+		} else {
+			s.roots[root] = struct{}{}
+		}
+	}
+	for _, rule := range step.rules {
+		current, target := rule.before, rule.after
+		if undo {
+			current, target = rule.after, rule.before
+		}
+		if stored, ok := s.includeRules[rule.id]; !sameRuleState(ruleState(stored, ok), current) {
+			continue
+		}
+		if target == nil {
+			delete(s.includeRules, rule.id)
+		} else {
+			s.includeRules[rule.id] = *target
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package gitcfg
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestUndoRedoWrites(t *testing.T) {
+	s := newTestService(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "profile.gitconfig")
+
+	for _, name := range []string{"Alice", "Bob"} {
+		if _, err := s.WriteConfig(ctx, WriteRequest{TargetPath: path, Key: "user.name", Value: name}); err != nil {
+			t.Fatalf("WriteConfig returned error: %v", err)
+		}
+	}
+	if _, err := s.Redo(ctx); err == nil {
+		t.Fatal("expected Redo to fail with nothing undone")
+	}
+
+	result, err := s.Undo(ctx)
+	if err != nil {
+		t.Fatalf("Undo returned error: %v", err)
+	}
+	if got := gitConfigGet(t, dir, "--file", path, "user.name"); got != "Alice" {
+		t.Fatalf("expected undo to restore Alice, got %q", got)
+	}
+	if len(result.ChangeSets) != 1 || result.ChangeSets[0].Operation != WriteOperationUndo || result.Step.Label != "set user.name" {
+		t.Fatalf("unexpected undo result: %+v", result)
+	}
+	if _, err := s.Undo(ctx); err != nil {
+		t.Fatalf("Undo returned error: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatal("expected undoing the first write to remove the file it created")
+	}
+	if _, err := s.Undo(ctx); err == nil {
+		t.Fatal("expected Undo to fail once the history is exhausted")
+	}
+
+	if _, err := s.Redo(ctx); err != nil {
+		t.Fatalf("Redo returned error: %v", err)
+	}
+	if got := gitConfigGet(t, dir, "--file", path, "user.name"); got != "Alice" {
+		t.Fatalf("expected redo to reapply Alice, got %q", got)
+	}
+	if history := s.GetEditHistory(); len(history.Undo) != 1 || len(history.Redo) != 1 {
+		t.Fatalf("expected one step on each stack, got %+v", history)
+	}
+
+	if _, err := s.WriteConfig(ctx, WriteRequest{TargetPath: path, Key: "user.name", Value: "Carol"}); err != nil {
+		t.Fatalf("WriteConfig returned error: %v", err)
+	}
+	if history := s.GetEditHistory(); len(history.Undo) != 2 || len(history.Redo) != 0 {
+		t.Fatalf("expected a new edit to invalidate redo, got %+v", history)
+	}
+
+	runGit(t, dir, "config", "--file", path, "user.name", "Dave")
+	if _, err := s.Undo(ctx); err == nil || !strings.Contains(err.Error(), "has changed") {
+		t.Fatalf("expected an external edit to block undo, got %v", err)
+	}
+	if got := gitConfigGet(t, dir, "--file", path, "user.name"); got != "Dave" {
+		t.Fatalf("expected the external edit to be kept, got %q", got)
+	}
+}
+
+func TestUndoRedoSpansFilesAndRules(t *testing.T) {
+	s := newTestService(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+	global := filepath.Join(dir, "gitconfig")
+	profile := filepath.Join(dir, "work.gitconfig")
+	writeTestFile(t, global, "[core]\n\teditor = vim\n")
+	t.Setenv("GIT_CONFIG_GLOBAL", global)
+
+	rule, err := s.UpsertRule(ctx, IncludeRule{Pattern: "~/work/", TargetPath: profile, Enabled: true})
+	if err != nil {
+		t.Fatalf("UpsertRule returned error: %v", err)
+	}
+	updated := rule
+	updated.TargetPath = "~/other.gitconfig"
+	if _, err := s.StageWrite(ctx, WriteRequest{TargetPath: profile, Key: "user.email", Value: "alice@work.example"}); err != nil {
+		t.Fatalf("StageWrite returned error: %v", err)
+	}
+	if _, err := s.StageRule(ctx, updated, false); err != nil {
+		t.Fatalf("StageRule returned error: %v", err)
+	}
+	batch, err := s.ApplyStaged(ctx, nil)
+	if err != nil {
+		t.Fatalf("ApplyStaged returned error: %v", err)
+	}
+
+	history := s.GetEditHistory()
+	if len(history.Undo) != 2 || history.Undo[0].BatchID != batch.BatchID || len(history.Undo[0].Files) != 2 || len(history.Undo[0].RuleIDs) != 1 {
+		t.Fatalf("expected the batch to be one step, got %+v", history.Undo)
+	}
+	if got := gitConfigGet(t, dir, "--file", global, "--get-all", "includeIf.gitdir:~/work/.path"); got != updated.TargetPath {
+		t.Fatalf("expected the rule update to replace its include, got %q", got)
+	}
+
+	if _, err := s.Undo(ctx); err != nil {
+		t.Fatalf("Undo returned error: %v", err)
+	}
+	if got := gitConfigGet(t, dir, "--file", global, "--get-all", "includeIf.gitdir:~/work/.path"); got != profile {
+		t.Fatalf("expected undo to restore the previous include, got %q", got)
+	}
+	if _, err := os.Stat(profile); !os.IsNotExist(err) {
+		t.Fatal("expected the profile created by the batch to be removed")
+	}
+	rules, _ := s.ListRules(ctx)
+	if len(rules) != 1 || rules[0].TargetPath != profile {
+		t.Fatalf("expected the rule edit to be undone, got %+v", rules)
+	}
+
+	if _, err := s.Redo(ctx); err != nil {
+		t.Fatalf("Redo returned error: %v", err)
+	}
+	if got := gitConfigGet(t, dir, "--file", profile, "user.email"); got != "alice@work.example" {
+		t.Fatalf("expected redo to recreate the profile, got %q", got)
+	}
+	if got := gitConfigGet(t, dir, "--file", global, "--get-all", "includeIf.gitdir:~/work/.path"); got != updated.TargetPath {
+		t.Fatalf("expected redo to write the updated include, got %q", got)
+	}
+	if rules, _ := s.ListRules(ctx); len(rules) != 1 || rules[0].TargetPath != updated.TargetPath {
+		t.Fatalf("expected the rule edit to be redone, got %+v", rules)
+	}
+
+	if _, err := s.Undo(ctx); err != nil {
+		t.Fatalf("Undo returned error: %v", err)
+	}
+	if _, err := s.Undo(ctx); err != nil {
+		t.Fatalf("Undo returned error: %v", err)
+	}
+	if rules, _ := s.ListRules(ctx); len(rules) != 0 {
+		t.Fatalf("expected undoing the upsert to remove the rule, got %+v", rules)
+	}
+	if data, err := os.ReadFile(global); err != nil || string(data) != "[core]\n\teditor = vim\n" {
+		t.Fatalf("expected the global file to be restored, got %q (%v)", data, err)
+	}
+}
+
+func TestRestackStepAfterConcurrentEdit(t *testing.T) {
+	s := NewService()
+	s.pushEditStepLocked(editStep{label: "set user.name"})
+	step := s.undo[0]
+	s.undo = nil
+	claimed := s.editSeq
+
+	// An edit made while the step was being undone empties the redo stack after it.
+	s.pushEditStepLocked(editStep{label: "set user.email"})
+	s.restackStepLocked(step, false, claimed)
+	if len(s.redo) != 0 {
+		t.Fatalf("expected a newer edit to keep the undone step off the redo stack, got %+v", s.redo)
+	}
+
+	// A step that could not be undone goes back below the edits made meanwhile.
+	s.restackStepLocked(step, true, claimed)
+	if len(s.undo) != 2 || s.undo[0] != step || s.undo[1].label != "set user.email" {
+		t.Fatalf("expected the step below the newer edit, got %+v", s.GetEditHistory().Undo)
+	}
+
+	claimed = s.editSeq
+	s.restackStepLocked(step, false, claimed)
+	if len(s.redo) != 1 {
+		t.Fatalf("expected the step on the redo stack, got %+v", s.redo)
+	}
+}
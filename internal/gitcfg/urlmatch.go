@@ -0,0 +1,297 @@
+package gitcfg
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// URLMatchSetting is the value a URL-scoped variable such as http.<url>.proxy takes for a
+// particular URL.
+type URLMatchSetting struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+	// Pattern is the URL subsection that matched, or empty for the unscoped variable.
+	Pattern string       `json:"pattern,omitempty"`
+	Source  ConfigSource `json:"source"`
+}
+
+// RemoteHTTPSettings lists the effective http.* settings of one remote.
+type RemoteHTTPSettings struct {
+	Remote   string            `json:"remote"`
+	URL      string            `json:"url"`
+	Settings []URLMatchSetting `json:"settings"`
+	// Note explains why no settings apply, e.g. for SSH remotes.
+	Note string `json:"note,omitempty"`
+}
+
+// normalizedURL is the subset of git's url_info needed for urlmatch comparisons.
+type normalizedURL struct {
+	scheme string
+	user   string
+	host   string
+	port   string
+	path   string
+}
+
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+	"ftp":   "21",
+	"ftps":  "990",
+}
+
+// normalizeMatchURL parses raw the way git's url_normalize does for matching: scheme and host
+// are lower-cased, default ports dropped, and an empty path becomes "/".
+func normalizeMatchURL(raw string) (normalizedURL, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return normalizedURL{}, fmt.Errorf("invalid url %q: %w", raw, err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return normalizedURL{}, fmt.Errorf("url %q must contain a scheme and a host", raw)
+	}
+
+	n := normalizedURL{
+		scheme: strings.ToLower(u.Scheme),
+		host:   strings.ToLower(u.Hostname()),
+		port:   u.Port(),
+		path:   u.EscapedPath(),
+	}
+	if u.User != nil {
+		n.user = u.User.Username()
+	}
+	if n.port == "" || n.port == defaultPorts[n.scheme] {
+		n.port = ""
+	}
+	if n.path == "" {
+		n.path = "/"
+	}
+	return n, nil
+}
+
+// urlMatchScore ranks how well a URL pattern matches; higher scores win, compared by host
+// length, then whether the host is free of wildcards, then path length, then whether a user
+// name was required.
+type urlMatchScore struct {
+	host  int
+	exact bool
+	path  int
+	user  bool
+}
+
+func (a urlMatchScore) less(b urlMatchScore) bool {
+	if a.host != b.host {
+		return a.host < b.host
+	}
+	if a.exact != b.exact {
+		return !a.exact
+	}
+	if a.path != b.path {
+		return a.path < b.path
+	}
+	return !a.user && b.user
+}
+
+func matchURL(pattern, target normalizedURL) (urlMatchScore, bool) {
+	if pattern.scheme != target.scheme || pattern.port != target.port {
+		return urlMatchScore{}, false
+	}
+	if pattern.user != "" && pattern.user != target.user {
+		return urlMatchScore{}, false
+	}
+	if !matchHost(pattern.host, target.host) {
+		return urlMatchScore{}, false
+	}
+
+	pathLen, ok := matchPathPrefix(pattern.path, target.path)
+	if !ok {
+		return urlMatchScore{}, false
+	}
+	return urlMatchScore{
+		host:  len(pattern.host),
+		exact: !strings.Contains(pattern.host, "*"),
+		path:  pathLen,
+		user:  pattern.user != "",
+	}, true
+}
+
+// matchHost compares dot-separated host components as globs; "*" never crosses a dot, so
+// "*.example.com" matches "git.example.com" but neither "example.com" nor "a.b.example.com",
+// and "git*.example.com" matches "github.example.com" but not "gist.example.com".
+func matchHost(pattern, host string) bool {
+	patternParts := strings.Split(pattern, ".")
+	hostParts := strings.Split(host, ".")
+	if len(patternParts) != len(hostParts) {
+		return false
+	}
+	for i, part := range patternParts {
+		if !wildmatch(part, hostParts[i], false) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchPathPrefix reports whether pattern is a prefix of path ending on a "/" boundary and
+// returns the length of the matched prefix.
+func matchPathPrefix(pattern, path string) (int, bool) {
+	pattern = strings.TrimSuffix(pattern, "/")
+	if pattern == "" {
+		return 1, true
+	}
+	if path == pattern || strings.HasPrefix(path, pattern+"/") {
+		return len(pattern), true
+	}
+	return 0, false
+}
+
+type urlMatchCandidate struct {
+	setting URLMatchSetting
+	score   urlMatchScore
+}
+
+// resolveURLSettings applies git's urlmatch rules to every <section>.<url>.<name> and
+// <section>.<name> entry of matrix for target. Like urlmatch.c, entries are read in config
+// order and one that matches worse than an earlier entry for the same variable is ignored.
+// A single-valued variable takes the last value that remains; a multi-valued one such as
+// http.extraHeader collects them all, and an empty value clears what was collected so far.
+func resolveURLSettings(matrix ConfigMatrix, section, target string) ([]URLMatchSetting, error) {
+	targetURL, err := normalizeMatchURL(target)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := make(map[string][]urlMatchCandidate)
+	for key, value := range matrix.Entries {
+		parsed, err := ParseKey(key)
+		if err != nil || parsed.Section != section {
+			continue
+		}
+
+		score := urlMatchScore{}
+		if parsed.Subsection != "" {
+			patternURL, err := normalizeMatchURL(parsed.Subsection)
+			if err != nil {
+				continue
+			}
+			var ok bool
+			if score, ok = matchURL(patternURL, targetURL); !ok {
+				continue
+			}
+		}
+
+		generic := ConfigKey{Section: parsed.Section, Name: parsed.Name}.String()
+		for _, entry := range valuesInOrder(value) {
+			matches[generic] = append(matches[generic], urlMatchCandidate{
+				setting: URLMatchSetting{
+					Key:     generic,
+					Value:   entry.Value,
+					Pattern: parsed.Subsection,
+					Source:  entry.Source,
+				},
+				score: score,
+			})
+		}
+	}
+
+	settings := make([]URLMatchSetting, 0, len(matches))
+	for name, candidates := range matches {
+		// Entries are visited in map order; git reads them in config order.
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return candidates[i].setting.Source.Order < candidates[j].setting.Source.Order
+		})
+		info, _ := LookupKeyInfo(name)
+		var best urlMatchScore
+		var applied []URLMatchSetting
+		for _, candidate := range candidates {
+			if candidate.score.less(best) {
+				continue
+			}
+			best = candidate.score
+			switch {
+			case !info.MultiValued:
+				applied = []URLMatchSetting{candidate.setting}
+			case candidate.setting.Value == "":
+				applied = nil
+			default:
+				applied = append(applied, candidate.setting)
+			}
+		}
+		settings = append(settings, applied...)
+	}
+
+	sort.SliceStable(settings, func(i, j int) bool {
+		return settings[i].Key < settings[j].Key
+	})
+	return settings, nil
+}
+
+// configuredRemotes returns the URLs of every remote defined in matrix, keyed by remote name.
+func configuredRemotes(matrix ConfigMatrix) map[string][]string {
+	remotes := make(map[string][]string)
+	for key, value := range matrix.Entries {
+		parsed, err := ParseKey(key)
+		if err != nil || parsed.Section != "remote" || parsed.Subsection == "" || parsed.Name != "url" {
+			continue
+		}
+		for _, entry := range valuesInOrder(value) {
+			remotes[parsed.Subsection] = append(remotes[parsed.Subsection], entry.Value)
+		}
+	}
+	return remotes
+}
+
+// GetRemoteHTTPSettings returns the http.* settings git applies to each remote of a repository,
+// following the same rules as `git config --get-urlmatch`. Remote URLs are rewritten with
+// insteadOf first, as git does before connecting.
+func (s *Service) GetRemoteHTTPSettings(ctx context.Context, repositoryID string) ([]RemoteHTTPSettings, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	matrix, err := s.loadMatrix(ctx, repositoryID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	rewrites := collectURLRewrites(matrix)
+	remotes := configuredRemotes(matrix)
+	results := make([]RemoteHTTPSettings, 0, len(remotes))
+	for name, urls := range remotes {
+		remoteURL, _ := rewriteURL(urls[0], rewrites, false)
+		result := RemoteHTTPSettings{Remote: name, URL: remoteURL, Settings: []URLMatchSetting{}}
+
+		if !isHTTPURL(remoteURL) {
+			result.Note = "http.* settings only apply to HTTP(S) remotes"
+		} else {
+			settings, err := resolveURLSettings(matrix, "http", remoteURL)
+			if err != nil {
+				result.Note = err.Error()
+			} else {
+				result.Settings = settings
+			}
+		}
+
+		result.URL, _ = redactValue(result.URL)
+		for i := range result.Settings {
+			result.Settings[i].Value, _ = redactValue(result.Settings[i].Value)
+			result.Settings[i].Pattern, _ = redactValue(result.Settings[i].Pattern)
+		}
+		results = append(results, result)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Remote < results[j].Remote
+	})
+	return results, nil
+}
+
+func isHTTPURL(raw string) bool {
+	lower := strings.ToLower(raw)
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://")
+}
@@ -0,0 +1,150 @@
+package gitcfg
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMatchURL(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		pattern string
+		target  string
+		want    bool
+	}{
+		{pattern: "https://example.com", target: "https://example.com/org/repo.git", want: true},
+		{pattern: "https://example.com:443/", target: "https://Example.com/repo", want: true},
+		{pattern: "https://example.com", target: "http://example.com/repo", want: false},
+		{pattern: "https://example.com:8443", target: "https://example.com/repo", want: false},
+		{pattern: "https://*.example.com", target: "https://git.example.com/repo", want: true},
+		{pattern: "https://*.example.com", target: "https://example.com/repo", want: false},
+		{pattern: "https://*.example.com", target: "https://a.b.example.com/repo", want: false},
+		{pattern: "https://git*.example.com", target: "https://github.example.com/repo", want: true},
+		{pattern: "https://git*.example.com", target: "https://git.example.com/repo", want: true},
+		{pattern: "https://git*.example.com", target: "https://gist.example.com/repo", want: false},
+		{pattern: "https://git*.example.com", target: "https://github.corp.example.com/repo", want: false},
+		{pattern: "https://example.com/org", target: "https://example.com/org/repo", want: true},
+		{pattern: "https://example.com/org", target: "https://example.com/organisation/repo", want: false},
+		{pattern: "https://alice@example.com", target: "https://alice@example.com/repo", want: true},
+		{pattern: "https://alice@example.com", target: "https://example.com/repo", want: false},
+		{pattern: "https://example.com", target: "https://bob@example.com/repo", want: true},
+	}
+
+	for _, tt := range tests {
+		pattern, err := normalizeMatchURL(tt.pattern)
+		if err != nil {
+			t.Fatalf("normalizeMatchURL(%q) returned error: %v", tt.pattern, err)
+		}
+		target, err := normalizeMatchURL(tt.target)
+		if err != nil {
+			t.Fatalf("normalizeMatchURL(%q) returned error: %v", tt.target, err)
+		}
+		if _, got := matchURL(pattern, target); got != tt.want {
+			t.Fatalf("matchURL(%q, %q) = %v, want %v", tt.pattern, tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestResolveURLSettingsPrecedence(t *testing.T) {
+	matrix := ConfigMatrix{Entries: map[string]ConfigValue{
+		"http.proxy": {
+			Key: "http.proxy", Value: "http://default-proxy:3128",
+			Source: ConfigSource{Scope: ConfigScopeGlobal},
+		},
+		"http.https://corp.example.com/.proxy": {
+			Key: "http.https://corp.example.com/.proxy", Value: "http://corp-proxy:8080",
+			Source: ConfigSource{Scope: ConfigScopeGlobal},
+		},
+		"http.https://corp.example.com/team/.proxy": {
+			Key: "http.https://corp.example.com/team/.proxy", Value: "http://team-proxy:8080",
+			Source: ConfigSource{Scope: ConfigScopeGlobal},
+		},
+		"http.https://*.example.com/.sslverify": {
+			Key: "http.https://*.example.com/.sslverify", Value: "false",
+			Source: ConfigSource{Scope: ConfigScopeGlobal},
+		},
+		"http.https://other.example.org/.sslcainfo": {
+			Key: "http.https://other.example.org/.sslcainfo", Value: "/etc/ca.pem",
+			Source: ConfigSource{Scope: ConfigScopeGlobal},
+		},
+	}}
+
+	settings, err := resolveURLSettings(matrix, "http", "https://corp.example.com/team/api.git")
+	if err != nil {
+		t.Fatalf("resolveURLSettings returned error: %v", err)
+	}
+
+	got := make(map[string]URLMatchSetting)
+	for _, setting := range settings {
+		got[setting.Key] = setting
+	}
+
+	if len(got) != 2 {
+		t.Fatalf("expected proxy and sslverify settings, got %+v", settings)
+	}
+	if proxy := got["http.proxy"]; proxy.Value != "http://team-proxy:8080" {
+		t.Fatalf("expected the longest path match to win, got %+v", proxy)
+	}
+	if verify := got["http.sslverify"]; verify.Value != "false" || verify.Pattern != "https://*.example.com/" {
+		t.Fatalf("expected wildcard host match, got %+v", verify)
+	}
+}
+
+func TestResolveURLSettingsTieGoesToLastEntry(t *testing.T) {
+	matrix := ConfigMatrix{Entries: buildConfigValues([]gitConfigEntry{
+		{key: "http.https://example.com/.proxy", value: "http://first", scope: ConfigScopeGlobal, order: 0},
+		{key: "http.https://example.com.proxy", value: "http://second", scope: ConfigScopeGlobal, order: 1},
+	})}
+
+	// Both patterns normalize to the same URL; map iteration must not decide the winner.
+	for i := 0; i < 20; i++ {
+		settings, err := resolveURLSettings(matrix, "http", "https://example.com/repo.git")
+		if err != nil {
+			t.Fatalf("resolveURLSettings returned error: %v", err)
+		}
+		if len(settings) != 1 || settings[0].Value != "http://second" {
+			t.Fatalf("expected the later entry to win, got %+v", settings)
+		}
+	}
+}
+
+func TestResolveURLSettingsExactHostBeatsWildcard(t *testing.T) {
+	// Both hosts are as long; the exact one wins regardless of order.
+	matrix := ConfigMatrix{Entries: buildConfigValues([]gitConfigEntry{
+		{key: "http.https://a.example.com.proxy", value: "http://exact", scope: ConfigScopeGlobal, order: 0},
+		{key: "http.https://*.example.com.proxy", value: "http://wildcard", scope: ConfigScopeGlobal, order: 1},
+	})}
+
+	settings, err := resolveURLSettings(matrix, "http", "https://a.example.com/repo.git")
+	if err != nil {
+		t.Fatalf("resolveURLSettings returned error: %v", err)
+	}
+	if len(settings) != 1 || settings[0].Value != "http://exact" {
+		t.Fatalf("expected the exact host to win, got %+v", settings)
+	}
+}
+
+func TestResolveURLSettingsMultiValued(t *testing.T) {
+	matrix := ConfigMatrix{Entries: buildConfigValues([]gitConfigEntry{
+		{key: "http.extraHeader", value: "X-Dropped: 1", scope: ConfigScopeSystem, order: 0},
+		{key: "http.extraHeader", value: "", scope: ConfigScopeGlobal, order: 1},
+		{key: "http.extraHeader", value: "X-Global: 1", scope: ConfigScopeGlobal, order: 2},
+		{key: "http.https://example.com.extraHeader", value: "X-Host: 1", scope: ConfigScopeGlobal, order: 3},
+		// Matches worse than the entry before it, so git ignores it.
+		{key: "http.extraHeader", value: "X-Ignored: 1", scope: ConfigScopeLocal, order: 4},
+		{key: "http.https://example.com/org.extraHeader", value: "X-Org: 1", scope: ConfigScopeLocal, order: 5},
+	})}
+
+	settings, err := resolveURLSettings(matrix, "http", "https://example.com/org/repo.git")
+	if err != nil {
+		t.Fatalf("resolveURLSettings returned error: %v", err)
+	}
+	var got []string
+	for _, setting := range settings {
+		got = append(got, setting.Value)
+	}
+	if strings.Join(got, ", ") != "X-Global: 1, X-Host: 1, X-Org: 1" {
+		t.Fatalf("expected the headers in config order after the reset, got %+v", settings)
+	}
+}
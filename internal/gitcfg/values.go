@@ -0,0 +1,309 @@
+package gitcfg
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ValueType mirrors the types understood by `git config --type`.
+type ValueType string
+
+const (
+	ValueTypeString     ValueType = "string"
+	ValueTypeBool       ValueType = "bool"
+	ValueTypeInt        ValueType = "int"
+	ValueTypePath       ValueType = "path"
+	ValueTypeColor      ValueType = "color"
+	ValueTypeExpiryDate ValueType = "expiry-date"
+)
+
+// ValueTypeForKey reports the value type of key according to the catalogue, defaulting to
+// ValueTypeString for unknown keys.
+func ValueTypeForKey(key string) ValueType {
+	if info, ok := LookupKeyInfo(key); ok && info.Type != "" {
+		return info.Type
+	}
+	return ValueTypeString
+}
+
+// NormalizeValue interprets raw according to t and returns its canonical spelling, matching
+// what `git config --type=<t>` would print.
+func NormalizeValue(t ValueType, raw string) (string, error) {
+	return normalizeValue(t, raw, false, time.Now())
+}
+
+// ValuesEqual reports whether a and b denote the same value when interpreted as t.
+func ValuesEqual(t ValueType, a, b string) bool {
+	now := time.Now()
+	na, errA := normalizeValue(t, a, false, now)
+	nb, errB := normalizeValue(t, b, false, now)
+	if errA != nil || errB != nil {
+		return a == b
+	}
+	return na == nb
+}
+
+// normalizeValue is NormalizeValue with the implicit-true flag for `[section] key` lines
+// that carry no "=", and a fixed reference time for relative expiry dates.
+func normalizeValue(t ValueType, raw string, noValue bool, now time.Time) (string, error) {
+	switch t {
+	case ValueTypeBool:
+		if noValue {
+			return "true", nil
+		}
+		b, err := parseBool(raw)
+		if err != nil {
+			return "", err
+		}
+		return strconv.FormatBool(b), nil
+	case ValueTypeInt:
+		n, err := parseInt(raw)
+		if err != nil {
+			return "", err
+		}
+		return strconv.FormatInt(n, 10), nil
+	case ValueTypePath:
+		return expandPath(raw)
+	case ValueTypeColor:
+		return parseColor(raw)
+	case ValueTypeExpiryDate:
+		ts, err := parseExpiryDate(raw, now)
+		if err != nil {
+			return "", err
+		}
+		return strconv.FormatUint(ts, 10), nil
+	default:
+		return raw, nil
+	}
+}
+
+func parseBool(raw string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "true", "yes", "on":
+		return true, nil
+	case "false", "no", "off", "":
+		return false, nil
+	}
+	n, err := parseInt(raw)
+	if err != nil {
+		return false, fmt.Errorf("bad boolean value %q", raw)
+	}
+	return n != 0, nil
+}
+
+func parseInt(raw string) (int64, error) {
+	s := strings.TrimSpace(raw)
+	if s == "" {
+		return 0, fmt.Errorf("bad numeric value %q", raw)
+	}
+
+	factor := int64(1)
+	switch s[len(s)-1] {
+	case 'k', 'K':
+		factor = 1 << 10
+	case 'm', 'M':
+		factor = 1 << 20
+	case 'g', 'G':
+		factor = 1 << 30
+	}
+	if factor != 1 {
+		s = s[:len(s)-1]
+	}
+
+	// Like strtoimax with base 0: an optional sign, then hexadecimal after 0x, octal after a
+	// leading 0 and decimal otherwise. Go's own prefixes and digit separators are not accepted.
+	digits, sign := s, ""
+	if digits != "" && (digits[0] == '+' || digits[0] == '-') {
+		sign, digits = digits[:1], digits[1:]
+	}
+	base := 10
+	switch {
+	case len(digits) > 2 && (digits[:2] == "0x" || digits[:2] == "0X"):
+		base, digits = 16, digits[2:]
+	case len(digits) > 1 && digits[0] == '0':
+		base, digits = 8, digits[1:]
+	}
+	if digits == "" || strings.ContainsAny(digits, "+-") {
+		return 0, fmt.Errorf("bad numeric value %q", raw)
+	}
+	n, err := strconv.ParseInt(sign+digits, base, 64)
+	if err != nil {
+		return 0, fmt.Errorf("bad numeric value %q", raw)
+	}
+	if n > 0 && n > (1<<63-1)/factor || n < 0 && n < (-1<<63)/factor {
+		return 0, fmt.Errorf("numeric value %q out of range", raw)
+	}
+	return n * factor, nil
+}
+
+var (
+	gitPrefixOnce sync.Once
+	gitPrefix     string
+)
+
+// runtimePrefix approximates git's RUNTIME_PREFIX as the parent of the directory holding the
+// git binary, e.g. /usr for /usr/bin/git.
+func runtimePrefix() string {
+	gitPrefixOnce.Do(func() {
+		bin, err := exec.LookPath("git")
+		if err != nil {
+			return
+		}
+		if resolved, err := filepath.EvalSymlinks(bin); err == nil {
+			bin = resolved
+		}
+		gitPrefix = filepath.Dir(filepath.Dir(bin))
+	})
+	return gitPrefix
+}
+
+func expandPath(raw string) (string, error) {
+	switch {
+	case raw == "~" || strings.HasPrefix(raw, "~/"):
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("expand %q: %w", raw, err)
+		}
+		return home + raw[1:], nil
+	case strings.HasPrefix(raw, "~"):
+		name, rest, _ := strings.Cut(raw[1:], "/")
+		u, err := user.Lookup(name)
+		if err != nil {
+			return "", fmt.Errorf("expand %q: %w", raw, err)
+		}
+		if rest == "" {
+			return u.HomeDir, nil
+		}
+		return u.HomeDir + "/" + rest, nil
+	case strings.HasPrefix(raw, "%(prefix)/"):
+		prefix := runtimePrefix()
+		if prefix == "" {
+			return "", fmt.Errorf("expand %q: git runtime prefix is unknown", raw)
+		}
+		return prefix + raw[len("%(prefix)"):], nil
+	default:
+		return raw, nil
+	}
+}
+
+var colorNames = map[string]bool{
+	"normal": true, "default": true, "black": true, "red": true, "green": true, "yellow": true,
+	"blue": true, "magenta": true, "cyan": true, "white": true,
+}
+
+var colorAttributes = map[string]bool{
+	"bold": true, "dim": true, "ul": true, "blink": true, "reverse": true, "italic": true, "strike": true,
+}
+
+var hexColorPattern = regexp.MustCompile(`^#[0-9a-f]{6}$`)
+
+// parseColor validates a color specification and renders it as "<fg> <bg> <attributes...>",
+// so that equivalent spellings such as "bold red" and "red bold" compare equal.
+func parseColor(raw string) (string, error) {
+	var colors, attrs []string
+	for _, word := range strings.Fields(strings.ToLower(raw)) {
+		switch {
+		case isColorWord(word):
+			if len(colors) == 2 {
+				return "", fmt.Errorf("bad color value %q: too many colors", raw)
+			}
+			colors = append(colors, word)
+		case isColorAttribute(word):
+			attrs = append(attrs, strings.Replace(word, "no-", "no", 1))
+		default:
+			return "", fmt.Errorf("bad color value %q: unknown word %q", raw, word)
+		}
+	}
+
+	sort.Strings(attrs)
+	return strings.Join(append(colors, attrs...), " "), nil
+}
+
+func isColorWord(word string) bool {
+	if colorNames[word] || colorNames[strings.TrimPrefix(word, "bright")] {
+		return true
+	}
+	if hexColorPattern.MatchString(word) {
+		return true
+	}
+	n, err := strconv.Atoi(word)
+	return err == nil && n >= -1 && n <= 255
+}
+
+func isColorAttribute(word string) bool {
+	if word == "reset" {
+		return true
+	}
+	word = strings.TrimPrefix(word, "no")
+	word = strings.TrimPrefix(word, "-")
+	return colorAttributes[word]
+}
+
+var relativeDatePattern = regexp.MustCompile(`^(\d+)[. ]+(second|minute|hour|day|week|month|year)s?[. ]+ago$`)
+
+var relativeDateUnits = map[string]time.Duration{
+	"second": time.Second,
+	"minute": time.Minute,
+	"hour":   time.Hour,
+	"day":    24 * time.Hour,
+	"week":   7 * 24 * time.Hour,
+}
+
+// expiryDateMax is what git reports for "now" and "all": everything has expired.
+const expiryDateMax = math.MaxUint64
+
+// parseExpiryDate supports the commonly used subset of git's approxidate syntax and returns
+// a unix timestamp relative to now.
+func parseExpiryDate(raw string, now time.Time) (uint64, error) {
+	s := strings.ToLower(strings.TrimSpace(raw))
+	switch s {
+	case "never", "false":
+		return 0, nil
+	case "now", "all":
+		return expiryDateMax, nil
+	}
+	ts, err := parseApproxidate(raw, s, now)
+	if err != nil {
+		return 0, err
+	}
+	if ts < 0 {
+		return 0, fmt.Errorf("expiry date %q is before 1970", raw)
+	}
+	return uint64(ts), nil
+}
+
+func parseApproxidate(raw, s string, now time.Time) (int64, error) {
+
+	if m := relativeDatePattern.FindStringSubmatch(s); m != nil {
+		n, err := strconv.Atoi(m[1])
+		if err != nil {
+			return 0, fmt.Errorf("bad expiry date %q", raw)
+		}
+		switch m[2] {
+		case "month":
+			return now.AddDate(0, -n, 0).Unix(), nil
+		case "year":
+			return now.AddDate(-n, 0, 0).Unix(), nil
+		default:
+			return now.Add(-time.Duration(n) * relativeDateUnits[m[2]]).Unix(), nil
+		}
+	}
+
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, strings.TrimSpace(raw), time.Local); err == nil {
+			return t.Unix(), nil
+		}
+	}
+
+	return 0, fmt.Errorf("bad expiry date %q", raw)
+}
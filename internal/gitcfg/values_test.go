@@ -0,0 +1,132 @@
+package gitcfg
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestNormalizeValue(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		typ     ValueType
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{name: "bool yes", typ: ValueTypeBool, input: "yes", want: "true"},
+		{name: "bool On", typ: ValueTypeBool, input: "On", want: "true"},
+		{name: "bool numeric", typ: ValueTypeBool, input: "1", want: "true"},
+		{name: "bool empty", typ: ValueTypeBool, input: "", want: "false"},
+		{name: "bool invalid", typ: ValueTypeBool, input: "maybe", wantErr: true},
+		{name: "int plain", typ: ValueTypeInt, input: "42", want: "42"},
+		{name: "int kilo", typ: ValueTypeInt, input: "1k", want: "1024"},
+		{name: "int mega", typ: ValueTypeInt, input: "2M", want: "2097152"},
+		{name: "int giga", typ: ValueTypeInt, input: "1g", want: "1073741824"},
+		{name: "int invalid", typ: ValueTypeInt, input: "12x", wantErr: true},
+		{name: "int hex", typ: ValueTypeInt, input: "0x10", want: "16"},
+		{name: "int octal", typ: ValueTypeInt, input: "010", want: "8"},
+		{name: "int negative hex", typ: ValueTypeInt, input: "-0x10", want: "-16"},
+		{name: "int signed", typ: ValueTypeInt, input: "+5k", want: "5120"},
+		{name: "int separators", typ: ValueTypeInt, input: "1_000", wantErr: true},
+		{name: "int binary", typ: ValueTypeInt, input: "0b101", wantErr: true},
+		{name: "int go octal", typ: ValueTypeInt, input: "0o7", wantErr: true},
+		{name: "int double sign", typ: ValueTypeInt, input: "+-5", wantErr: true},
+		{name: "int unit only", typ: ValueTypeInt, input: "k", wantErr: true},
+		{name: "color reorder", typ: ValueTypeColor, input: "bold Red", want: "red bold"},
+		{name: "color fg bg", typ: ValueTypeColor, input: "red blue ul", want: "red blue ul"},
+		{name: "color invalid", typ: ValueTypeColor, input: "purple", wantErr: true},
+		{name: "expiry never", typ: ValueTypeExpiryDate, input: "never", want: "0"},
+		{name: "expiry now", typ: ValueTypeExpiryDate, input: "now", want: "18446744073709551615"},
+		{name: "expiry all", typ: ValueTypeExpiryDate, input: "all", want: "18446744073709551615"},
+		{name: "string passthrough", typ: ValueTypeString, input: "Yes", want: "Yes"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeValue(tt.typ, tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("NormalizeValue(%q, %q) expected error, got %q", tt.typ, tt.input, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NormalizeValue(%q, %q) returned error: %v", tt.typ, tt.input, err)
+			}
+			if got != tt.want {
+				t.Fatalf("NormalizeValue(%q, %q) = %q, want %q", tt.typ, tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandPathHome(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skipf("no home directory: %v", err)
+	}
+
+	got, err := expandPath("~/.gitignore")
+	if err != nil {
+		t.Fatalf("expandPath returned error: %v", err)
+	}
+	if want := home + "/.gitignore"; got != want {
+		t.Fatalf("expandPath = %q, want %q", got, want)
+	}
+}
+
+func TestParseExpiryDateRelative(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	got, err := parseExpiryDate("2.weeks.ago", now)
+	if err != nil {
+		t.Fatalf("parseExpiryDate returned error: %v", err)
+	}
+	if want := uint64(now.Add(-14 * 24 * time.Hour).Unix()); got != want {
+		t.Fatalf("parseExpiryDate = %d, want %d", got, want)
+	}
+}
+
+func TestValuesEqual(t *testing.T) {
+	if !ValuesEqual(ValueTypeBool, "true", "yes") {
+		t.Fatalf("expected true and yes to be equal booleans")
+	}
+	if ValuesEqual(ValueTypeString, "true", "yes") {
+		t.Fatalf("expected strings to compare verbatim")
+	}
+}
+
+func TestBuildConfigValuesNormalizesImplicitBool(t *testing.T) {
+	raw := bytes.Join([][]byte{
+		[]byte("global"),
+		[]byte("file:/home/u/.gitconfig"),
+		[]byte("core.filemode\nno"),
+		[]byte("local"),
+		[]byte("file:.git/config"),
+		[]byte("core.filemode"),
+	}, []byte{0})
+
+	entries, err := parseGitConfigOutput(raw)
+	if err != nil {
+		t.Fatalf("parseGitConfigOutput returned error: %v", err)
+	}
+
+	value, ok := buildConfigValues(entries)["core.filemode"]
+	if !ok {
+		t.Fatalf("expected core.filemode to be present")
+	}
+	if value.Type != ValueTypeBool {
+		t.Fatalf("expected bool type, got %q", value.Type)
+	}
+	if value.Value != "" || value.Normalized != "true" {
+		t.Fatalf("expected raw %q normalized %q, got raw %q normalized %q", "", "true", value.Value, value.Normalized)
+	}
+	if got := value.Overrides[0].Normalized; got != "false" {
+		t.Fatalf("expected override normalized false, got %q", got)
+	}
+}
@@ -0,0 +1,142 @@
+package gitcfg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// WhatIfRequest describes a repository that may not exist yet.
+type WhatIfRequest struct {
+	// Path is the working directory of the repository, e.g. where it would be cloned to.
+	Path string `json:"path"`
+	// Branch is the branch assumed to be checked out, for onbranch conditions.
+	Branch string `json:"branch,omitempty"`
+	// RemoteURL is the assumed remote, for hasconfig:remote.*.url conditions.
+	RemoteURL string `json:"remoteUrl,omitempty"`
+}
+
+// WhatIfResult is the configuration a repository at the requested location would receive from
+// the system and per-user config files.
+type WhatIfResult struct {
+	Path   string       `json:"path"`
+	GitDir string       `json:"gitDir"`
+	Matrix ConfigMatrix `json:"matrix"`
+	// Graph shows which include and includeIf directives apply to the location.
+	Graph IncludeGraph `json:"graph"`
+}
+
+// configEvaluator reads config files the way git does, expanding includes in place.
+// contents replaces the data of the files it names, keyed by clean absolute path.
+type configEvaluator struct {
+	ictx     includeContext
+	contents map[string][]byte
+	entries  []gitConfigEntry
+}
+
+func (e *configEvaluator) readFile(file string, scope ConfigScope, depth int) error {
+	data, ok := e.contents[filepath.Clean(file)]
+	if !ok {
+		var err error
+		if data, err = os.ReadFile(file); err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return nil
+			}
+			return fmt.Errorf("read %s: %w", file, err)
+		}
+	}
+	parsed, err := parseConfigFile(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", file, err)
+	}
+
+	for _, entry := range parsed {
+		e.entries = append(e.entries, gitConfigEntry{
+			key:     entry.key.String(),
+			value:   entry.value,
+			noValue: entry.noValue,
+			scope:   scope,
+			file:    file,
+			line:    entry.line,
+			order:   len(e.entries),
+		})
+
+		_, condition, ok := includeDirective(entry.key)
+		if !ok || entry.noValue {
+			continue
+		}
+		if condition != "" {
+			if matched, _ := evalIncludeCondition(condition, file, e.ictx); !matched {
+				continue
+			}
+		}
+		target, err := resolveIncludePath(entry.value, file)
+		if err != nil {
+			return fmt.Errorf("%s:%d: %w", file, entry.line, err)
+		}
+		if depth >= maxIncludeDepth {
+			return fmt.Errorf("exceeded maximum include depth (%d) while including %s from %s", maxIncludeDepth, target, file)
+		}
+		if err := e.readFile(target, scope, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// evaluateConfig returns the values git would read from roots for a repository described by
+// ictx, with includes resolved. contents may substitute the data of some files.
+func evaluateConfig(roots []includeRoot, ictx includeContext, contents map[string][]byte) (map[string]ConfigValue, error) {
+	e := &configEvaluator{ictx: ictx, contents: contents}
+	for _, root := range roots {
+		if err := e.readFile(root.file, root.scope, 0); err != nil {
+			return nil, err
+		}
+	}
+	return buildConfigValues(e.entries), nil
+}
+
+// EvaluatePath computes the effective system and global configuration of a repository at an
+// arbitrary location, which need not exist, for an assumed branch and remote URL.
+func (s *Service) EvaluatePath(ctx context.Context, req WhatIfRequest) (WhatIfResult, error) {
+	select {
+	case <-ctx.Done():
+		return WhatIfResult{}, ctx.Err()
+	default:
+	}
+
+	if strings.TrimSpace(req.Path) == "" {
+		return WhatIfResult{}, errors.New("path cannot be empty")
+	}
+	path, err := expandPath(req.Path)
+	if err != nil {
+		return WhatIfResult{}, err
+	}
+	if path, err = filepath.Abs(path); err != nil {
+		return WhatIfResult{}, fmt.Errorf("resolve %q: %w", req.Path, err)
+	}
+
+	ictx := includeContext{gitDir: filepath.Join(path, ".git"), branch: req.Branch}
+	if req.RemoteURL != "" {
+		ictx.remoteURLs = []string{req.RemoteURL}
+	}
+
+	roots := globalConfigRoots()
+	values, err := evaluateConfig(roots, ictx, nil)
+	if err != nil {
+		return WhatIfResult{}, err
+	}
+	graph, chains := walkIncludes(roots, ictx)
+	annotateIncludeChains(values, chains, "")
+
+	return WhatIfResult{
+		Path:   path,
+		GitDir: ictx.gitDir,
+		Matrix: redactMatrix(ConfigMatrix{Entries: values, RetrievedAt: timestamp(time.Now())}),
+		Graph:  graph,
+	}, nil
+}
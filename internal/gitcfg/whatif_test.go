@@ -0,0 +1,88 @@
+package gitcfg
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEvaluatePathMatchesGit(t *testing.T) {
+	s := newTestService(t)
+	ctx := context.Background()
+	home := t.TempDir()
+	global := filepath.Join(home, "gitconfig")
+	t.Setenv("GIT_CONFIG_GLOBAL", global)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	repo := newTestRepository(t, s)
+	runGit(t, repo.Path, "checkout", "-q", "-b", "feature/login")
+	runGit(t, repo.Path, "remote", "add", "origin", "git@github.com:acme/api.git")
+
+	writeTestFile(t, filepath.Join(home, "work"), "[user]\n\temail = alice@acme.example\n[include]\n\tpath = signing\n")
+	writeTestFile(t, filepath.Join(home, "signing"), "[commit]\n\tgpgsign = true\n")
+	writeTestFile(t, filepath.Join(home, "feature"), "[pull]\n\trebase = true\n")
+	writeTestFile(t, filepath.Join(home, "acme"), "[core]\n\tsshCommand = ssh -i ~/.ssh/acme\n")
+	writeTestFile(t, filepath.Join(home, "personal"), "[user]\n\temail = alice@home.example\n")
+	writeTestFile(t, global, "[user]\n\tname = Alice\n\temail = alice@example.com\n"+
+		"[includeIf \"gitdir:"+filepath.Dir(repo.Path)+"/\"]\n\tpath = work\n"+
+		"[includeIf \"gitdir:/nowhere/\"]\n\tpath = personal\n"+
+		"[includeIf \"onbranch:feature/\"]\n\tpath = feature\n"+
+		"[includeIf \"hasconfig:remote.*.url:git@github.com:acme/**\"]\n\tpath = acme\n")
+
+	result, err := s.EvaluatePath(ctx, WhatIfRequest{Path: repo.Path, Branch: "feature/login", RemoteURL: "git@github.com:acme/api.git"})
+	if err != nil {
+		t.Fatalf("EvaluatePath returned error: %v", err)
+	}
+
+	actual, err := readGitConfig(ctx, repo.Path)
+	if err != nil {
+		t.Fatalf("readGitConfig returned error: %v", err)
+	}
+	want := make(map[string]string)
+	for key, value := range actual {
+		if value.Source.Scope == ConfigScopeGlobal {
+			want[key] = value.Value
+		}
+	}
+	if len(result.Matrix.Entries) != len(want) {
+		t.Fatalf("expected %d global keys as git reports, got %+v", len(want), result.Matrix.Entries)
+	}
+	for key, value := range want {
+		if got := result.Matrix.Entries[key]; got.Value != value {
+			t.Fatalf("%s: git reports %q, evaluation gives %q", key, value, got.Value)
+		}
+	}
+	if email := result.Matrix.Entries["user.email"]; len(email.Source.IncludeChain) != 1 || email.Value != "alice@acme.example" {
+		t.Fatalf("expected the work identity with its include chain, got %+v", email)
+	}
+}
+
+func TestEvaluatePathForMissingDirectory(t *testing.T) {
+	s := newTestService(t)
+	home := t.TempDir()
+	global := filepath.Join(home, "gitconfig")
+	t.Setenv("GIT_CONFIG_GLOBAL", global)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+
+	real := filepath.Join(home, "real")
+	if err := os.Mkdir(real, 0o700); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	link := filepath.Join(home, "link")
+	if err := os.Symlink(real, link); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+	writeTestFile(t, filepath.Join(home, "clients"), "[user]\n\temail = alice@clients.example\n")
+	writeTestFile(t, global, "[includeIf \"gitdir:"+real+"/clients/\"]\n\tpath = clients\n")
+
+	result, err := s.EvaluatePath(context.Background(), WhatIfRequest{Path: filepath.Join(link, "clients", "acme")})
+	if err != nil {
+		t.Fatalf("EvaluatePath returned error: %v", err)
+	}
+	if email := result.Matrix.Entries["user.email"]; email.Value != "alice@clients.example" {
+		t.Fatalf("expected the include to match through the symlink, got %+v", result.Matrix.Entries)
+	}
+	if _, err := os.Stat(filepath.Join(real, "clients")); !os.IsNotExist(err) {
+		t.Fatal("expected EvaluatePath not to create the directory")
+	}
+}
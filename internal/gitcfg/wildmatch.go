@@ -0,0 +1,190 @@
+package gitcfg
+
+import (
+	"strings"
+	"unicode"
+)
+
+// wildmatch implements git's wildmatch() with WM_PATHNAME, as used by includeIf conditions:
+// '*' and '?' do not match '/', while "**" surrounded by slashes matches any number of
+// directories. fold enables case-insensitive matching (WM_CASEFOLD).
+func wildmatch(pattern, text string, fold bool) bool {
+	return wildmatchFrom(pattern, 0, text, fold)
+}
+
+func wildmatchFrom(pattern string, pi int, text string, fold bool) bool {
+	for pi < len(pattern) {
+		c := pattern[pi]
+		switch c {
+		case '?':
+			if text == "" || text[0] == '/' {
+				return false
+			}
+			pi++
+			text = text[1:]
+		case '*':
+			start := pi
+			for pi < len(pattern) && pattern[pi] == '*' {
+				pi++
+			}
+			if pi-start >= 2 && (start == 0 || pattern[start-1] == '/') && (pi == len(pattern) || pattern[pi] == '/') {
+				if pi == len(pattern) {
+					return true
+				}
+				// "**/" matches zero or more leading directories.
+				rest := pi + 1
+				if wildmatchFrom(pattern, rest, text, fold) {
+					return true
+				}
+				for i := 0; i < len(text); i++ {
+					if text[i] == '/' && wildmatchFrom(pattern, rest, text[i+1:], fold) {
+						return true
+					}
+				}
+				return false
+			}
+			for i := 0; ; i++ {
+				if wildmatchFrom(pattern, pi, text[i:], fold) {
+					return true
+				}
+				if i == len(text) || text[i] == '/' {
+					return false
+				}
+			}
+		case '[':
+			if text == "" || text[0] == '/' {
+				return false
+			}
+			matched, next, ok := matchBracket(pattern, pi, text[0], fold)
+			if !ok {
+				// An unterminated class matches nothing, as in git.
+				return false
+			}
+			if !matched {
+				return false
+			}
+			pi = next
+			text = text[1:]
+		case '\\':
+			if pi+1 < len(pattern) {
+				pi++
+				c = pattern[pi]
+			}
+			fallthrough
+		default:
+			if text == "" || !sameByte(c, text[0], fold) {
+				return false
+			}
+			pi++
+			text = text[1:]
+		}
+	}
+	return text == ""
+}
+
+// matchBracket evaluates the character class starting at pattern[start] == '[' against c and
+// returns the index following the closing ']'.
+func matchBracket(pattern string, start int, c byte, fold bool) (matched bool, next int, ok bool) {
+	i := start + 1
+	negate := false
+	if i < len(pattern) && (pattern[i] == '!' || pattern[i] == '^') {
+		negate = true
+		i++
+	}
+
+	first := true
+	for i < len(pattern) {
+		p := pattern[i]
+		if p == ']' && !first {
+			return matched != negate, i + 1, true
+		}
+		first = false
+
+		if p == '[' && strings.HasPrefix(pattern[i:], "[:") {
+			if end := strings.Index(pattern[i+2:], ":]"); end >= 0 {
+				if matchCharClass(pattern[i+2:i+2+end], c) {
+					matched = true
+				}
+				i += end + 4
+				continue
+			}
+		}
+		if p == '\\' && i+1 < len(pattern) {
+			i++
+			p = pattern[i]
+		}
+		if i+2 < len(pattern) && pattern[i+1] == '-' && pattern[i+2] != ']' {
+			hi := pattern[i+2]
+			if hi == '\\' && i+3 < len(pattern) {
+				hi = pattern[i+3]
+				i++
+			}
+			if inRange(c, p, hi, fold) {
+				matched = true
+			}
+			i += 3
+			continue
+		}
+		if sameByte(p, c, fold) {
+			matched = true
+		}
+		i++
+	}
+	return false, 0, false
+}
+
+func matchCharClass(class string, c byte) bool {
+	r := rune(c)
+	switch class {
+	case "alnum":
+		return unicode.IsLetter(r) || unicode.IsDigit(r)
+	case "alpha":
+		return unicode.IsLetter(r)
+	case "digit":
+		return unicode.IsDigit(r)
+	case "lower":
+		return unicode.IsLower(r)
+	case "upper":
+		return unicode.IsUpper(r)
+	case "space":
+		return unicode.IsSpace(r)
+	case "punct":
+		return unicode.IsPunct(r) || unicode.IsSymbol(r)
+	case "xdigit":
+		return strings.ContainsRune("0123456789abcdefABCDEF", r)
+	default:
+		return false
+	}
+}
+
+func inRange(c, lo, hi byte, fold bool) bool {
+	if lo <= c && c <= hi {
+		return true
+	}
+	if !fold {
+		return false
+	}
+	lower, upper := toLowerByte(c), toUpperByte(c)
+	return (lo <= lower && lower <= hi) || (lo <= upper && upper <= hi)
+}
+
+func sameByte(a, b byte, fold bool) bool {
+	if a == b {
+		return true
+	}
+	return fold && toLowerByte(a) == toLowerByte(b)
+}
+
+func toLowerByte(c byte) byte {
+	if c >= 'A' && c <= 'Z' {
+		return c + 'a' - 'A'
+	}
+	return c
+}
+
+func toUpperByte(c byte) byte {
+	if c >= 'a' && c <= 'z' {
+		return c - ('a' - 'A')
+	}
+	return c
+}
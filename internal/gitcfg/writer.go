@@ -0,0 +1,430 @@
+package gitcfg
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WriteOperation selects how a WriteRequest modifies the target file.
+type WriteOperation string
+
+const (
+	// WriteOperationSet replaces the value of a key; with MatchValue only that value is replaced.
+	WriteOperationSet WriteOperation = "set"
+	// WriteOperationAdd appends another value to a multi-valued key.
+	WriteOperationAdd WriteOperation = "add"
+	// WriteOperationUnset removes a key; with MatchValue only that value is removed.
+	WriteOperationUnset WriteOperation = "unset"
+	// WriteOperationUnsetAll removes every value of a key.
+	WriteOperationUnsetAll WriteOperation = "unset-all"
+	// WriteOperationRollback marks change sets produced by Service.Rollback.
+	WriteOperationRollback WriteOperation = "rollback"
+	// WriteOperationBatch marks change sets produced by Service.ApplyStaged.
+	WriteOperationBatch WriteOperation = "batch"
+	// WriteOperationUndo and WriteOperationRedo mark change sets produced by Service.Undo and
+	// Service.Redo.
+	WriteOperationUndo WriteOperation = "undo"
+	WriteOperationRedo WriteOperation = "redo"
+)
+
+// configEdit describes a pending modification of a single config file as a list of
+// `git config --file` invocations.
+type configEdit struct {
+	path string
+	ops  [][]string
+	// expectedHash, when set, is the contentHash the file must still have when the edit is
+	// applied.
+	expectedHash string
+}
+
+// writeOps translates a validated write request into git config arguments.
+func writeOps(req WriteRequest) ([][]string, error) {
+	switch req.Operation {
+	case "", WriteOperationSet:
+		if req.MatchValue != "" {
+			return [][]string{{"--fixed-value", req.Key, req.Value, req.MatchValue}}, nil
+		}
+		return [][]string{{req.Key, req.Value}}, nil
+	case WriteOperationAdd:
+		return [][]string{{"--add", req.Key, req.Value}}, nil
+	case WriteOperationUnset:
+		if req.MatchValue != "" {
+			return [][]string{{"--fixed-value", "--unset", req.Key, req.MatchValue}}, nil
+		}
+		return [][]string{{"--unset", req.Key}}, nil
+	case WriteOperationUnsetAll:
+		return [][]string{{"--unset-all", req.Key}}, nil
+	default:
+		return nil, fmt.Errorf("unknown write operation %q", req.Operation)
+	}
+}
+
+// previewEdit applies edit to a scratch copy of the target file and returns the file contents
+// before and after. The target file itself is left untouched. A missing file is treated as empty.
+func previewEdit(ctx context.Context, edit configEdit) (before, after []byte, err error) {
+	before, err = os.ReadFile(edit.path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, nil, fmt.Errorf("read %s: %w", edit.path, err)
+	}
+	after, err = editContent(ctx, before, edit.ops)
+	if err != nil {
+		return nil, nil, err
+	}
+	return before, after, nil
+}
+
+// editContent applies ops to a scratch file holding content and returns the result.
+func editContent(ctx context.Context, content []byte, ops [][]string) ([]byte, error) {
+	scratch, err := os.CreateTemp("", "gitcfg-edit-*")
+	if err != nil {
+		return nil, fmt.Errorf("create scratch file: %w", err)
+	}
+	defer os.Remove(scratch.Name())
+
+	if _, err := scratch.Write(content); err != nil {
+		scratch.Close()
+		return nil, fmt.Errorf("write scratch file: %w", err)
+	}
+	if err := scratch.Close(); err != nil {
+		return nil, fmt.Errorf("write scratch file: %w", err)
+	}
+
+	for _, op := range ops {
+		args := append([]string{"config", "--file", scratch.Name()}, op...)
+		cmd := exec.CommandContext(ctx, "git", args...)
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			return nil, fmt.Errorf("git config %s: %w", strings.Join(op, " "), gitError(err, stderr.String()))
+		}
+	}
+
+	after, err := os.ReadFile(scratch.Name())
+	if err != nil {
+		return nil, fmt.Errorf("read scratch file: %w", err)
+	}
+	return after, nil
+}
+
+// gitError enriches err with git's own message, translating the exit codes used by git config.
+func gitError(err error, stderr string) error {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 5 && strings.TrimSpace(stderr) == "" {
+		return errors.New("key or value not found")
+	}
+	if msg := strings.TrimSpace(stderr); msg != "" {
+		return fmt.Errorf("%w: %s", err, msg)
+	}
+	return err
+}
+
+// fileLock is git's lock on a config file: <file>.lock is created exclusively, receives the
+// new contents and is renamed over the file, so readers never see a partial write and git
+// refuses to write the file meanwhile.
+type fileLock struct {
+	path      string
+	file      *os.File
+	committed bool
+}
+
+// lockFile locks path the way git does, following symlinks so that the link is kept and its
+// target is written. It fails if the lock is already held.
+func lockFile(path string) (*fileLock, error) {
+	if real, err := filepath.EvalSymlinks(path); err == nil {
+		path = real
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("create directory for %s: %w", path, err)
+	}
+	// The lock starts with the mode of the file it replaces, so contents of a private file are
+	// never readable by others, not even while they are being written.
+	mode := os.FileMode(0o644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	file, err := os.OpenFile(path+".lock", os.O_RDWR|os.O_CREATE|os.O_EXCL, mode)
+	if err != nil {
+		if errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("%s is locked by another process; remove %s.lock if no git command is running", path, path)
+		}
+		return nil, fmt.Errorf("lock %s: %w", path, err)
+	}
+	return &fileLock{path: path, file: file}, nil
+}
+
+// commit replaces the locked file with content, keeping its mode and, where the platform
+// allows, its owner. With keep unset the file is removed instead. The lock is released either
+// way.
+func (l *fileLock) commit(content []byte, keep bool) error {
+	defer l.release()
+	if !keep {
+		if err := os.Remove(l.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("remove %s: %w", l.path, err)
+		}
+		return nil
+	}
+
+	if info, err := os.Stat(l.path); err == nil {
+		if err := l.file.Chmod(info.Mode().Perm()); err != nil {
+			return fmt.Errorf("preserve mode of %s: %w", l.path, err)
+		}
+		preserveOwner(l.file, info)
+	}
+	if _, err := l.file.Write(content); err != nil {
+		return fmt.Errorf("write %s.lock: %w", l.path, err)
+	}
+	if err := l.file.Sync(); err != nil {
+		return fmt.Errorf("write %s.lock: %w", l.path, err)
+	}
+	if err := l.file.Close(); err != nil {
+		return fmt.Errorf("write %s.lock: %w", l.path, err)
+	}
+	l.file = nil
+	if err := os.Rename(l.path+".lock", l.path); err != nil {
+		return fmt.Errorf("commit %s: %w", l.path, err)
+	}
+	l.committed = true
+	return nil
+}
+
+// release drops the lock without touching the file. It is a no-op after commit.
+func (l *fileLock) release() {
+	if l.file != nil {
+		l.file.Close()
+		l.file = nil
+	}
+	if !l.committed {
+		os.Remove(l.path + ".lock")
+		l.committed = true
+	}
+}
+
+// contentHash identifies the contents of a config file for optimistic concurrency checks. A
+// missing file hashes like an empty one.
+func contentHash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// globalConfigPath returns the file `git config --global` writes to.
+func globalConfigPath() (string, error) {
+	if path := os.Getenv("GIT_CONFIG_GLOBAL"); path != "" {
+		return path, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("resolve home directory: %w", err)
+	}
+	homeConfig := filepath.Join(home, ".gitconfig")
+	if _, err := os.Stat(homeConfig); err == nil {
+		return homeConfig, nil
+	}
+
+	if xdg := xdgConfigPath(); xdg != "" {
+		if _, err := os.Stat(xdg); err == nil {
+			return xdg, nil
+		}
+	}
+	return homeConfig, nil
+}
+
+// xdgConfigPath returns $XDG_CONFIG_HOME/git/config, falling back to ~/.config/git/config.
+func xdgConfigPath() string {
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+		return filepath.Join(xdg, "git", "config")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "git", "config")
+}
+
+// systemConfigPath returns the system-wide config file of the git binary in PATH.
+func systemConfigPath() string {
+	if path := os.Getenv("GIT_CONFIG_SYSTEM"); path != "" {
+		return path
+	}
+	prefix := runtimePrefix()
+	if prefix == "" || prefix == "/usr" {
+		return "/etc/gitconfig"
+	}
+	return filepath.Join(prefix, "etc", "gitconfig")
+}
+
+// repositoryConfigPath resolves a file inside the git directory, such as "config" or
+// "config.worktree", honouring worktree and common directory layouts.
+func repositoryConfigPath(ctx context.Context, repo Repository, name string) (string, error) {
+	out, err := gitQuery(ctx, repo.Path, "rev-parse", "--git-path", name)
+	if err != nil {
+		return "", fmt.Errorf("resolve %s of %q: %w", name, repo.Name, err)
+	}
+	path := strings.TrimSpace(out)
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(repo.Path, path)
+	}
+	return path, nil
+}
+
+// resolveTargetFile determines the config file a write for scope lands in. An explicit
+// targetPath always wins; repo is only consulted for local and worktree scopes.
+func resolveTargetFile(ctx context.Context, repo *Repository, scope ConfigScope, targetPath string) (string, error) {
+	if targetPath != "" {
+		expanded, err := expandPath(targetPath)
+		if err != nil {
+			return "", err
+		}
+		return filepath.Clean(expanded), nil
+	}
+
+	switch scope {
+	case ConfigScopeGlobal:
+		return globalConfigPath()
+	case ConfigScopeSystem:
+		return systemConfigPath(), nil
+	case ConfigScopeLocal, ConfigScopeWorktree:
+		if repo == nil {
+			return "", fmt.Errorf("scope %q requires a repository", scope)
+		}
+		name := "config"
+		if scope == ConfigScopeWorktree {
+			name = "config.worktree"
+		}
+		return repositoryConfigPath(ctx, *repo, name)
+	case ConfigScopeInclude:
+		return "", errors.New("include scope requires a target path")
+	default:
+		return "", fmt.Errorf("scope %q cannot be written", scope)
+	}
+}
+
+// fileChange captures the contents of a config file before and after a modification.
+// existed reports whether the file was present beforehand; remove deletes the file instead
+// of writing after, restoring the "file absent" state.
+type fileChange struct {
+	path    string
+	before  []byte
+	after   []byte
+	existed bool
+	remove  bool
+	// lock is the lock already held on path, if any; otherwise recordChange takes one.
+	lock *fileLock
+}
+
+// applyEdit previews edit and, unless dryRun is set, applies it and records cs. Dry runs
+// return cs with the diff and the hash of the current file filled in but without an ID. The
+// file is locked while it is read and written, and the edit is refused when the file no
+// longer has the expected hash.
+func (s *Service) applyEdit(ctx context.Context, cs ChangeSet, edit configEdit, dryRun bool) (ChangeSet, error) {
+	var lock *fileLock
+	if !dryRun {
+		var err error
+		if lock, err = lockFile(edit.path); err != nil {
+			return ChangeSet{}, err
+		}
+		defer lock.release()
+	}
+
+	before, after, err := previewEdit(ctx, edit)
+	if err != nil {
+		return ChangeSet{}, err
+	}
+	if err := checkExpectedHash(edit, before); err != nil {
+		return ChangeSet{}, err
+	}
+	_, statErr := os.Stat(edit.path)
+	existed := statErr == nil
+
+	if dryRun {
+		cs.FilePath = edit.path
+		cs.Diff = redactDiff(unifiedDiff(edit.path, string(before), string(after)))
+		cs.BaseHash = contentHash(before)
+		cs.Created = !existed
+		cs.CreatedAt = timestamp(time.Now())
+		return cs, nil
+	}
+
+	return s.recordChange(cs, fileChange{path: edit.path, before: before, after: after, existed: existed, lock: lock})
+}
+
+// checkExpectedHash refuses an edit whose file changed since it was previewed.
+func checkExpectedHash(edit configEdit, before []byte) error {
+	if edit.expectedHash != "" && edit.expectedHash != contentHash(before) {
+		return fmt.Errorf("%s changed since the preview; review the new contents and try again", edit.path)
+	}
+	return nil
+}
+
+// recordChange applies change, keeps a backup of the previous contents and stores cs as the
+// change set describing the modification.
+func (s *Service) recordChange(cs ChangeSet, change fileChange) (ChangeSet, error) {
+	cs.ID = uuid.NewString()
+	cs.FilePath = change.path
+	cs.Diff = redactDiff(unifiedDiff(change.path, string(change.before), string(change.after)))
+	cs.BaseHash = contentHash(change.before)
+	if !change.remove {
+		cs.AfterHash = contentHash(change.after)
+	}
+	cs.Created = !change.existed
+	cs.CreatedAt = timestamp(time.Now())
+
+	lock := change.lock
+	if lock == nil {
+		var err error
+		if lock, err = lockFile(change.path); err != nil {
+			return ChangeSet{}, err
+		}
+	}
+
+	// The backup is taken while the file is locked, and dropped again when the write fails.
+	// Store helper files hold plaintext passwords, which backups must not retain.
+	if change.existed && cs.Scope != ConfigScopeCredentialStore {
+		backupPath, err := s.writeBackup(cs.ID, change.path, change.before)
+		if err != nil {
+			lock.release()
+			return ChangeSet{}, err
+		}
+		cs.BackupPath = backupPath
+	}
+	if err := lock.commit(change.after, !change.remove); err != nil {
+		if cs.BackupPath != "" {
+			if dropErr := s.dropBackups(cs.ID); dropErr != nil {
+				return ChangeSet{}, fmt.Errorf("%w; dropping its backup failed: %v", err, dropErr)
+			}
+		}
+		return ChangeSet{}, err
+	}
+
+	// The file is written at this point, so a failure to audit it is reported rather than
+	// turned into an error that callers would try to roll back.
+	if err := s.auditChange(cs, change); err != nil {
+		cs.AuditWarning = err.Error()
+	}
+
+	s.mu.Lock()
+	s.changeSets[cs.ID] = cs
+	s.matrices = make(map[string]cachedMatrix)
+	s.mu.Unlock()
+	s.rememberChange(cs, change)
+	return cs, nil
+}
+
+// defaultDataDir returns the per-user directory where the application keeps its state.
+func defaultDataDir() string {
+	if dir, err := os.UserConfigDir(); err == nil {
+		return filepath.Join(dir, "git-config-manager")
+	}
+	return filepath.Join(os.TempDir(), "git-config-manager")
+}
@@ -0,0 +1,239 @@
+package gitcfg
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newTestService(t *testing.T) *Service {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	s := NewService()
+	s.dataDir = t.TempDir()
+	return s
+}
+
+func TestWriteConfigAndRollback(t *testing.T) {
+	s := newTestService(t)
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "profile.gitconfig")
+	original := "[user]\n\tname = Alice\n"
+	if err := os.WriteFile(path, []byte(original), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	dry, err := s.WriteConfig(ctx, WriteRequest{Scope: ConfigScopeInclude, TargetPath: path, Key: "User.Email", Value: "alice@example.com", DryRun: true})
+	if err != nil {
+		t.Fatalf("dry run returned error: %v", err)
+	}
+	if !strings.Contains(dry.Diff, "+\tEmail = alice@example.com") {
+		t.Fatalf("expected dry run diff to add email with the given spelling, got:\n%s", dry.Diff)
+	}
+	if dry.Key != "user.email" {
+		t.Fatalf("expected change set to record the canonical key, got %q", dry.Key)
+	}
+	if content, _ := os.ReadFile(path); string(content) != original {
+		t.Fatalf("dry run must not modify the file")
+	}
+
+	cs, err := s.WriteConfig(ctx, WriteRequest{Scope: ConfigScopeInclude, TargetPath: path, Key: "user.email", Value: "alice@example.com"})
+	if err != nil {
+		t.Fatalf("WriteConfig returned error: %v", err)
+	}
+	if cs.BackupPath == "" {
+		t.Fatalf("expected a backup for an existing file")
+	}
+	if content, _ := os.ReadFile(path); !strings.Contains(string(content), "email = alice@example.com") {
+		t.Fatalf("expected email to be written, got:\n%s", content)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o600 {
+		t.Fatalf("expected file mode to be preserved, got %v (%v)", info.Mode().Perm(), err)
+	}
+
+	written, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read config: %v", err)
+	}
+	edited := string(written) + "[core]\n\teditor = vim\n"
+	if err := os.WriteFile(path, []byte(edited), 0o600); err != nil {
+		t.Fatalf("edit config: %v", err)
+	}
+	if _, err := s.Rollback(ctx, cs.ID); err == nil || !strings.Contains(err.Error(), "has changed") {
+		t.Fatalf("expected rollback over an external edit to be refused, got %v", err)
+	}
+	if content, _ := os.ReadFile(path); string(content) != edited {
+		t.Fatalf("expected the refused rollback to leave the file alone, got:\n%s", content)
+	}
+	if err := os.WriteFile(path, written, 0o600); err != nil {
+		t.Fatalf("revert edit: %v", err)
+	}
+
+	revert, err := s.Rollback(ctx, cs.ID)
+	if err != nil {
+		t.Fatalf("Rollback returned error: %v", err)
+	}
+	if revert.RevertsID != cs.ID {
+		t.Fatalf("expected rollback to reference %q, got %q", cs.ID, revert.RevertsID)
+	}
+	if content, _ := os.ReadFile(path); string(content) != original {
+		t.Fatalf("expected rollback to restore the original, got:\n%s", content)
+	}
+}
+
+func TestWriteConfigMultiValued(t *testing.T) {
+	s := newTestService(t)
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "created.gitconfig")
+	key := "url.git@mirror:.insteadOf"
+
+	for _, prefix := range []string{"https://github.com/", "https://gitlab.com/"} {
+		if _, err := s.WriteConfig(ctx, WriteRequest{Scope: ConfigScopeInclude, TargetPath: path, Key: key, Value: prefix, Operation: WriteOperationAdd}); err != nil {
+			t.Fatalf("add %q: %v", prefix, err)
+		}
+	}
+
+	cs, err := s.WriteConfig(ctx, WriteRequest{Scope: ConfigScopeInclude, TargetPath: path, Key: key, Operation: WriteOperationUnset, MatchValue: "https://github.com/"})
+	if err != nil {
+		t.Fatalf("unset returned error: %v", err)
+	}
+	if !strings.Contains(cs.Diff, "-\tinsteadOf = https://github.com/") {
+		t.Fatalf("expected only the matching value to be removed, got:\n%s", cs.Diff)
+	}
+
+	content, _ := os.ReadFile(path)
+	if !strings.Contains(string(content), "https://gitlab.com/") {
+		t.Fatalf("expected the other value to remain, got:\n%s", content)
+	}
+
+	if _, err := s.WriteConfig(ctx, WriteRequest{Scope: ConfigScopeInclude, TargetPath: path, Key: "core.autocrlf", Value: "sometimes"}); err == nil {
+		t.Fatalf("expected catalogue validation to reject an invalid enum value")
+	}
+}
+
+func TestRollbackRemovesCreatedFile(t *testing.T) {
+	s := newTestService(t)
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "new.gitconfig")
+
+	cs, err := s.WriteConfig(ctx, WriteRequest{Scope: ConfigScopeInclude, TargetPath: path, Key: "user.name", Value: "Bob"})
+	if err != nil {
+		t.Fatalf("WriteConfig returned error: %v", err)
+	}
+	if !cs.Created {
+		t.Fatalf("expected change set to record that it created the file")
+	}
+
+	if _, err := s.Rollback(ctx, cs.ID); err != nil {
+		t.Fatalf("Rollback returned error: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected rollback to remove the created file, stat error: %v", err)
+	}
+}
+
+func TestWriteConfigRespectsLocks(t *testing.T) {
+	s := newTestService(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+	target := filepath.Join(dir, "dotfiles.gitconfig")
+	link := filepath.Join(dir, "gitconfig")
+	writeTestFile(t, target, "[user]\n\tname = Alice\n")
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+
+	writeTestFile(t, target+".lock", "")
+	if _, err := s.WriteConfig(ctx, WriteRequest{TargetPath: link, Key: "user.name", Value: "Bob"}); err == nil || !strings.Contains(err.Error(), "locked") {
+		t.Fatalf("expected a held lock to stop the write, got %v", err)
+	}
+	if _, err := os.Stat(target + ".lock"); err != nil {
+		t.Fatal("expected the foreign lock to be left in place")
+	}
+	if err := os.Remove(target + ".lock"); err != nil {
+		t.Fatalf("remove lock: %v", err)
+	}
+
+	lock, err := lockFile(link)
+	if err != nil {
+		t.Fatalf("lockFile returned error: %v", err)
+	}
+	if info, err := os.Stat(target + ".lock"); err != nil || info.Mode().Perm() != 0o600 {
+		t.Fatalf("expected the lock of a private file to be private while held, got %v (%v)", info, err)
+	}
+	lock.release()
+
+	if _, err := s.WriteConfig(ctx, WriteRequest{TargetPath: link, Key: "user.name", Value: "Bob"}); err != nil {
+		t.Fatalf("WriteConfig returned error: %v", err)
+	}
+	if info, err := os.Lstat(link); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Fatal("expected the symlink to be kept")
+	}
+	info, err := os.Stat(target)
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Fatalf("expected the file mode to be preserved, got %v", info.Mode().Perm())
+	}
+	if _, err := os.Stat(target + ".lock"); !os.IsNotExist(err) {
+		t.Fatal("expected the lock to be released")
+	}
+}
+
+func TestWriteConfigDetectsConcurrentEdits(t *testing.T) {
+	s := newTestService(t)
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "profile.gitconfig")
+	writeTestFile(t, path, "[user]\n\tname = Alice\n")
+
+	req := WriteRequest{TargetPath: path, Key: "user.name", Value: "Bob", DryRun: true}
+	preview, err := s.WriteConfig(ctx, req)
+	if err != nil {
+		t.Fatalf("dry run returned error: %v", err)
+	}
+	if preview.BaseHash == "" {
+		t.Fatal("expected the preview to record the file hash")
+	}
+
+	writeTestFile(t, path, "[user]\n\tname = Carol\n")
+	req.DryRun = false
+	req.ExpectedHash = preview.BaseHash
+	if _, err := s.WriteConfig(ctx, req); err == nil || !strings.Contains(err.Error(), "changed since the preview") {
+		t.Fatalf("expected the external edit to be detected, got %v", err)
+	}
+	if got := gitConfigGet(t, filepath.Dir(path), "--file", path, "user.name"); got != "Carol" {
+		t.Fatalf("expected the external edit to be kept, got %q", got)
+	}
+
+	preview, err = s.WriteConfig(ctx, WriteRequest{TargetPath: path, Key: "user.name", Value: "Bob", DryRun: true})
+	if err != nil {
+		t.Fatalf("dry run returned error: %v", err)
+	}
+	req.ExpectedHash = preview.BaseHash
+	cs, err := s.WriteConfig(ctx, req)
+	if err != nil {
+		t.Fatalf("WriteConfig returned error: %v", err)
+	}
+	if cs.BaseHash != preview.BaseHash {
+		t.Fatalf("expected the change set to record the hash it was applied to, got %q", cs.BaseHash)
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	before := "a\nb\nc\nd\n"
+	after := "a\nB\nc\nd\ne\n"
+
+	want := "--- a/f\n+++ b/f\n@@ -1,4 +1,5 @@\n a\n-b\n+B\n c\n d\n+e\n"
+	if got := unifiedDiff("f", before, after); got != want {
+		t.Fatalf("unifiedDiff mismatch:\n%s\nwant:\n%s", got, want)
+	}
+	if got := unifiedDiff("f", before, before); got != "" {
+		t.Fatalf("expected no diff for identical content, got %q", got)
+	}
+}